@@ -0,0 +1,312 @@
+// Package analysis finds the variables of a program and where they are used, without running it. It follows the
+// rules of the resolver of the evaluator: parameters and lets of a function are local to the whole function, the
+// body of a function sees every variable the code around it binds, even after the function, and top level lets
+// are globals visible everywhere.
+package analysis
+
+import (
+	"sort"
+
+	"github.com/ShivankSharma070/go-interpreter/ast"
+	"github.com/ShivankSharma070/go-interpreter/token"
+)
+
+// Kind of a binding
+type Kind int
+
+const (
+	Global    Kind = iota // A let at the top level
+	Local                 // A let in a function
+	Parameter             // A parameter of a function or a macro
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Global:
+		return "global"
+	case Local:
+		return "local"
+	default:
+		return "parameter"
+	}
+}
+
+// Binding is a variable introduced by a let or a parameter. Binding the same name again in the same function is a
+// new binding, the uses after it belong to the new one.
+type Binding struct {
+	Name  *ast.Identifier
+	Kind  Kind
+	Let   *ast.LetStatement // Nil for parameters
+	Scope ast.Node          // The FunctionExpression or MacroLiteral of a local or a parameter, nil for globals
+	Uses  []*ast.Identifier
+}
+
+// Info is what Analyze found in a program
+type Info struct {
+	Bindings    []*Binding                   // In the order they were found
+	Refs        map[*ast.Identifier]*Binding // Every identifier naming a binding, including the names of the bindings
+	Free        []*ast.Identifier            // Identifiers naming no binding, like builtins or variables of the host
+	identifiers []*ast.Identifier            // Every identifier above, sorted by position
+
+	globals map[string][]*Binding // Top level lets by name, in source order
+	scopes  []*scope              // Functions around the code being analyzed, innermost last
+	pending []func()              // Functions to analyze once the code around them is
+	unbound []*ast.Identifier     // Identifiers that are not locals, they may name a global
+}
+
+// scope holds the bindings of a function
+type scope struct {
+	function ast.Node
+	bindings map[string]*Binding
+}
+
+// Analyze finds the bindings of program and their uses. Programs with syntax errors can be analyzed, missing
+// nodes are skipped.
+func Analyze(program *ast.Program) *Info {
+	info := &Info{
+		Refs:    make(map[*ast.Identifier]*Binding),
+		globals: make(map[string][]*Binding),
+	}
+	info.statements(program.Statements)
+	info.resolvePending()
+	info.bindGlobals()
+
+	for _, binding := range info.Bindings {
+		sortByPosition(binding.Uses)
+	}
+	sortByPosition(info.Free)
+	sortByPosition(info.identifiers)
+	return info
+}
+
+func (info *Info) statements(statements []ast.Statement) {
+	for _, statement := range statements {
+		info.node(statement)
+	}
+}
+
+func (info *Info) node(node ast.Node) {
+	switch node := node.(type) {
+	case *ast.ExpressionStatement:
+		info.node(node.Expression)
+	case *ast.LetStatement:
+		// The value comes first, in `let x = x + 1` the x on the right is the outer one
+		info.node(node.Value)
+		if node.Name != nil {
+			info.define(node.Name, node)
+		}
+	case *ast.ReturnStatement:
+		info.node(node.ReturnValue)
+	case *ast.BlockStatement:
+		if node != nil {
+			info.statements(node.Statements)
+		}
+	case *ast.Identifier:
+		info.use(node)
+	case *ast.PrefixExpression:
+		info.node(node.Right)
+	case *ast.InfixExpression:
+		info.node(node.Left)
+		info.node(node.Right)
+	case *ast.IfElseExpression:
+		info.node(node.Condition)
+		info.node(node.Consequence)
+		info.node(node.Alternative)
+	case *ast.FunctionExpression:
+		info.pending = append(info.pending, func() { info.function(node, node.Parameters, node.Body) })
+	case *ast.MacroLiteral:
+		info.pending = append(info.pending, func() { info.function(node, node.Parameters, node.Body) })
+	case *ast.CallExpression:
+		if iden, ok := node.Function.(*ast.Identifier); ok && iden.Value == "quote" {
+			info.use(iden)
+			// Quoted code is data, only the arguments of its unquote calls are evaluated
+			for _, arg := range node.Argument {
+				ast.Inspect(arg, func(node ast.Node) bool {
+					call, ok := node.(*ast.CallExpression)
+					if !ok {
+						return true
+					}
+					if iden, ok := call.Function.(*ast.Identifier); !ok || iden.Value != "unquote" {
+						return true
+					}
+					info.node(call)
+					return false
+				})
+			}
+			return
+		}
+		info.node(node.Function)
+		for _, arg := range node.Argument {
+			info.node(arg)
+		}
+	case *ast.ArrayLiteral:
+		for _, element := range node.Elements {
+			info.node(element)
+		}
+	case *ast.IndexExpression:
+		info.node(node.Left)
+		info.node(node.Index)
+	case *ast.MemberExpression:
+		info.node(node.Object)
+	case *ast.HashLiteral:
+		for key, value := range node.Pairs {
+			info.node(key)
+			info.node(value)
+		}
+	}
+}
+
+// function analyzes the body of a function once the code around it is, see the package comment
+func (info *Info) function(function ast.Node, params []*ast.Identifier, body *ast.BlockStatement) {
+	scope := &scope{function: function, bindings: make(map[string]*Binding)}
+	info.scopes = append(info.scopes, scope)
+	enclosingPending := info.pending
+	info.pending = nil
+
+	for _, param := range params {
+		binding := &Binding{Name: param, Kind: Parameter, Scope: function}
+		info.add(binding)
+		scope.bindings[param.Value] = binding
+	}
+	info.node(body)
+	info.resolvePending()
+
+	info.pending = enclosingPending
+	info.scopes = info.scopes[:len(info.scopes)-1]
+}
+
+func (info *Info) resolvePending() {
+	for len(info.pending) > 0 {
+		analyze := info.pending[0]
+		info.pending = info.pending[1:]
+		analyze()
+	}
+}
+
+func (info *Info) define(name *ast.Identifier, let *ast.LetStatement) {
+	if len(info.scopes) == 0 {
+		binding := &Binding{Name: name, Kind: Global, Let: let}
+		info.add(binding)
+		info.globals[name.Value] = append(info.globals[name.Value], binding)
+		return
+	}
+
+	scope := info.scopes[len(info.scopes)-1]
+	binding := &Binding{Name: name, Kind: Local, Let: let, Scope: scope.function}
+	info.add(binding)
+	scope.bindings[name.Value] = binding
+}
+
+func (info *Info) add(binding *Binding) {
+	info.Bindings = append(info.Bindings, binding)
+	info.Refs[binding.Name] = binding
+	info.identifiers = append(info.identifiers, binding.Name)
+}
+
+func (info *Info) use(iden *ast.Identifier) {
+	info.identifiers = append(info.identifiers, iden)
+	for i := len(info.scopes) - 1; i >= 0; i-- {
+		if binding, ok := info.scopes[i].bindings[iden.Value]; ok {
+			info.Refs[iden] = binding
+			binding.Uses = append(binding.Uses, iden)
+			return
+		}
+	}
+	info.unbound = append(info.unbound, iden)
+}
+
+// bindGlobals runs once every top level let is known. A global is used from the last let of its name before the
+// use, or from the first one when the use comes before all of them, in a function called later.
+func (info *Info) bindGlobals() {
+	for _, iden := range info.unbound {
+		lets := info.globals[iden.Value]
+		if len(lets) == 0 {
+			info.Free = append(info.Free, iden)
+			continue
+		}
+		binding := lets[0]
+		for _, let := range lets[1:] {
+			if before(ast.Start(let.Let), iden.Token.Pos) {
+				binding = let
+			}
+		}
+		info.Refs[iden] = binding
+		binding.Uses = append(binding.Uses, iden)
+	}
+}
+
+// IdentifierAt returns the identifier at pos, or the one ending right before it, as a cursor placed after a name
+// is still on it. It returns nil when there is none.
+func (info *Info) IdentifierAt(pos token.Position) *ast.Identifier {
+	var found *ast.Identifier
+	for _, iden := range info.identifiers {
+		start, end := ast.Start(iden), ast.End(iden)
+		if before(pos, start) {
+			break
+		}
+		if before(pos, end) {
+			return iden
+		}
+		if pos == end {
+			found = iden
+		}
+	}
+	return found
+}
+
+// Visible returns the bindings that code at pos can use, the innermost first, one for every name. Locals are
+// visible after their let, parameters in the whole function and globals everywhere.
+func (info *Info) Visible(pos token.Position) []*Binding {
+	seen := make(map[string]bool)
+	var visible []*Binding
+	add := func(binding *Binding) {
+		if !seen[binding.Name.Value] {
+			seen[binding.Name.Value] = true
+			visible = append(visible, binding)
+		}
+	}
+
+	var locals []*Binding
+	for _, binding := range info.Bindings {
+		if binding.Kind == Global || !contains(binding.Scope, pos) {
+			continue
+		}
+		if binding.Kind == Local && !before(binding.Name.Token.Pos, pos) {
+			continue
+		}
+		locals = append(locals, binding)
+	}
+	// Inner functions start after the ones around them, and later lets replace earlier ones
+	sort.SliceStable(locals, func(i, j int) bool {
+		a, b := ast.Start(locals[i].Scope), ast.Start(locals[j].Scope)
+		if a != b {
+			return before(b, a)
+		}
+		return before(locals[j].Name.Token.Pos, locals[i].Name.Token.Pos)
+	})
+	for _, binding := range locals {
+		add(binding)
+	}
+
+	for i := len(info.Bindings) - 1; i >= 0; i-- {
+		if binding := info.Bindings[i]; binding.Kind == Global {
+			add(binding)
+		}
+	}
+	return visible
+}
+
+// contains reports whether pos is in the source of node
+func contains(node ast.Node, pos token.Position) bool {
+	return !before(pos, ast.Start(node)) && before(pos, ast.End(node))
+}
+
+func before(a, b token.Position) bool {
+	return a.Line < b.Line || a.Line == b.Line && a.Column < b.Column
+}
+
+func sortByPosition(identifiers []*ast.Identifier) {
+	sort.SliceStable(identifiers, func(i, j int) bool {
+		return before(identifiers[i].Token.Pos, identifiers[j].Token.Pos)
+	})
+}
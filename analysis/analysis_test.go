@@ -0,0 +1,162 @@
+package analysis
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ShivankSharma070/go-interpreter/ast"
+	"github.com/ShivankSharma070/go-interpreter/lexer"
+	"github.com/ShivankSharma070/go-interpreter/parser"
+	"github.com/ShivankSharma070/go-interpreter/token"
+)
+
+func TestAnalyze(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string // Every binding as kind name@line:column followed by the positions of its uses
+		free     string
+	}{
+		{
+			`let a = 1; let b = a + c; puts(b)`,
+			[]string{"global a@1:5 1:20", "global b@1:16 1:32"},
+			"c puts",
+		},
+		{
+			// Parameters and lets are local to the function, the value of a let uses the previous binding
+			`let f = fn(x, y) { let x = x + 1; x * y }; f(1, 2)`,
+			[]string{"global f@1:5 1:44", "parameter x@1:12 1:28", "parameter y@1:15 1:39", "local x@1:24 1:35"},
+			"",
+		},
+		{
+			// Functions see lets bound after them, globals are used from the last let before the use
+			`let g = fn() { h() }; let h = fn() { 1 }; let h = 2; h`,
+			[]string{"global g@1:5", "global h@1:27 1:16", "global h@1:47 1:54"},
+			"",
+		},
+		{
+			`let f = fn() { let g = fn() { v }; let v = 1; g() }`,
+			[]string{"global f@1:5", "local g@1:20 1:47", "local v@1:40 1:31"},
+			"",
+		},
+		{
+			// Only the unquoted parts of quoted code are evaluated
+			`let m = macro(a) { quote(unquote(a) + b) }`,
+			[]string{"global m@1:5", "parameter a@1:15 1:34"},
+			"quote unquote",
+		},
+		{
+			`let h = {"k": v}; h.k`,
+			[]string{"global h@1:5 1:19"},
+			"v",
+		},
+	}
+
+	for _, tt := range tests {
+		info := Analyze(parse(t, tt.input))
+
+		var got []string
+		for _, binding := range info.Bindings {
+			got = append(got, describe(binding))
+		}
+		if strings.Join(got, ", ") != strings.Join(tt.expected, ", ") {
+			t.Errorf("wrong bindings for %s.\nwant=%v\ngot =%v", tt.input, tt.expected, got)
+		}
+
+		var free []string
+		for _, iden := range info.Free {
+			free = append(free, iden.Value)
+		}
+		if strings.Join(free, " ") != tt.free {
+			t.Errorf("wrong free identifiers for %s. want=%q, got=%q", tt.input, tt.free, strings.Join(free, " "))
+		}
+
+		for iden, binding := range info.Refs {
+			if iden.Value != binding.Name.Value {
+				t.Errorf("%s refers to binding %s", iden.Value, binding.Name.Value)
+			}
+		}
+	}
+}
+
+func TestIdentifierAt(t *testing.T) {
+	info := Analyze(parse(t, "let count = 1;\nputs(count)"))
+	tests := []struct {
+		pos      token.Position
+		expected string
+	}{
+		{token.Position{Line: 1, Column: 5}, "count@1:5"},
+		{token.Position{Line: 1, Column: 9}, "count@1:5"},
+		// Right after a name
+		{token.Position{Line: 1, Column: 10}, "count@1:5"},
+		{token.Position{Line: 1, Column: 1}, ""},
+		{token.Position{Line: 2, Column: 1}, "puts@2:1"},
+		{token.Position{Line: 2, Column: 5}, "puts@2:1"},
+		{token.Position{Line: 2, Column: 6}, "count@2:6"},
+		{token.Position{Line: 3, Column: 1}, ""},
+	}
+
+	for _, tt := range tests {
+		got := ""
+		if iden := info.IdentifierAt(tt.pos); iden != nil {
+			got = fmt.Sprintf("%s@%s", iden.Value, iden.Token.Pos)
+		}
+		if got != tt.expected {
+			t.Errorf("wrong identifier at %s. want=%q, got=%q", tt.pos, tt.expected, got)
+		}
+	}
+}
+
+func TestVisible(t *testing.T) {
+	input := `let a = 1;
+let f = fn(x) {
+  let b = 2;
+  let g = fn(a) {
+    a
+  };
+  let c = 3;
+};
+let z = 4;`
+	info := Analyze(parse(t, input))
+	tests := []struct {
+		pos      token.Position
+		expected string
+	}{
+		{token.Position{Line: 1, Column: 1}, "z f a"},
+		{token.Position{Line: 3, Column: 1}, "x z f a"},
+		{token.Position{Line: 5, Column: 5}, "a@param g b x z f"},
+		{token.Position{Line: 7, Column: 12}, "c g b x z f a"},
+	}
+
+	for _, tt := range tests {
+		var got []string
+		for _, binding := range info.Visible(tt.pos) {
+			name := binding.Name.Value
+			if binding.Kind == Parameter && name == "a" {
+				name += "@param"
+			}
+			got = append(got, name)
+		}
+		if strings.Join(got, " ") != tt.expected {
+			t.Errorf("wrong bindings visible at %s. want=%q, got=%q", tt.pos, tt.expected, strings.Join(got, " "))
+		}
+	}
+}
+
+func describe(binding *Binding) string {
+	parts := []string{fmt.Sprintf("%s %s@%s", binding.Kind, binding.Name.Value, binding.Name.Token.Pos)}
+	for _, use := range binding.Uses {
+		parts = append(parts, use.Token.Pos.String())
+	}
+	return strings.Join(parts, " ")
+}
+
+func parse(t *testing.T, input string) *ast.Program {
+	t.Helper()
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parser errors for %s: %v", input, p.Errors())
+	}
+	return program
+}
@@ -79,6 +79,13 @@ func (ls *LetStatement) String() string {
 type Identifier struct {
 	Token token.Token
 	Value string
+
+	// Address of the variable, filled in by the resolver of the evaluator. A parameter or let of a function is
+	// a Local, found Depth functions up from where the identifier is used, at Slot in the locals of that function.
+	// Other identifiers are looked up by name.
+	Local bool
+	Depth int
+	Slot  int
 }
 
 // To implement expression & node interface
@@ -151,6 +158,15 @@ func (il *IntegerLiteral) expressionNode()      {}
 func (il *IntegerLiteral) TokenLiteral() string { return il.Token.Literal }
 func (il *IntegerLiteral) String() string       { return il.Token.Literal }
 
+type FloatLiteral struct {
+	Token token.Token
+	Value float64
+}
+
+func (fl *FloatLiteral) expressionNode()      {}
+func (fl *FloatLiteral) TokenLiteral() string { return fl.Token.Literal }
+func (fl *FloatLiteral) String() string       { return fl.Token.Literal }
+
 type PrefixExpression struct {
 	Token    token.Token
 	Operator string
@@ -224,6 +240,7 @@ func (ie *IfElseExpression) String() string {
 type BlockStatement struct {
 	Token      token.Token
 	Statements []Statement
+	End        token.Position // Position of the closing brace
 }
 
 func (be *BlockStatement) statementNode()       {}
@@ -241,6 +258,7 @@ type FunctionExpression struct {
 	Token      token.Token
 	Parameters []*Identifier
 	Body       *BlockStatement
+	NumLocals  int // Number of parameters and lets of the function, filled in by the resolver
 }
 
 func (fe *FunctionExpression) expressionNode()      {}
@@ -260,10 +278,37 @@ func (fe *FunctionExpression) String() string {
 	return buf.String()
 }
 
+// macro(x, y) { x + y }
+// Same shape as a function, but its arguments and result are pieces of code instead of values
+type MacroLiteral struct {
+	Token      token.Token
+	Parameters []*Identifier
+	Body       *BlockStatement
+	NumLocals  int
+}
+
+func (ml *MacroLiteral) expressionNode()      {}
+func (ml *MacroLiteral) TokenLiteral() string { return ml.Token.Literal }
+func (ml *MacroLiteral) String() string {
+	var buf bytes.Buffer
+
+	parameters := []string{}
+	for _, iden := range ml.Parameters {
+		parameters = append(parameters, iden.String())
+	}
+	buf.WriteString(ml.TokenLiteral())
+	buf.WriteString("(")
+	buf.WriteString(strings.Join(parameters, ", "))
+	buf.WriteString(")")
+	buf.WriteString(ml.Body.String())
+	return buf.String()
+}
+
 type CallExpression struct {
 	Token    token.Token
 	Function Expression
 	Argument []Expression
+	End      token.Position // Position of the closing parenthesis
 }
 
 func (ce *CallExpression) expressionNode()      {}
@@ -284,6 +329,7 @@ func (ce *CallExpression) String() string {
 type ArrayLiteral struct {
 	Token    token.Token
 	Elements []Expression
+	End      token.Position // Position of the closing bracket
 }
 
 func (al *ArrayLiteral) expressionNode()      {}
@@ -306,6 +352,7 @@ type IndexExpression struct {
 	Token token.Token
 	Left  Expression
 	Index Expression
+	End   token.Position // Position of the closing bracket
 }
 
 func (ie *IndexExpression) expressionNode()      {}
@@ -321,9 +368,31 @@ func (ie *IndexExpression) String() string {
 	return out.String()
 }
 
+// Member access, a.b
+// It is sugar for a["b"] on hashes and modules, and gives access to the methods of other types
+type MemberExpression struct {
+	Token    token.Token // The . token
+	Object   Expression
+	Property *Identifier
+}
+
+func (me *MemberExpression) expressionNode()      {}
+func (me *MemberExpression) TokenLiteral() string { return me.Token.Literal }
+func (me *MemberExpression) String() string {
+	var out bytes.Buffer
+	out.WriteString("(")
+	out.WriteString(me.Object.String())
+	out.WriteString(".")
+	out.WriteString(me.Property.String())
+	out.WriteString(")")
+
+	return out.String()
+}
+
 type HashLiteral struct {
 	Token token.Token
 	Pairs map[Expression]Expression
+	End   token.Position // Position of the closing brace
 }
 
 func (hl *HashLiteral) expressionNode()      {}
@@ -0,0 +1,441 @@
+package ast
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/ShivankSharma070/go-interpreter/token"
+)
+
+// A tree in JSON is made of objects, one for every node, with these members:
+//
+//	kind    the name of the node type, "LetStatement", "InfixExpression", ...
+//	token   the token of the node, {"type": "+", "literal": "+", "pos": {"line": 1, "column": 3}}
+//	span    the source of the node, {"start": {...}, "end": {...}} as given by Start and End
+//
+// followed by the fields of the node, named like the Go fields in camel case ("returnValue", "numLocals"). The
+// arguments of a call are "arguments" and the pairs of a hash are a list of {"key": ..., "value": ...} objects in
+// source order. Missing nodes, like the else branch of an if without one, are null. Identifiers only have "local",
+// "depth" and "slot" once the resolver made them locals.
+//
+// Decoding ignores spans, except for the nodes ending with a closing bracket or brace, which is the last character
+// of their span.
+
+// MarshalJSON encodes the program in the format described above
+func (p *Program) MarshalJSON() ([]byte, error) {
+	return json.Marshal(encodeNode(p))
+}
+
+// UnmarshalJSON decodes a program encoded by MarshalJSON
+func (p *Program) UnmarshalJSON(data []byte) error {
+	node, err := UnmarshalNode(data)
+	if err != nil {
+		return err
+	}
+	program, ok := node.(*Program)
+	if !ok {
+		return fmt.Errorf("ast: expected a Program, got %s", kindOf(node))
+	}
+	*p = *program
+	return nil
+}
+
+// MarshalNode encodes any node, and the tree below it, like Program.MarshalJSON
+func MarshalNode(node Node) ([]byte, error) {
+	return json.Marshal(encodeNode(node))
+}
+
+// UnmarshalNode decodes a node encoded by MarshalNode, the type of the node is given by its kind
+func UnmarshalNode(data []byte) (Node, error) {
+	node, err := decodeNode(data)
+	if err != nil {
+		return nil, fmt.Errorf("ast: %w", err)
+	}
+	return node, nil
+}
+
+type jsonPosition struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+type jsonToken struct {
+	Type    token.TokenType `json:"type"`
+	Literal string          `json:"literal"`
+	Pos     jsonPosition    `json:"pos"`
+}
+
+type jsonSpan struct {
+	Start jsonPosition `json:"start"`
+	End   jsonPosition `json:"end"`
+}
+
+func kindOf(node Node) string {
+	if node == nil {
+		return "null"
+	}
+	return fmt.Sprintf("%T", node)[len("*ast."):]
+}
+
+// encodeNode turns node into the maps and slices encoding/json writes, nil stays nil
+func encodeNode(node Node) any {
+	if node == nil || isNilNode(node) {
+		return nil
+	}
+
+	fields := map[string]any{
+		"kind": kindOf(node),
+		"span": jsonSpan{Start: jsonPosition(Start(node)), End: jsonPosition(End(node))},
+	}
+	setToken := func(tok token.Token) {
+		fields["token"] = jsonToken{Type: tok.Type, Literal: tok.Literal, Pos: jsonPosition(tok.Pos)}
+	}
+
+	switch node := node.(type) {
+	case *Program:
+		fields["statements"] = encodeList(node.Statements)
+	case *LetStatement:
+		setToken(node.Token)
+		fields["name"] = encodeNode(node.Name)
+		fields["value"] = encodeNode(node.Value)
+	case *ReturnStatement:
+		setToken(node.Token)
+		fields["returnValue"] = encodeNode(node.ReturnValue)
+	case *ExpressionStatement:
+		setToken(node.Token)
+		fields["expression"] = encodeNode(node.Expression)
+	case *BlockStatement:
+		setToken(node.Token)
+		fields["statements"] = encodeList(node.Statements)
+	case *Identifier:
+		setToken(node.Token)
+		fields["value"] = node.Value
+		if node.Local {
+			fields["local"] = true
+			fields["depth"] = node.Depth
+			fields["slot"] = node.Slot
+		}
+	case *IntegerLiteral:
+		setToken(node.Token)
+		fields["value"] = node.Value
+	case *FloatLiteral:
+		setToken(node.Token)
+		fields["value"] = node.Value
+	case *StringLiteral:
+		setToken(node.Token)
+		fields["value"] = node.Value
+	case *BoolExpression:
+		setToken(node.Token)
+		fields["value"] = node.Value
+	case *PrefixExpression:
+		setToken(node.Token)
+		fields["operator"] = node.Operator
+		fields["right"] = encodeNode(node.Right)
+	case *InfixExpression:
+		setToken(node.Token)
+		fields["left"] = encodeNode(node.Left)
+		fields["operator"] = node.Operator
+		fields["right"] = encodeNode(node.Right)
+	case *IfElseExpression:
+		setToken(node.Token)
+		fields["condition"] = encodeNode(node.Condition)
+		fields["consequence"] = encodeNode(node.Consequence)
+		fields["alternative"] = encodeNode(node.Alternative)
+	case *FunctionExpression:
+		setToken(node.Token)
+		fields["parameters"] = encodeList(node.Parameters)
+		fields["body"] = encodeNode(node.Body)
+		fields["numLocals"] = node.NumLocals
+	case *MacroLiteral:
+		setToken(node.Token)
+		fields["parameters"] = encodeList(node.Parameters)
+		fields["body"] = encodeNode(node.Body)
+		fields["numLocals"] = node.NumLocals
+	case *CallExpression:
+		setToken(node.Token)
+		fields["function"] = encodeNode(node.Function)
+		fields["arguments"] = encodeList(node.Argument)
+	case *ArrayLiteral:
+		setToken(node.Token)
+		fields["elements"] = encodeList(node.Elements)
+	case *IndexExpression:
+		setToken(node.Token)
+		fields["left"] = encodeNode(node.Left)
+		fields["index"] = encodeNode(node.Index)
+	case *MemberExpression:
+		setToken(node.Token)
+		fields["object"] = encodeNode(node.Object)
+		fields["property"] = encodeNode(node.Property)
+	case *HashLiteral:
+		setToken(node.Token)
+		pairs := []any{}
+		for _, key := range sortedKeys(node) {
+			pairs = append(pairs, map[string]any{"key": encodeNode(key), "value": encodeNode(node.Pairs[key])})
+		}
+		fields["pairs"] = pairs
+	}
+	return fields
+}
+
+func encodeList[T Node](nodes []T) []any {
+	list := []any{}
+	for _, node := range nodes {
+		list = append(list, encodeNode(node))
+	}
+	return list
+}
+
+// isNilNode reports whether node is a nil pointer stored in an interface, like a missing else branch
+func isNilNode(node Node) bool {
+	switch node := node.(type) {
+	case *BlockStatement:
+		return node == nil
+	case *Identifier:
+		return node == nil
+	}
+	return false
+}
+
+// sortedKeys returns the keys of a hash in source order, keys without positions are ordered by their code
+func sortedKeys(hash *HashLiteral) []Expression {
+	keys := make([]Expression, 0, len(hash.Pairs))
+	for key := range hash.Pairs {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := Start(keys[i]), Start(keys[j])
+		if a != b {
+			return a.Line < b.Line || a.Line == b.Line && a.Column < b.Column
+		}
+		return keys[i].String() < keys[j].String()
+	})
+	return keys
+}
+
+// jsonObject is a node being decoded, its kind is kept for error messages
+type jsonObject struct {
+	kind   string
+	fields map[string]json.RawMessage
+}
+
+func decodeNode(data []byte) (Node, error) {
+	if isNull(data) {
+		return nil, nil
+	}
+	obj := &jsonObject{}
+	if err := json.Unmarshal(data, &obj.fields); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(obj.fields["kind"], &obj.kind); err != nil {
+		return nil, errors.New("node without a kind")
+	}
+
+	var err error
+	var tok token.Token
+	if obj.kind != "Program" {
+		var t jsonToken
+		err = obj.field("token", &t)
+		tok = token.Token{Type: t.Type, Literal: t.Literal, Pos: token.Position(t.Pos)}
+	}
+	// Every step below is skipped after the first error, which is returned at the end
+	get := func(name string, value any) {
+		if err == nil {
+			err = obj.field(name, value)
+		}
+	}
+	expression := func(name string) Expression {
+		var node Expression
+		get(name, &decoded[Expression]{&node, "an expression"})
+		return node
+	}
+	block := func(name string) *BlockStatement {
+		var node *BlockStatement
+		get(name, &decoded[*BlockStatement]{&node, "a BlockStatement"})
+		return node
+	}
+	identifier := func(name string) *Identifier {
+		var node *Identifier
+		get(name, &decoded[*Identifier]{&node, "an Identifier"})
+		return node
+	}
+
+	var node Node
+	switch obj.kind {
+	case "Program":
+		program := &Program{}
+		get("statements", &decodedList[Statement]{&program.Statements, "a statement"})
+		node = program
+	case "LetStatement":
+		node = &LetStatement{Token: tok, Name: identifier("name"), Value: expression("value")}
+	case "ReturnStatement":
+		node = &ReturnStatement{Token: tok, ReturnValue: expression("returnValue")}
+	case "ExpressionStatement":
+		node = &ExpressionStatement{Token: tok, Expression: expression("expression")}
+	case "BlockStatement":
+		block := &BlockStatement{Token: tok, End: obj.closing()}
+		get("statements", &decodedList[Statement]{&block.Statements, "a statement"})
+		node = block
+	case "Identifier":
+		iden := &Identifier{Token: tok}
+		get("value", &iden.Value)
+		if _, ok := obj.fields["local"]; ok {
+			get("local", &iden.Local)
+			get("depth", &iden.Depth)
+			get("slot", &iden.Slot)
+		}
+		node = iden
+	case "IntegerLiteral":
+		literal := &IntegerLiteral{Token: tok}
+		get("value", &literal.Value)
+		node = literal
+	case "FloatLiteral":
+		literal := &FloatLiteral{Token: tok}
+		get("value", &literal.Value)
+		node = literal
+	case "StringLiteral":
+		literal := &StringLiteral{Token: tok}
+		get("value", &literal.Value)
+		node = literal
+	case "BoolExpression":
+		literal := &BoolExpression{Token: tok}
+		get("value", &literal.Value)
+		node = literal
+	case "PrefixExpression":
+		prefix := &PrefixExpression{Token: tok}
+		get("operator", &prefix.Operator)
+		prefix.Right = expression("right")
+		node = prefix
+	case "InfixExpression":
+		infix := &InfixExpression{Token: tok}
+		infix.Left = expression("left")
+		get("operator", &infix.Operator)
+		infix.Right = expression("right")
+		node = infix
+	case "IfElseExpression":
+		node = &IfElseExpression{
+			Token:       tok,
+			Condition:   expression("condition"),
+			Consequence: block("consequence"),
+			Alternative: block("alternative"),
+		}
+	case "FunctionExpression":
+		function := &FunctionExpression{Token: tok}
+		get("parameters", &decodedList[*Identifier]{&function.Parameters, "an Identifier"})
+		function.Body = block("body")
+		get("numLocals", &function.NumLocals)
+		node = function
+	case "MacroLiteral":
+		macro := &MacroLiteral{Token: tok}
+		get("parameters", &decodedList[*Identifier]{&macro.Parameters, "an Identifier"})
+		macro.Body = block("body")
+		get("numLocals", &macro.NumLocals)
+		node = macro
+	case "CallExpression":
+		call := &CallExpression{Token: tok, Function: expression("function"), End: obj.closing()}
+		get("arguments", &decodedList[Expression]{&call.Argument, "an expression"})
+		node = call
+	case "ArrayLiteral":
+		array := &ArrayLiteral{Token: tok, End: obj.closing()}
+		get("elements", &decodedList[Expression]{&array.Elements, "an expression"})
+		node = array
+	case "IndexExpression":
+		node = &IndexExpression{Token: tok, Left: expression("left"), Index: expression("index"), End: obj.closing()}
+	case "MemberExpression":
+		node = &MemberExpression{Token: tok, Object: expression("object"), Property: identifier("property")}
+	case "HashLiteral":
+		hash := &HashLiteral{Token: tok, Pairs: map[Expression]Expression{}, End: obj.closing()}
+		var pairs []struct {
+			Key   json.RawMessage `json:"key"`
+			Value json.RawMessage `json:"value"`
+		}
+		get("pairs", &pairs)
+		for i := 0; err == nil && i < len(pairs); i++ {
+			var key, value Expression
+			if err = (&decoded[Expression]{&key, "an expression"}).UnmarshalJSON(pairs[i].Key); err == nil {
+				err = (&decoded[Expression]{&value, "an expression"}).UnmarshalJSON(pairs[i].Value)
+			}
+			if err != nil {
+				err = fmt.Errorf("%s.pairs[%d]: %w", obj.kind, i, err)
+			}
+			hash.Pairs[key] = value
+		}
+		node = hash
+	default:
+		return nil, fmt.Errorf("unknown node kind %q", obj.kind)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// field decodes the member name of the object into value, it must be there even when it is null
+func (obj *jsonObject) field(name string, value any) error {
+	data, ok := obj.fields[name]
+	if !ok {
+		return fmt.Errorf("%s without %s", obj.kind, name)
+	}
+	if err := json.Unmarshal(data, value); err != nil {
+		return fmt.Errorf("%s.%s: %w", obj.kind, name, err)
+	}
+	return nil
+}
+
+// closing returns the position of the closing bracket of the node, the last character of its span
+func (obj *jsonObject) closing() token.Position {
+	var span jsonSpan
+	if err := json.Unmarshal(obj.fields["span"], &span); err != nil || span.End == (jsonPosition{}) {
+		return token.Position{}
+	}
+	return token.Position{Line: span.End.Line, Column: span.End.Column - 1}
+}
+
+// decoded unmarshals a node into a field of type T, failing when the node is not a T
+type decoded[T Node] struct {
+	target *T
+	want   string
+}
+
+func (d *decoded[T]) UnmarshalJSON(data []byte) error {
+	node, err := decodeNode(data)
+	if err != nil || node == nil {
+		return err
+	}
+	value, ok := node.(T)
+	if !ok {
+		return fmt.Errorf("%s is not %s", kindOf(node), d.want)
+	}
+	*d.target = value
+	return nil
+}
+
+// decodedList unmarshals a list of nodes into a slice of T
+type decodedList[T Node] struct {
+	target *[]T
+	want   string
+}
+
+func (d *decodedList[T]) UnmarshalJSON(data []byte) error {
+	var items []json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		return err
+	}
+	list := []T{}
+	for i, item := range items {
+		var node T
+		if err := (&decoded[T]{&node, d.want}).UnmarshalJSON(item); err != nil {
+			return fmt.Errorf("[%d]: %w", i, err)
+		}
+		list = append(list, node)
+	}
+	*d.target = list
+	return nil
+}
+
+func isNull(data []byte) bool {
+	return bytes.Equal(bytes.TrimSpace(data), []byte("null"))
+}
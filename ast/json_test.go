@@ -0,0 +1,127 @@
+package ast_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ShivankSharma070/go-interpreter/ast"
+	"github.com/ShivankSharma070/go-interpreter/evaluator"
+	"github.com/ShivankSharma070/go-interpreter/formatter"
+	"github.com/ShivankSharma070/go-interpreter/lexer"
+	"github.com/ShivankSharma070/go-interpreter/object"
+	"github.com/ShivankSharma070/go-interpreter/parser"
+)
+
+func TestMarshalJSON(t *testing.T) {
+	program := parse(t, `-x;`)
+	data, err := json.Marshal(program)
+	if err != nil {
+		t.Fatalf("Marshal failed: %s", err)
+	}
+
+	expected := `{"kind":"Program","span":{"start":{"line":1,"column":1},"end":{"line":1,"column":3}},"statements":[` +
+		`{"expression":{"kind":"PrefixExpression","operator":"-","right":` +
+		`{"kind":"Identifier","span":{"start":{"line":1,"column":2},"end":{"line":1,"column":3}},` +
+		`"token":{"type":"IDEN","literal":"x","pos":{"line":1,"column":2}},"value":"x"},` +
+		`"span":{"start":{"line":1,"column":1},"end":{"line":1,"column":3}},` +
+		`"token":{"type":"-","literal":"-","pos":{"line":1,"column":1}}},` +
+		`"kind":"ExpressionStatement","span":{"start":{"line":1,"column":1},"end":{"line":1,"column":3}},` +
+		`"token":{"type":"-","literal":"-","pos":{"line":1,"column":1}}}]}`
+	if string(data) != expected {
+		t.Errorf("wrong json.\nwant=%s\ngot =%s", expected, data)
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	inputs := []string{
+		`let x = 5; return x;`,
+		`let add = fn(a, b) { a + b }; add(1, 2.5) * -3`,
+		"if (x > 1) {\n  \"big\"\n} else { !true }\nif (y) { 1 }",
+		`let h = {"b": [1, 2][0], "a": {}, 3: h.b}; h["a"]`,
+		`let m = macro(a) { quote(unquote(a) * 2) }; m(1)`,
+		`fn() {}`,
+		``,
+	}
+
+	for _, input := range inputs {
+		program := parse(t, input)
+		assertRoundTrip(t, input, program)
+	}
+}
+
+func TestJSONRoundTripKeepsResolvedVariables(t *testing.T) {
+	input := `let f = fn(a) { let b = a; fn() { a + b } }; f(1)()`
+	program := parse(t, input)
+	if err := evaluator.Resolve(program, object.NewEnvironment()); err != nil {
+		t.Fatalf("Resolve failed: %s", err.Message)
+	}
+
+	decoded := assertRoundTrip(t, input, program)
+	if result := evaluator.Eval(decoded, object.NewEnvironment()); result.Inspect() != "2" {
+		t.Errorf("decoded program gives %s, want 2", result.Inspect())
+	}
+}
+
+func TestUnmarshalJSONErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`[]`, "cannot unmarshal"},
+		{`{"statements": []}`, "node without a kind"},
+		{`{"kind": "Nope"}`, `unknown node kind "Nope"`},
+		{`{"kind": "Program"}`, "Program without statements"},
+		{`{"kind": "Program", "statements": [{"kind": "Identifier", "token": {}, "value": "x"}]}`, "Identifier is not a statement"},
+		{`{"kind": "Identifier", "token": {}, "value": 1}`, "Identifier.value"},
+		{`{"kind": "ExpressionStatement", "token": {}}`, "ExpressionStatement without expression"},
+		{`{"kind": "ExpressionStatement", "token": {}, "expression": null}`, "expected a Program, got ExpressionStatement"},
+	}
+
+	for _, tt := range tests {
+		var program ast.Program
+		err := json.Unmarshal([]byte(tt.input), &program)
+		if err == nil {
+			t.Errorf("expected an error for %s", tt.input)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.expected) {
+			t.Errorf("wrong error for %s. want=%q, got=%q", tt.input, tt.expected, err)
+		}
+	}
+}
+
+// assertRoundTrip decodes the encoding of program and checks that it encodes the same
+func assertRoundTrip(t *testing.T, input string, program *ast.Program) *ast.Program {
+	t.Helper()
+	data, err := json.Marshal(program)
+	if err != nil {
+		t.Fatalf("Marshal(%q) failed: %s", input, err)
+	}
+
+	decoded := &ast.Program{}
+	if err := json.Unmarshal(data, decoded); err != nil {
+		t.Fatalf("Unmarshal(%q) failed: %s", input, err)
+	}
+	again, err := json.Marshal(decoded)
+	if err != nil {
+		t.Fatalf("Marshal of decoded %q failed: %s", input, err)
+	}
+	if string(again) != string(data) {
+		t.Errorf("round trip of %q changed the tree.\nwant=%s\ngot =%s", input, data, again)
+	}
+	if formatter.Node(decoded) != formatter.Node(program) {
+		t.Errorf("round trip of %q changed the program. want=%q, got=%q", input, formatter.Node(program), formatter.Node(decoded))
+	}
+	return decoded
+}
+
+func parse(t *testing.T, input string) *ast.Program {
+	t.Helper()
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parser errors for %s: %v", input, p.Errors())
+	}
+	return program
+}
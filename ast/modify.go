@@ -0,0 +1,121 @@
+package ast
+
+// ModifierFunc is called by Modify on every node, the node it returns replaces the one it was given
+type ModifierFunc func(Node) Node
+
+// Modify walks the tree rooted at node depth first, children before their parent, replacing every node
+// with what modifier returns for it. It returns the modified tree, the nodes of the original tree are copied
+// instead of being changed, so a tree can be modified more than once (a quote inside a function runs on every call).
+func Modify(node Node, modifier ModifierFunc) Node {
+	switch n := node.(type) {
+	case *Program:
+		copied := *n
+		copied.Statements = modifyStatements(n.Statements, modifier)
+		node = &copied
+	case *ExpressionStatement:
+		copied := *n
+		copied.Expression, _ = Modify(n.Expression, modifier).(Expression)
+		node = &copied
+	case *LetStatement:
+		copied := *n
+		copied.Value, _ = Modify(n.Value, modifier).(Expression)
+		node = &copied
+	case *ReturnStatement:
+		copied := *n
+		copied.ReturnValue, _ = Modify(n.ReturnValue, modifier).(Expression)
+		node = &copied
+	case *BlockStatement:
+		copied := *n
+		copied.Statements = modifyStatements(n.Statements, modifier)
+		node = &copied
+	case *PrefixExpression:
+		copied := *n
+		copied.Right, _ = Modify(n.Right, modifier).(Expression)
+		node = &copied
+	case *InfixExpression:
+		copied := *n
+		copied.Left, _ = Modify(n.Left, modifier).(Expression)
+		copied.Right, _ = Modify(n.Right, modifier).(Expression)
+		node = &copied
+	case *IfElseExpression:
+		copied := *n
+		copied.Condition, _ = Modify(n.Condition, modifier).(Expression)
+		copied.Consequence, _ = Modify(n.Consequence, modifier).(*BlockStatement)
+		if n.Alternative != nil {
+			copied.Alternative, _ = Modify(n.Alternative, modifier).(*BlockStatement)
+		}
+		node = &copied
+	case *FunctionExpression:
+		copied := *n
+		copied.Parameters = modifyIdentifiers(n.Parameters, modifier)
+		copied.Body, _ = Modify(n.Body, modifier).(*BlockStatement)
+		node = &copied
+	case *MacroLiteral:
+		copied := *n
+		copied.Parameters = modifyIdentifiers(n.Parameters, modifier)
+		copied.Body, _ = Modify(n.Body, modifier).(*BlockStatement)
+		node = &copied
+	case *CallExpression:
+		copied := *n
+		copied.Function, _ = Modify(n.Function, modifier).(Expression)
+		copied.Argument = modifyExpressions(n.Argument, modifier)
+		node = &copied
+	case *ArrayLiteral:
+		copied := *n
+		copied.Elements = modifyExpressions(n.Elements, modifier)
+		node = &copied
+	case *IndexExpression:
+		copied := *n
+		copied.Left, _ = Modify(n.Left, modifier).(Expression)
+		copied.Index, _ = Modify(n.Index, modifier).(Expression)
+		node = &copied
+	case *MemberExpression:
+		copied := *n
+		copied.Object, _ = Modify(n.Object, modifier).(Expression)
+		node = &copied
+	case *HashLiteral:
+		copied := *n
+		copied.Pairs = make(map[Expression]Expression, len(n.Pairs))
+		for key, value := range n.Pairs {
+			newKey, _ := Modify(key, modifier).(Expression)
+			newValue, _ := Modify(value, modifier).(Expression)
+			copied.Pairs[newKey] = newValue
+		}
+		node = &copied
+	}
+
+	return modifier(node)
+}
+
+func modifyStatements(statements []Statement, modifier ModifierFunc) []Statement {
+	if statements == nil {
+		return nil
+	}
+	modified := make([]Statement, len(statements))
+	for i, statement := range statements {
+		modified[i], _ = Modify(statement, modifier).(Statement)
+	}
+	return modified
+}
+
+func modifyExpressions(expressions []Expression, modifier ModifierFunc) []Expression {
+	if expressions == nil {
+		return nil
+	}
+	modified := make([]Expression, len(expressions))
+	for i, expression := range expressions {
+		modified[i], _ = Modify(expression, modifier).(Expression)
+	}
+	return modified
+}
+
+func modifyIdentifiers(identifiers []*Identifier, modifier ModifierFunc) []*Identifier {
+	if identifiers == nil {
+		return nil
+	}
+	modified := make([]*Identifier, len(identifiers))
+	for i, identifier := range identifiers {
+		modified[i], _ = Modify(identifier, modifier).(*Identifier)
+	}
+	return modified
+}
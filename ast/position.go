@@ -0,0 +1,133 @@
+package ast
+
+import "github.com/ShivankSharma070/go-interpreter/token"
+
+// Start returns the position of the first token of node. Parentheses are not part of the tree, the start of
+// (a + b) is the position of a.
+func Start(node Node) token.Position {
+	switch node := node.(type) {
+	case *Program:
+		if len(node.Statements) > 0 {
+			return Start(node.Statements[0])
+		}
+	case *ExpressionStatement:
+		if node.Token.Pos != (token.Position{}) {
+			return node.Token.Pos
+		}
+		return Start(node.Expression)
+	case *InfixExpression:
+		return Start(node.Left)
+	case *CallExpression:
+		return Start(node.Function)
+	case *IndexExpression:
+		return Start(node.Left)
+	case *MemberExpression:
+		return Start(node.Object)
+	case *LetStatement:
+		return node.Token.Pos
+	case *ReturnStatement:
+		return node.Token.Pos
+	case *BlockStatement:
+		return node.Token.Pos
+	case *Identifier:
+		return node.Token.Pos
+	case *IntegerLiteral:
+		return node.Token.Pos
+	case *FloatLiteral:
+		return node.Token.Pos
+	case *StringLiteral:
+		return node.Token.Pos
+	case *BoolExpression:
+		return node.Token.Pos
+	case *PrefixExpression:
+		return node.Token.Pos
+	case *IfElseExpression:
+		return node.Token.Pos
+	case *FunctionExpression:
+		return node.Token.Pos
+	case *MacroLiteral:
+		return node.Token.Pos
+	case *ArrayLiteral:
+		return node.Token.Pos
+	case *HashLiteral:
+		return node.Token.Pos
+	}
+	return token.Position{}
+}
+
+// End returns the position just past the last character of node, so the source of node runs from Start up to,
+// but not including, End. The semicolon ending a statement is not part of it. Nodes built outside the parser
+// have no positions and give the zero position.
+func End(node Node) token.Position {
+	switch node := node.(type) {
+	case *Program:
+		if len(node.Statements) > 0 {
+			return End(node.Statements[len(node.Statements)-1])
+		}
+	case *LetStatement:
+		if node.Value != nil {
+			return End(node.Value)
+		}
+		return End(node.Name)
+	case *ReturnStatement:
+		if node.ReturnValue != nil {
+			return End(node.ReturnValue)
+		}
+		return after(node.Token.Pos, node.Token.Literal)
+	case *ExpressionStatement:
+		return End(node.Expression)
+	case *BlockStatement:
+		return after(node.End, "}")
+	case *Identifier:
+		return after(node.Token.Pos, node.Token.Literal)
+	case *IntegerLiteral:
+		return after(node.Token.Pos, node.Token.Literal)
+	case *FloatLiteral:
+		return after(node.Token.Pos, node.Token.Literal)
+	case *BoolExpression:
+		return after(node.Token.Pos, node.Token.Literal)
+	case *StringLiteral:
+		// The token starts at the opening quote, its literal leaves both quotes out
+		return after(node.Token.Pos, `"`+node.Token.Literal+`"`)
+	case *PrefixExpression:
+		return End(node.Right)
+	case *InfixExpression:
+		return End(node.Right)
+	case *IfElseExpression:
+		if node.Alternative != nil {
+			return End(node.Alternative)
+		}
+		return End(node.Consequence)
+	case *FunctionExpression:
+		return End(node.Body)
+	case *MacroLiteral:
+		return End(node.Body)
+	case *CallExpression:
+		return after(node.End, ")")
+	case *ArrayLiteral:
+		return after(node.End, "]")
+	case *IndexExpression:
+		return after(node.End, "]")
+	case *MemberExpression:
+		return End(node.Property)
+	case *HashLiteral:
+		return after(node.End, "}")
+	}
+	return token.Position{}
+}
+
+// after returns the position following text when it starts at pos, text can span lines
+func after(pos token.Position, text string) token.Position {
+	if pos == (token.Position{}) {
+		return pos
+	}
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\n' {
+			pos.Line++
+			pos.Column = 1
+		} else {
+			pos.Column++
+		}
+	}
+	return pos
+}
@@ -0,0 +1,56 @@
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/ShivankSharma070/go-interpreter/ast"
+	"github.com/ShivankSharma070/go-interpreter/lexer"
+	"github.com/ShivankSharma070/go-interpreter/parser"
+	"github.com/ShivankSharma070/go-interpreter/token"
+)
+
+func TestSpans(t *testing.T) {
+	tests := []struct {
+		input string
+		start token.Position
+		end   token.Position
+	}{
+		{`x`, token.Position{Line: 1, Column: 1}, token.Position{Line: 1, Column: 2}},
+		{`  12.5`, token.Position{Line: 1, Column: 3}, token.Position{Line: 1, Column: 7}},
+		{`"abc"`, token.Position{Line: 1, Column: 1}, token.Position{Line: 1, Column: 6}},
+		{"\"a\nbc\"", token.Position{Line: 1, Column: 1}, token.Position{Line: 2, Column: 4}},
+		{`(a + b) * c`, token.Position{Line: 1, Column: 1}, token.Position{Line: 1, Column: 12}},
+		{`-a.b`, token.Position{Line: 1, Column: 1}, token.Position{Line: 1, Column: 5}},
+		{`f(1, 2 )`, token.Position{Line: 1, Column: 1}, token.Position{Line: 1, Column: 9}},
+		{`a[1 ]`, token.Position{Line: 1, Column: 1}, token.Position{Line: 1, Column: 6}},
+		{`[1, 2]`, token.Position{Line: 1, Column: 1}, token.Position{Line: 1, Column: 7}},
+		{`{"a": 1 }`, token.Position{Line: 1, Column: 1}, token.Position{Line: 1, Column: 10}},
+		{"if (x) {\n1\n} else {\n2\n}", token.Position{Line: 1, Column: 1}, token.Position{Line: 5, Column: 2}},
+		{"fn(x) {\n  x\n}", token.Position{Line: 1, Column: 1}, token.Position{Line: 3, Column: 2}},
+		// The semicolon is not part of a statement
+		{`let x = 5;`, token.Position{Line: 1, Column: 1}, token.Position{Line: 1, Column: 10}},
+		{`return f();`, token.Position{Line: 1, Column: 1}, token.Position{Line: 1, Column: 11}},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			t.Fatalf("parser errors for %q: %v", tt.input, p.Errors())
+		}
+		statement := program.Statements[0]
+		if start := ast.Start(statement); start != tt.start {
+			t.Errorf("wrong start of %q. want=%s, got=%s", tt.input, tt.start, start)
+		}
+		if end := ast.End(statement); end != tt.end {
+			t.Errorf("wrong end of %q. want=%s, got=%s", tt.input, tt.end, end)
+		}
+	}
+}
+
+func TestSpansWithoutPositions(t *testing.T) {
+	node := &ast.CallExpression{Function: &ast.Identifier{Value: "f"}}
+	if start, end := ast.Start(node), ast.End(node); start != (token.Position{}) || end != (token.Position{}) {
+		t.Errorf("expected zero positions for a node built by hand, got %s to %s", start, end)
+	}
+}
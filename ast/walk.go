@@ -0,0 +1,176 @@
+package ast
+
+import "fmt"
+
+// A Visitor is called by Walk for every node. When the visitor w it returns is not nil, Walk visits the children
+// of the node with w, followed by a call of w.Visit(nil).
+type Visitor interface {
+	Visit(node Node) (w Visitor)
+}
+
+// Walk traverses the tree rooted at node depth first, calling v.Visit(node) before the children of the node.
+// Every child is visited in source order: the name of a let, the parameters of a function, the property of a
+// member expression and both the keys and the values of a hash. Missing children, like the else branch of an if
+// without one, are skipped.
+func Walk(node Node, v Visitor) {
+	if node == nil || isNilNode(node) {
+		return
+	}
+	if v = v.Visit(node); v == nil {
+		return
+	}
+
+	switch n := node.(type) {
+	case *Program:
+		walkList(n.Statements, v)
+	case *LetStatement:
+		Walk(n.Name, v)
+		Walk(n.Value, v)
+	case *ReturnStatement:
+		Walk(n.ReturnValue, v)
+	case *ExpressionStatement:
+		Walk(n.Expression, v)
+	case *BlockStatement:
+		walkList(n.Statements, v)
+	case *PrefixExpression:
+		Walk(n.Right, v)
+	case *InfixExpression:
+		Walk(n.Left, v)
+		Walk(n.Right, v)
+	case *IfElseExpression:
+		Walk(n.Condition, v)
+		Walk(n.Consequence, v)
+		Walk(n.Alternative, v)
+	case *FunctionExpression:
+		walkList(n.Parameters, v)
+		Walk(n.Body, v)
+	case *MacroLiteral:
+		walkList(n.Parameters, v)
+		Walk(n.Body, v)
+	case *CallExpression:
+		Walk(n.Function, v)
+		walkList(n.Argument, v)
+	case *ArrayLiteral:
+		walkList(n.Elements, v)
+	case *IndexExpression:
+		Walk(n.Left, v)
+		Walk(n.Index, v)
+	case *MemberExpression:
+		Walk(n.Object, v)
+		Walk(n.Property, v)
+	case *HashLiteral:
+		for _, key := range sortedKeys(n) {
+			Walk(key, v)
+			Walk(n.Pairs[key], v)
+		}
+	}
+
+	v.Visit(nil)
+}
+
+func walkList[T Node](nodes []T, v Visitor) {
+	for _, node := range nodes {
+		Walk(node, v)
+	}
+}
+
+type inspector func(Node) bool
+
+func (f inspector) Visit(node Node) Visitor {
+	if f(node) {
+		return f
+	}
+	return nil
+}
+
+// Inspect traverses the tree rooted at node like Walk, calling f for every node. The children of a node are only
+// visited when f returns true for it, f(nil) follows the children.
+func Inspect(node Node, f func(Node) bool) {
+	Walk(node, inspector(f))
+}
+
+// RewriteFunc is called by Rewrite for every node, the node it returns replaces the one it was given
+type RewriteFunc func(Node) Node
+
+// Rewrite traverses the tree rooted at node like Walk, children before their parent, and replaces every node with
+// what rewrite returns for it. Unlike Modify the tree is changed in place, and every child is visited, the names
+// of lets and parameters included. It returns the replacement of node itself. Replacing a node with nil leaves its
+// child missing. Rewrite panics when a node is replaced with one that does not fit where it is, like the name of a
+// let replaced with an integer.
+func Rewrite(node Node, rewrite RewriteFunc) Node {
+	if node == nil || isNilNode(node) {
+		return node
+	}
+
+	switch n := node.(type) {
+	case *Program:
+		rewriteList(n.Statements, rewrite)
+	case *LetStatement:
+		n.Name = rewriteAs[*Identifier](n.Name, rewrite)
+		n.Value = rewriteAs[Expression](n.Value, rewrite)
+	case *ReturnStatement:
+		n.ReturnValue = rewriteAs[Expression](n.ReturnValue, rewrite)
+	case *ExpressionStatement:
+		n.Expression = rewriteAs[Expression](n.Expression, rewrite)
+	case *BlockStatement:
+		rewriteList(n.Statements, rewrite)
+	case *PrefixExpression:
+		n.Right = rewriteAs[Expression](n.Right, rewrite)
+	case *InfixExpression:
+		n.Left = rewriteAs[Expression](n.Left, rewrite)
+		n.Right = rewriteAs[Expression](n.Right, rewrite)
+	case *IfElseExpression:
+		n.Condition = rewriteAs[Expression](n.Condition, rewrite)
+		n.Consequence = rewriteAs[*BlockStatement](n.Consequence, rewrite)
+		n.Alternative = rewriteAs[*BlockStatement](n.Alternative, rewrite)
+	case *FunctionExpression:
+		rewriteList(n.Parameters, rewrite)
+		n.Body = rewriteAs[*BlockStatement](n.Body, rewrite)
+	case *MacroLiteral:
+		rewriteList(n.Parameters, rewrite)
+		n.Body = rewriteAs[*BlockStatement](n.Body, rewrite)
+	case *CallExpression:
+		n.Function = rewriteAs[Expression](n.Function, rewrite)
+		rewriteList(n.Argument, rewrite)
+	case *ArrayLiteral:
+		rewriteList(n.Elements, rewrite)
+	case *IndexExpression:
+		n.Left = rewriteAs[Expression](n.Left, rewrite)
+		n.Index = rewriteAs[Expression](n.Index, rewrite)
+	case *MemberExpression:
+		n.Object = rewriteAs[Expression](n.Object, rewrite)
+		n.Property = rewriteAs[*Identifier](n.Property, rewrite)
+	case *HashLiteral:
+		pairs := make(map[Expression]Expression, len(n.Pairs))
+		for _, key := range sortedKeys(n) {
+			value := n.Pairs[key]
+			pairs[rewriteAs[Expression](key, rewrite)] = rewriteAs[Expression](value, rewrite)
+		}
+		n.Pairs = pairs
+	}
+
+	return rewrite(node)
+}
+
+// rewriteAs rewrites a child stored in a field of type T
+func rewriteAs[T Node](node T, rewrite RewriteFunc) T {
+	if Node(node) == nil || isNilNode(node) {
+		return node
+	}
+	replaced := Rewrite(node, rewrite)
+	if replaced == nil {
+		var missing T
+		return missing
+	}
+	child, ok := replaced.(T)
+	if !ok {
+		panic(fmt.Sprintf("ast: Rewrite cannot replace %s with %s", kindOf(node), kindOf(replaced)))
+	}
+	return child
+}
+
+func rewriteList[T Node](nodes []T, rewrite RewriteFunc) {
+	for i, node := range nodes {
+		nodes[i] = rewriteAs(node, rewrite)
+	}
+}
@@ -0,0 +1,225 @@
+package ast_test
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ShivankSharma070/go-interpreter/ast"
+	"github.com/ShivankSharma070/go-interpreter/formatter"
+	"github.com/ShivankSharma070/go-interpreter/token"
+)
+
+// recorder is a Visitor writing down the nodes it visits, nested by depth
+type recorder struct {
+	out   *[]string
+	depth int
+}
+
+func (r recorder) Visit(node ast.Node) ast.Visitor {
+	if node == nil {
+		*r.out = append(*r.out, strings.Repeat(" ", r.depth-1)+"end")
+		return nil
+	}
+	*r.out = append(*r.out, strings.Repeat(" ", r.depth)+describe(node))
+	return recorder{r.out, r.depth + 1}
+}
+
+func describe(node ast.Node) string {
+	name := fmt.Sprintf("%T", node)[len("*ast."):]
+	switch node := node.(type) {
+	case *ast.Identifier:
+		return name + " " + node.Value
+	case *ast.IntegerLiteral, *ast.StringLiteral:
+		return name + " " + node.String()
+	}
+	return name
+}
+
+func TestWalk(t *testing.T) {
+	program := parse(t, `let f = fn(a, b) { {"k": a}.k[b] }; if (f) { 1 }`)
+	expected := []string{
+		"Program",
+		" LetStatement",
+		"  Identifier f",
+		"  end",
+		"  FunctionExpression",
+		"   Identifier a",
+		"   end",
+		"   Identifier b",
+		"   end",
+		"   BlockStatement",
+		"    ExpressionStatement",
+		"     IndexExpression",
+		"      MemberExpression",
+		"       HashLiteral",
+		"        StringLiteral k",
+		"        end",
+		"        Identifier a",
+		"        end",
+		"       end",
+		"       Identifier k",
+		"       end",
+		"      end",
+		"      Identifier b",
+		"      end",
+		"     end",
+		"    end",
+		"   end",
+		"  end",
+		" end",
+		" ExpressionStatement",
+		"  IfElseExpression",
+		"   Identifier f",
+		"   end",
+		"   BlockStatement",
+		"    ExpressionStatement",
+		"     IntegerLiteral 1",
+		"     end",
+		"    end",
+		"   end",
+		"  end",
+		" end",
+		"end",
+	}
+
+	var got []string
+	ast.Walk(program, recorder{out: &got})
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("wrong walk.\nwant=\n%s\ngot=\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+}
+
+func TestWalkVisitsHashPairsInSourceOrder(t *testing.T) {
+	program := parse(t, `{"c": 1, "a": 2, "b": 3, 4: 5}`)
+	var got []string
+	ast.Inspect(program, func(node ast.Node) bool {
+		switch node.(type) {
+		case *ast.StringLiteral, *ast.IntegerLiteral:
+			got = append(got, node.String())
+		}
+		return true
+	})
+	if strings.Join(got, " ") != "c 1 a 2 b 3 4 5" {
+		t.Errorf("wrong order of hash pairs, got %v", got)
+	}
+}
+
+func TestInspect(t *testing.T) {
+	program := parse(t, `let x = f(1, fn(y) { g(y) }); h(x)`)
+
+	// Calls outside of functions, the children of a function are skipped
+	var calls []string
+	ast.Inspect(program, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.CallExpression:
+			calls = append(calls, node.Function.String())
+		case *ast.FunctionExpression:
+			return false
+		}
+		return true
+	})
+	if strings.Join(calls, " ") != "f h" {
+		t.Errorf("wrong calls, got %v", calls)
+	}
+
+	count := 0
+	ast.Inspect(program, func(node ast.Node) bool {
+		if _, ok := node.(*ast.Identifier); ok {
+			count++
+		}
+		return true
+	})
+	// x, f, y, g, y, h, x
+	if count != 7 {
+		t.Errorf("wrong number of identifiers. want=7, got=%d", count)
+	}
+}
+
+func TestRewrite(t *testing.T) {
+	tests := []struct {
+		input    string
+		rewrite  ast.RewriteFunc
+		expected string
+	}{
+		{
+			// Every identifier is renamed, the names of lets, parameters and members included
+			`let a = fn(b) { b.c + a }; a(1)`,
+			func(node ast.Node) ast.Node {
+				if iden, ok := node.(*ast.Identifier); ok {
+					iden.Value = strings.ToUpper(iden.Value)
+				}
+				return node
+			},
+			"let A = fn(B) { B.C + A };\nA(1);",
+		},
+		{
+			// Children are rewritten before their parent
+			`1 + 2 * 3`,
+			func(node ast.Node) ast.Node {
+				infix, ok := node.(*ast.InfixExpression)
+				if !ok {
+					return node
+				}
+				left, _ := infix.Left.(*ast.IntegerLiteral)
+				right, _ := infix.Right.(*ast.IntegerLiteral)
+				if left == nil || right == nil {
+					return node
+				}
+				value := left.Value + right.Value
+				return &ast.IntegerLiteral{Value: value, Token: token.Token{Type: token.INT, Literal: strconv.FormatInt(value, 10)}}
+			},
+			"6;",
+		},
+		{
+			`{"a": 1, "b": 2}`,
+			func(node ast.Node) ast.Node {
+				if str, ok := node.(*ast.StringLiteral); ok {
+					str.Value += str.Value
+					str.Token.Literal = str.Value
+				}
+				return node
+			},
+			"{\"aa\": 1, \"bb\": 2};",
+		},
+		{
+			`if (x) { 1 } else { 2 }`,
+			func(node ast.Node) ast.Node {
+				if ifElse, ok := node.(*ast.IfElseExpression); ok {
+					ifElse.Alternative = nil
+				}
+				return node
+			},
+			"if (x) { 1 }",
+		},
+	}
+
+	for _, tt := range tests {
+		program := parse(t, tt.input)
+		rewritten := ast.Rewrite(program, tt.rewrite)
+		if rewritten != ast.Node(program) {
+			t.Errorf("Rewrite of %s did not return the program it changed", tt.input)
+		}
+		if got := formatter.Node(program); got != tt.expected {
+			t.Errorf("wrong rewrite of %s. want=%q, got=%q", tt.input, tt.expected, got)
+		}
+	}
+}
+
+func TestRewritePanicsOnMisfits(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err == nil || !strings.Contains(fmt.Sprint(err), "cannot replace Identifier with IntegerLiteral") {
+			t.Errorf("wrong panic, got %v", err)
+		}
+	}()
+
+	program := parse(t, `let a = 1`)
+	ast.Rewrite(program, func(node ast.Node) ast.Node {
+		if _, ok := node.(*ast.Identifier); ok {
+			return &ast.IntegerLiteral{Value: 1}
+		}
+		return node
+	})
+}
@@ -0,0 +1,246 @@
+// Package code defines the bytecode instructions run by the vm and produced by the compiler.
+package code
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"sort"
+
+	"github.com/ShivankSharma070/go-interpreter/token"
+)
+
+// Instructions is a sequence of encoded instructions, an opcode followed by its operands
+type Instructions []byte
+
+type Opcode byte
+
+const (
+	OpConstant Opcode = iota // Push the constant at the index given by the operand
+	OpPop                    // Discard the top of the stack
+
+	// Infix operators, they pop the left operand then the right one and push the result.
+	// Operands are evaluated right to left, the same way the evaluator does.
+	OpAdd
+	OpSub
+	OpMul
+	OpDiv
+	OpEqual
+	OpNotEqual
+	OpGreaterThan
+	OpLessThan
+
+	// Prefix operators
+	OpMinus
+	OpBang
+
+	OpTrue
+	OpFalse
+	OpNull
+
+	OpJump          // Jump to the operand
+	OpJumpNotTruthy // Pop the top of the stack and jump to the operand if it is not truthy
+
+	OpGetGlobal
+	OpSetGlobal
+	OpGetLocal
+	OpSetLocal
+	OpGetCell      // Push the value of the local at the operand, a local captured by closures which holds a cell
+	OpSetCell      // Pop a value into the cell of the local at the operand
+	OpGetFree      // Push the value of the free variable at the operand
+	OpCaptureLocal // Push the cell of the local at the operand, for OpClosure to capture it
+	OpCaptureFree  // Push the cell of the free variable at the operand, for OpClosure to capture it again
+	OpGetBuiltin
+
+	OpArray // Build an array out of the number of elements given by the operand
+	OpHash  // Build a hash out of the number of keys and values given by the operand
+	OpIndex
+	OpMember // Access the member named by the constant at the operand
+
+	OpCall        // Call a function with the number of arguments given by the operand
+	OpReturnValue // Return the top of the stack
+	OpReturn      // Return without a value
+	OpClosure     // Create a closure of the function constant at the first operand, capturing the number of cells given by the second one
+
+	OpQuote // Fill the unquote placeholders of the quote constant at the first operand with the number of values given by the second one
+)
+
+// Definition describes an opcode, its name is used by the disassembler and OperandWidths holds the size in bytes of every operand
+type Definition struct {
+	Name          string
+	OperandWidths []int
+}
+
+var definitions = map[Opcode]*Definition{
+	OpConstant: {"OpConstant", []int{2}},
+	OpPop:      {"OpPop", []int{}},
+
+	OpAdd:         {"OpAdd", []int{}},
+	OpSub:         {"OpSub", []int{}},
+	OpMul:         {"OpMul", []int{}},
+	OpDiv:         {"OpDiv", []int{}},
+	OpEqual:       {"OpEqual", []int{}},
+	OpNotEqual:    {"OpNotEqual", []int{}},
+	OpGreaterThan: {"OpGreaterThan", []int{}},
+	OpLessThan:    {"OpLessThan", []int{}},
+
+	OpMinus: {"OpMinus", []int{}},
+	OpBang:  {"OpBang", []int{}},
+
+	OpTrue:  {"OpTrue", []int{}},
+	OpFalse: {"OpFalse", []int{}},
+	OpNull:  {"OpNull", []int{}},
+
+	OpJump:          {"OpJump", []int{2}},
+	OpJumpNotTruthy: {"OpJumpNotTruthy", []int{2}},
+
+	OpGetGlobal:    {"OpGetGlobal", []int{2}},
+	OpSetGlobal:    {"OpSetGlobal", []int{2}},
+	OpGetLocal:     {"OpGetLocal", []int{1}},
+	OpSetLocal:     {"OpSetLocal", []int{1}},
+	OpGetCell:      {"OpGetCell", []int{1}},
+	OpSetCell:      {"OpSetCell", []int{1}},
+	OpGetFree:      {"OpGetFree", []int{1}},
+	OpCaptureLocal: {"OpCaptureLocal", []int{1}},
+	OpCaptureFree:  {"OpCaptureFree", []int{1}},
+	OpGetBuiltin:   {"OpGetBuiltin", []int{2}},
+
+	OpArray:  {"OpArray", []int{2}},
+	OpHash:   {"OpHash", []int{2}},
+	OpIndex:  {"OpIndex", []int{}},
+	OpMember: {"OpMember", []int{2}},
+
+	OpCall:        {"OpCall", []int{1}},
+	OpReturnValue: {"OpReturnValue", []int{}},
+	OpReturn:      {"OpReturn", []int{}},
+	OpClosure:     {"OpClosure", []int{2, 1}},
+
+	OpQuote: {"OpQuote", []int{2, 1}},
+}
+
+func Lookup(op byte) (*Definition, error) {
+	def, ok := definitions[Opcode(op)]
+	if !ok {
+		return nil, fmt.Errorf("opcode %d undefined", op)
+	}
+	return def, nil
+}
+
+// Make encodes an instruction, operands are big endian
+func Make(op Opcode, operands ...int) []byte {
+	def, ok := definitions[op]
+	if !ok {
+		return []byte{}
+	}
+
+	instructionLen := 1
+	for _, w := range def.OperandWidths {
+		instructionLen += w
+	}
+
+	instruction := make([]byte, instructionLen)
+	instruction[0] = byte(op)
+
+	offset := 1
+	for i, o := range operands {
+		width := def.OperandWidths[i]
+		switch width {
+		case 2:
+			binary.BigEndian.PutUint16(instruction[offset:], uint16(o))
+		case 1:
+			instruction[offset] = byte(o)
+		}
+		offset += width
+	}
+	return instruction
+}
+
+// CheckOperands returns an error when an operand of op does not fit in its width, Make would truncate it
+func CheckOperands(op Opcode, operands ...int) error {
+	def, err := Lookup(byte(op))
+	if err != nil {
+		return err
+	}
+	for i, o := range operands {
+		if max := 1<<(8*def.OperandWidths[i]) - 1; o < 0 || o > max {
+			return fmt.Errorf("operand of %s out of range, got=%d, max=%d", def.Name, o, max)
+		}
+	}
+	return nil
+}
+
+// ReadOperands decodes the operands of an instruction, it returns them along with the number of bytes read
+func ReadOperands(def *Definition, ins Instructions) ([]int, int) {
+	operands := make([]int, len(def.OperandWidths))
+	offset := 0
+
+	for i, width := range def.OperandWidths {
+		switch width {
+		case 2:
+			operands[i] = int(ReadUint16(ins[offset:]))
+		case 1:
+			operands[i] = int(ReadUint8(ins[offset:]))
+		}
+		offset += width
+	}
+	return operands, offset
+}
+
+func ReadUint16(ins Instructions) uint16 { return binary.BigEndian.Uint16(ins) }
+
+func ReadUint8(ins Instructions) uint8 { return uint8(ins[0]) }
+
+// String disassembles the instructions, one per line prefixed by its offset
+func (ins Instructions) String() string {
+	var out bytes.Buffer
+
+	i := 0
+	for i < len(ins) {
+		def, err := Lookup(ins[i])
+		if err != nil {
+			fmt.Fprintf(&out, "ERROR: %s\n", err)
+			i++
+			continue
+		}
+
+		operands, read := ReadOperands(def, ins[i+1:])
+		fmt.Fprintf(&out, "%04d %s\n", i, ins.fmtInstruction(def, operands))
+		i += 1 + read
+	}
+	return out.String()
+}
+
+func (ins Instructions) fmtInstruction(def *Definition, operands []int) string {
+	operandCount := len(def.OperandWidths)
+	if len(operands) != operandCount {
+		return fmt.Sprintf("ERROR: operand len %d does not match defined %d\n", len(operands), operandCount)
+	}
+
+	switch operandCount {
+	case 0:
+		return def.Name
+	case 1:
+		return fmt.Sprintf("%s %d", def.Name, operands[0])
+	case 2:
+		return fmt.Sprintf("%s %d %d", def.Name, operands[0], operands[1])
+	}
+	return fmt.Sprintf("ERROR: unhandled operandCount for %s\n", def.Name)
+}
+
+// PositionEntry maps the instructions starting at Offset to the source position they were compiled from
+type PositionEntry struct {
+	Offset int
+	Pos    token.Position
+}
+
+// Positions is the table of source positions of some instructions, entries are sorted by offset
+type Positions []PositionEntry
+
+// Lookup returns the position of the instruction at offset, the zero position when it is unknown
+func (p Positions) Lookup(offset int) token.Position {
+	i := sort.Search(len(p), func(i int) bool { return p[i].Offset > offset })
+	if i == 0 {
+		return token.Position{}
+	}
+	return p[i-1].Pos
+}
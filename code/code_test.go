@@ -0,0 +1,134 @@
+package code
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ShivankSharma070/go-interpreter/token"
+)
+
+func TestMake(t *testing.T) {
+	tests := []struct {
+		op       Opcode
+		operands []int
+		expected []byte
+	}{
+		{OpConstant, []int{65534}, []byte{byte(OpConstant), 255, 254}},
+		{OpAdd, []int{}, []byte{byte(OpAdd)}},
+		{OpGetLocal, []int{255}, []byte{byte(OpGetLocal), 255}},
+		{OpClosure, []int{65534, 255}, []byte{byte(OpClosure), 255, 254, 255}},
+	}
+
+	for _, tt := range tests {
+		instruction := Make(tt.op, tt.operands...)
+		if len(instruction) != len(tt.expected) {
+			t.Fatalf("instruction has wrong length, want=%d, got=%d", len(tt.expected), len(instruction))
+		}
+		for i, b := range tt.expected {
+			if instruction[i] != b {
+				t.Errorf("wrong byte at pos %d, want=%d, got=%d", i, b, instruction[i])
+			}
+		}
+	}
+}
+
+func TestInstructionsString(t *testing.T) {
+	instructions := []Instructions{
+		Make(OpAdd),
+		Make(OpGetLocal, 1),
+		Make(OpConstant, 2),
+		Make(OpConstant, 65535),
+		Make(OpClosure, 65535, 255),
+	}
+
+	expected := `0000 OpAdd
+0001 OpGetLocal 1
+0003 OpConstant 2
+0006 OpConstant 65535
+0009 OpClosure 65535 255
+`
+
+	concatted := Instructions{}
+	for _, ins := range instructions {
+		concatted = append(concatted, ins...)
+	}
+	if concatted.String() != expected {
+		t.Errorf("instructions wrongly formatted.\nwant=%q\ngot=%q", expected, concatted.String())
+	}
+}
+
+func TestCheckOperands(t *testing.T) {
+	tests := []struct {
+		op       Opcode
+		operands []int
+		expected string
+	}{
+		{OpConstant, []int{65535}, ""},
+		{OpConstant, []int{65536}, "operand of OpConstant out of range, got=65536, max=65535"},
+		{OpJump, []int{-1}, "operand of OpJump out of range, got=-1, max=65535"},
+		{OpClosure, []int{1, 256}, "operand of OpClosure out of range, got=256, max=255"},
+	}
+
+	for _, tt := range tests {
+		err := CheckOperands(tt.op, tt.operands...)
+		if got := fmt.Sprint(err); (err == nil) != (tt.expected == "") || err != nil && got != tt.expected {
+			t.Errorf("wrong error for %v. want=%q, got=%v", tt.operands, tt.expected, err)
+		}
+	}
+}
+
+func TestReadOperands(t *testing.T) {
+	tests := []struct {
+		op        Opcode
+		operands  []int
+		bytesRead int
+	}{
+		{OpConstant, []int{65535}, 2},
+		{OpGetLocal, []int{255}, 1},
+		{OpClosure, []int{65535, 255}, 3},
+	}
+
+	for _, tt := range tests {
+		instruction := Make(tt.op, tt.operands...)
+		def, err := Lookup(byte(tt.op))
+		if err != nil {
+			t.Fatalf("definition not found: %q", err)
+		}
+
+		operandsRead, n := ReadOperands(def, instruction[1:])
+		if n != tt.bytesRead {
+			t.Fatalf("n wrong, want=%d, got=%d", tt.bytesRead, n)
+		}
+		for i, want := range tt.operands {
+			if operandsRead[i] != want {
+				t.Errorf("operand wrong, want=%d, got=%d", want, operandsRead[i])
+			}
+		}
+	}
+}
+
+func TestPositionsLookup(t *testing.T) {
+	positions := Positions{
+		{Offset: 0, Pos: token.Position{Line: 1, Column: 1}},
+		{Offset: 4, Pos: token.Position{Line: 2, Column: 3}},
+	}
+
+	tests := []struct {
+		offset   int
+		expected token.Position
+	}{
+		{0, token.Position{Line: 1, Column: 1}},
+		{3, token.Position{Line: 1, Column: 1}},
+		{4, token.Position{Line: 2, Column: 3}},
+		{100, token.Position{Line: 2, Column: 3}},
+	}
+
+	for _, tt := range tests {
+		if got := positions.Lookup(tt.offset); got != tt.expected {
+			t.Errorf("position of offset %d is not %s, got %s", tt.offset, tt.expected, got)
+		}
+	}
+	if got := (Positions{}).Lookup(3); got != (token.Position{}) {
+		t.Errorf("empty table returned %s", got)
+	}
+}
@@ -0,0 +1,600 @@
+// Package compiler lowers the ast of a program to bytecode run by the vm.
+package compiler
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ShivankSharma070/go-interpreter/ast"
+	"github.com/ShivankSharma070/go-interpreter/code"
+	"github.com/ShivankSharma070/go-interpreter/evaluator"
+	"github.com/ShivankSharma070/go-interpreter/object"
+	"github.com/ShivankSharma070/go-interpreter/token"
+)
+
+// Bytecode is a compiled program, the instructions of the top level code and the constants they refer to
+type Bytecode struct {
+	Instructions code.Instructions
+	Positions    code.Positions
+	Constants    []object.Object
+	GlobalNames  []string // Names of the global variables, indexed by their slot
+}
+
+type EmittedInstruction struct {
+	Opcode   code.Opcode
+	Position int
+}
+
+// CompilationScope holds the instructions of the function being compiled
+type CompilationScope struct {
+	instructions        code.Instructions
+	positions           code.Positions
+	lastInstruction     EmittedInstruction
+	previousInstruction EmittedInstruction
+
+	cells      map[int]bool // Slots of the function read by closures, they hold a cell shared with them
+	localNames []string     // Names of the locals, indexed by their slot
+	free       []freeVariable
+	freeIndex  map[freeVariable]int
+	freeNames  []string
+}
+
+// freeVariable is a local of an enclosing function used by the function being compiled, depth functions up
+type freeVariable struct {
+	depth, slot int
+}
+
+type Compiler struct {
+	constants   []object.Object
+	symbolTable *SymbolTable
+
+	scopes     []CompilationScope
+	scopeIndex int
+
+	err error // Set by the first operand too large for its instruction, the program cannot be compiled then
+}
+
+var builtinIndex = func() map[string]int {
+	index := make(map[string]int)
+	for i, name := range evaluator.BuiltinNames() {
+		index[name] = i
+	}
+	return index
+}()
+
+func New() *Compiler {
+	return NewWithState(NewSymbolTable(), []object.Object{})
+}
+
+// NewWithState creates a compiler continuing the compilation of a program, with the globals and constants already defined
+func NewWithState(s *SymbolTable, constants []object.Object) *Compiler {
+	return &Compiler{
+		constants:   constants,
+		symbolTable: s,
+		scopes:      []CompilationScope{{}},
+	}
+}
+
+func (c *Compiler) Compile(node ast.Node) error {
+	if err := c.compile(node); err != nil {
+		return err
+	}
+	return c.err
+}
+
+func (c *Compiler) compile(node ast.Node) error {
+	switch node := node.(type) {
+	case *ast.Program:
+		evaluator.ResolveLocals(node)
+		for _, s := range node.Statements {
+			if err := c.Compile(s); err != nil {
+				return err
+			}
+		}
+		// The program evaluates to its last statement, when it is an expression
+		if len(node.Statements) > 0 && c.lastInstructionIs(code.OpPop) {
+			if _, ok := node.Statements[len(node.Statements)-1].(*ast.ExpressionStatement); ok {
+				c.replaceLastPopWithReturn()
+				return nil
+			}
+		}
+		c.emit(code.OpReturn)
+
+	case *ast.ExpressionStatement:
+		if node.Expression == nil {
+			return nil
+		}
+		c.addPosition(node.Token.Pos)
+		if err := c.Compile(node.Expression); err != nil {
+			return err
+		}
+		c.emit(code.OpPop)
+
+	case *ast.BlockStatement:
+		for _, s := range node.Statements {
+			if err := c.Compile(s); err != nil {
+				return err
+			}
+		}
+
+	case *ast.LetStatement:
+		c.addPosition(node.Token.Pos)
+		var err error
+		if fn, ok := node.Value.(*ast.FunctionExpression); ok {
+			err = c.compileFunction(fn, node.Name.Value)
+		} else {
+			err = c.Compile(node.Value)
+		}
+		if err != nil {
+			return err
+		}
+		c.storeIdentifier(node.Name)
+
+	case *ast.ReturnStatement:
+		c.addPosition(node.Token.Pos)
+		if err := c.Compile(node.ReturnValue); err != nil {
+			return err
+		}
+		c.emit(code.OpReturnValue)
+
+	case *ast.Identifier:
+		c.loadIdentifier(node)
+
+	case *ast.IntegerLiteral:
+		c.emit(code.OpConstant, c.addConstant(&object.Integer{Value: node.Value}))
+
+	case *ast.FloatLiteral:
+		c.emit(code.OpConstant, c.addConstant(&object.Float{Value: node.Value}))
+
+	case *ast.StringLiteral:
+		c.emit(code.OpConstant, c.addConstant(&object.String{Value: node.Value}))
+
+	case *ast.BoolExpression:
+		if node.Value {
+			c.emit(code.OpTrue)
+		} else {
+			c.emit(code.OpFalse)
+		}
+
+	case *ast.PrefixExpression:
+		if err := c.Compile(node.Right); err != nil {
+			return err
+		}
+		switch node.Operator {
+		case "!":
+			c.emit(code.OpBang)
+		case "-":
+			c.emit(code.OpMinus)
+		default:
+			return fmt.Errorf("unknown operator %s", node.Operator)
+		}
+
+	case *ast.InfixExpression:
+		// The right operand is evaluated first, like the evaluator does
+		if err := c.Compile(node.Right); err != nil {
+			return err
+		}
+		if err := c.Compile(node.Left); err != nil {
+			return err
+		}
+		op, ok := infixOpcodes[node.Operator]
+		if !ok {
+			return fmt.Errorf("unknown operator %s", node.Operator)
+		}
+		c.emit(op)
+
+	case *ast.IfElseExpression:
+		return c.compileIfExpression(node)
+
+	case *ast.FunctionExpression:
+		return c.compileFunction(node, "")
+
+	case *ast.MacroLiteral:
+		return fmt.Errorf("macro literals can only be bound by a top level let statement")
+
+	case *ast.CallExpression:
+		if iden, ok := node.Function.(*ast.Identifier); ok && iden.Value == "quote" {
+			if len(node.Argument) != 1 {
+				return fmt.Errorf("wrong number of arguments. got=%d, want=1", len(node.Argument))
+			}
+			return c.compileQuote(node.Argument[0])
+		}
+
+		if err := c.Compile(node.Function); err != nil {
+			return err
+		}
+		for _, arg := range node.Argument {
+			if err := c.Compile(arg); err != nil {
+				return err
+			}
+		}
+		if len(node.Argument) > 255 {
+			return fmt.Errorf("too many arguments in call, got=%d, max=255", len(node.Argument))
+		}
+		c.addPosition(node.Token.Pos)
+		c.emit(code.OpCall, len(node.Argument))
+
+	case *ast.ArrayLiteral:
+		for _, el := range node.Elements {
+			if err := c.Compile(el); err != nil {
+				return err
+			}
+		}
+		c.emit(code.OpArray, len(node.Elements))
+
+	case *ast.HashLiteral:
+		// Keys are sorted so the same hash always compiles to the same instructions
+		keys := make([]ast.Expression, 0, len(node.Pairs))
+		for key := range node.Pairs {
+			keys = append(keys, key)
+		}
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+
+		for _, key := range keys {
+			if err := c.Compile(key); err != nil {
+				return err
+			}
+			if err := c.Compile(node.Pairs[key]); err != nil {
+				return err
+			}
+		}
+		c.emit(code.OpHash, len(node.Pairs)*2)
+
+	case *ast.IndexExpression:
+		if err := c.Compile(node.Left); err != nil {
+			return err
+		}
+		if err := c.Compile(node.Index); err != nil {
+			return err
+		}
+		c.emit(code.OpIndex)
+
+	case *ast.MemberExpression:
+		if err := c.Compile(node.Object); err != nil {
+			return err
+		}
+		c.emit(code.OpMember, c.addConstant(&object.String{Value: node.Property.Value}))
+
+	default:
+		return fmt.Errorf("cannot compile %T", node)
+	}
+
+	return nil
+}
+
+var infixOpcodes = map[string]code.Opcode{
+	"+":  code.OpAdd,
+	"-":  code.OpSub,
+	"*":  code.OpMul,
+	"/":  code.OpDiv,
+	"==": code.OpEqual,
+	"!=": code.OpNotEqual,
+	">":  code.OpGreaterThan,
+	"<":  code.OpLessThan,
+}
+
+func (c *Compiler) Bytecode() *Bytecode {
+	return &Bytecode{
+		Instructions: c.currentInstructions(),
+		Positions:    c.scopes[c.scopeIndex].positions,
+		Constants:    c.constants,
+		GlobalNames:  c.symbolTable.Names(),
+	}
+}
+
+// loadIdentifier pushes the value of a variable. Locals are the ones the resolver of the evaluator found, so both
+// engines agree on what a name refers to. Like the evaluator, globals shadow builtins which shadow constants.
+// A name that is not defined yet is taken as a global defined later on, reading it before that is an error at runtime.
+func (c *Compiler) loadIdentifier(node *ast.Identifier) {
+	if node.Local {
+		scope := &c.scopes[c.scopeIndex]
+		switch {
+		case node.Depth > 0:
+			c.emit(code.OpGetFree, c.freeVariable(node.Depth, node.Slot, node.Value))
+		case scope.cells[node.Slot]:
+			c.emit(code.OpGetCell, node.Slot)
+		default:
+			c.emit(code.OpGetLocal, node.Slot)
+		}
+		return
+	}
+
+	symbol, ok := c.symbolTable.Resolve(node.Value)
+	if !ok {
+		if index, ok := builtinIndex[node.Value]; ok {
+			c.emit(code.OpGetBuiltin, index)
+			return
+		}
+		if constant, ok := evaluator.LookupConstant(node.Value); ok {
+			c.emit(code.OpConstant, c.addConstant(constant))
+			return
+		}
+		symbol = c.symbolTable.Define(node.Value)
+	}
+	c.emit(code.OpGetGlobal, symbol.Index)
+}
+
+// storeIdentifier pops the value of a let into the variable it binds
+func (c *Compiler) storeIdentifier(node *ast.Identifier) {
+	if !node.Local {
+		c.emit(code.OpSetGlobal, c.symbolTable.Define(node.Value).Index)
+		return
+	}
+	scope := &c.scopes[c.scopeIndex]
+	scope.localNames[node.Slot] = node.Value
+	if scope.cells[node.Slot] {
+		c.emit(code.OpSetCell, node.Slot)
+	} else {
+		c.emit(code.OpSetLocal, node.Slot)
+	}
+}
+
+// freeVariable returns the index of a local of an enclosing function in the free variables of the current one
+func (c *Compiler) freeVariable(depth, slot int, name string) int {
+	scope := &c.scopes[c.scopeIndex]
+	v := freeVariable{depth: depth, slot: slot}
+	if index, ok := scope.freeIndex[v]; ok {
+		return index
+	}
+	scope.freeIndex[v] = len(scope.free)
+	scope.free = append(scope.free, v)
+	scope.freeNames = append(scope.freeNames, name)
+	return scope.freeIndex[v]
+}
+
+// capturedSlots returns the slots of fn read by the functions nested in it
+func capturedSlots(fn *ast.FunctionExpression) map[int]bool {
+	captured := make(map[int]bool)
+	var walk func(body *ast.BlockStatement, depth int)
+	walk = func(body *ast.BlockStatement, depth int) {
+		ast.Inspect(body, func(node ast.Node) bool {
+			switch node := node.(type) {
+			case *ast.FunctionExpression:
+				walk(node.Body, depth+1)
+				return false
+			case *ast.Identifier:
+				if node.Local && depth > 0 && node.Depth == depth {
+					captured[node.Slot] = true
+				}
+			}
+			return true
+		})
+	}
+	walk(fn.Body, 0)
+	return captured
+}
+
+func (c *Compiler) compileIfExpression(node *ast.IfElseExpression) error {
+	if err := c.Compile(node.Condition); err != nil {
+		return err
+	}
+
+	// The jump targets are patched once the branches are compiled
+	jumpNotTruthyPos := c.emit(code.OpJumpNotTruthy, 9999)
+	if err := c.compileBranch(node.Consequence); err != nil {
+		return err
+	}
+	jumpPos := c.emit(code.OpJump, 9999)
+
+	c.changeOperand(jumpNotTruthyPos, len(c.currentInstructions()))
+	if node.Alternative == nil {
+		c.emit(code.OpNull)
+	} else if err := c.compileBranch(node.Alternative); err != nil {
+		return err
+	}
+	c.changeOperand(jumpPos, len(c.currentInstructions()))
+	return nil
+}
+
+// compileBranch compiles a block of an if expression, leaving the value of the block on the stack
+func (c *Compiler) compileBranch(block *ast.BlockStatement) error {
+	if err := c.Compile(block); err != nil {
+		return err
+	}
+	if c.lastInstructionIs(code.OpPop) {
+		c.removeLastPop()
+	} else {
+		c.emit(code.OpNull)
+	}
+	return nil
+}
+
+// compileFunction compiles a function into a constant and emits the creation of a closure of it.
+// name is the variable the function is bound to, it names the function in errors.
+// Closures share the locals of enclosing functions they read through cells: the vm puts the slots in cells when
+// the function is called, and the closure gets the cells when it is created, so it sees later lets of them.
+func (c *Compiler) compileFunction(node *ast.FunctionExpression, name string) error {
+	if node.NumLocals > 256 {
+		return fmt.Errorf("too many local variables in function, got=%d, max=256", node.NumLocals)
+	}
+	cells := capturedSlots(node)
+	c.enterScope(cells, node.NumLocals)
+	params := make([]string, len(node.Parameters))
+	for i, p := range node.Parameters {
+		c.scopes[c.scopeIndex].localNames[i] = p.Value
+		params[i] = p.Value
+	}
+
+	if err := c.Compile(node.Body); err != nil {
+		return err
+	}
+	if c.lastInstructionIs(code.OpPop) {
+		c.replaceLastPopWithReturn()
+	}
+	if !c.lastInstructionIs(code.OpReturnValue) {
+		c.emit(code.OpReturn)
+	}
+
+	scope := c.scopes[c.scopeIndex]
+	instructions := c.leaveScope()
+
+	// The cells of the free variables are one function closer to the enclosing function
+	for i, v := range scope.free {
+		if v.depth == 1 {
+			c.emit(code.OpCaptureLocal, v.slot)
+		} else {
+			c.emit(code.OpCaptureFree, c.freeVariable(v.depth-1, v.slot, scope.freeNames[i]))
+		}
+	}
+
+	cellSlots := make([]int, 0, len(cells))
+	for slot := range cells {
+		cellSlots = append(cellSlots, slot)
+	}
+	sort.Ints(cellSlots)
+
+	fn := &object.CompiledFunction{
+		Instructions:  instructions,
+		Positions:     scope.positions,
+		NumLocals:     node.NumLocals,
+		NumParameters: len(node.Parameters),
+		Name:          name,
+		Parameters:    params,
+		Cells:         cellSlots,
+		LocalNames:    scope.localNames,
+		FreeNames:     scope.freeNames,
+	}
+	c.emit(code.OpClosure, c.addConstant(fn), len(scope.free))
+	return nil
+}
+
+// compileQuote turns the quoted node into a constant. The arguments of the unquote calls inside it are compiled,
+// and the calls replaced by placeholders the vm fills with their values, unquote(0) for the first one and so on.
+func (c *Compiler) compileQuote(node ast.Node) error {
+	var unquoted []ast.Expression
+	var err error
+	quoted := ast.Modify(node, func(node ast.Node) ast.Node {
+		call, ok := node.(*ast.CallExpression)
+		if !ok {
+			return node
+		}
+		iden, ok := call.Function.(*ast.Identifier)
+		if !ok || iden.Value != "unquote" {
+			return node
+		}
+		if len(call.Argument) != 1 {
+			err = fmt.Errorf("wrong number of arguments to `unquote`. got=%d, want=1", len(call.Argument))
+			return node
+		}
+
+		placeholder := &ast.IntegerLiteral{
+			Token: token.Token{Type: token.INT, Literal: fmt.Sprint(len(unquoted))},
+			Value: int64(len(unquoted)),
+		}
+		unquoted = append(unquoted, call.Argument[0])
+		return &ast.CallExpression{Token: call.Token, Function: iden, Argument: []ast.Expression{placeholder}}
+	})
+
+	if err != nil {
+		return err
+	}
+	for _, arg := range unquoted {
+		if err := c.Compile(arg); err != nil {
+			return err
+		}
+	}
+	if len(unquoted) > 255 {
+		return fmt.Errorf("too many unquote calls in quote, got=%d, max=255", len(unquoted))
+	}
+	c.emit(code.OpQuote, c.addConstant(&object.Quote{Node: quoted}), len(unquoted))
+	return nil
+}
+
+func (c *Compiler) addConstant(obj object.Object) int {
+	c.constants = append(c.constants, obj)
+	return len(c.constants) - 1
+}
+
+// emit adds an instruction to the current scope and returns its position
+func (c *Compiler) emit(op code.Opcode, operands ...int) int {
+	c.checkOperands(op, operands...)
+	ins := code.Make(op, operands...)
+	pos := c.addInstruction(ins)
+
+	scope := &c.scopes[c.scopeIndex]
+	scope.previousInstruction = scope.lastInstruction
+	scope.lastInstruction = EmittedInstruction{Opcode: op, Position: pos}
+	return pos
+}
+
+func (c *Compiler) addInstruction(ins []byte) int {
+	pos := len(c.currentInstructions())
+	c.scopes[c.scopeIndex].instructions = append(c.currentInstructions(), ins...)
+	return pos
+}
+
+// addPosition records that the next instruction comes from pos in the source
+func (c *Compiler) addPosition(pos token.Position) {
+	scope := &c.scopes[c.scopeIndex]
+	offset := len(scope.instructions)
+	if n := len(scope.positions); n > 0 {
+		last := &scope.positions[n-1]
+		if last.Offset == offset {
+			last.Pos = pos
+			return
+		}
+		if last.Pos == pos {
+			return
+		}
+	}
+	scope.positions = append(scope.positions, code.PositionEntry{Offset: offset, Pos: pos})
+}
+
+func (c *Compiler) currentInstructions() code.Instructions {
+	return c.scopes[c.scopeIndex].instructions
+}
+
+func (c *Compiler) lastInstructionIs(op code.Opcode) bool {
+	if len(c.currentInstructions()) == 0 {
+		return false
+	}
+	return c.scopes[c.scopeIndex].lastInstruction.Opcode == op
+}
+
+func (c *Compiler) removeLastPop() {
+	scope := &c.scopes[c.scopeIndex]
+	scope.instructions = scope.instructions[:scope.lastInstruction.Position]
+	scope.lastInstruction = scope.previousInstruction
+}
+
+func (c *Compiler) replaceLastPopWithReturn() {
+	lastPos := c.scopes[c.scopeIndex].lastInstruction.Position
+	c.replaceInstruction(lastPos, code.Make(code.OpReturnValue))
+	c.scopes[c.scopeIndex].lastInstruction.Opcode = code.OpReturnValue
+}
+
+func (c *Compiler) replaceInstruction(pos int, newInstruction []byte) {
+	ins := c.currentInstructions()
+	copy(ins[pos:], newInstruction)
+}
+
+func (c *Compiler) changeOperand(opPos int, operand int) {
+	op := code.Opcode(c.currentInstructions()[opPos])
+	c.checkOperands(op, operand)
+	c.replaceInstruction(opPos, code.Make(op, operand))
+}
+
+// checkOperands records the first operand too large for its instruction, like the index of a constant or the
+// target of a jump in a program larger than 64 KiB
+func (c *Compiler) checkOperands(op code.Opcode, operands ...int) {
+	if err := code.CheckOperands(op, operands...); err != nil && c.err == nil {
+		c.err = fmt.Errorf("program too large: %w", err)
+	}
+}
+
+func (c *Compiler) enterScope(cells map[int]bool, numLocals int) {
+	c.scopes = append(c.scopes, CompilationScope{
+		cells:      cells,
+		localNames: make([]string, numLocals),
+		freeIndex:  make(map[freeVariable]int),
+		freeNames:  []string{},
+	})
+	c.scopeIndex++
+}
+
+func (c *Compiler) leaveScope() code.Instructions {
+	instructions := c.currentInstructions()
+	c.scopes = c.scopes[:len(c.scopes)-1]
+	c.scopeIndex--
+	return instructions
+}
@@ -0,0 +1,112 @@
+package compiler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ShivankSharma070/go-interpreter/code"
+	"github.com/ShivankSharma070/go-interpreter/evaluator"
+	"github.com/ShivankSharma070/go-interpreter/object"
+)
+
+// Disassemble returns a human readable listing of the program: its globals, its constant pool, the top level code
+// and the code of every function. Instructions are annotated with the source position they start and with what
+// their operands refer to.
+func (b *Bytecode) Disassemble() string {
+	var out strings.Builder
+
+	fmt.Fprintf(&out, "globals: %s\n", strings.Join(b.GlobalNames, ", "))
+
+	out.WriteString("constants:\n")
+	for i, constant := range b.Constants {
+		fmt.Fprintf(&out, "  %04d %s %s\n", i, constant.Type(), describeConstant(constant))
+	}
+
+	out.WriteString("main:\n")
+	b.disassembleInstructions(&out, b.Instructions, b.Positions, nil)
+
+	for i, constant := range b.Constants {
+		fn, ok := constant.(*object.CompiledFunction)
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(&out, "%s [constant %d, %d locals]:\n", describeConstant(fn), i, fn.NumLocals)
+		b.disassembleInstructions(&out, fn.Instructions, fn.Positions, fn)
+	}
+	return out.String()
+}
+
+// disassembleInstructions lists the instructions of the top level code or of fn
+func (b *Bytecode) disassembleInstructions(out *strings.Builder, ins code.Instructions, positions code.Positions, fn *object.CompiledFunction) {
+	next := 0 // Next entry of the position table
+	for i := 0; i < len(ins); {
+		def, err := code.Lookup(ins[i])
+		if err != nil {
+			fmt.Fprintf(out, "  %04d ERROR: %s\n", i, err)
+			i++
+			continue
+		}
+		operands, read := code.ReadOperands(def, ins[i+1:])
+
+		pos := ""
+		for next < len(positions) && positions[next].Offset <= i {
+			pos = positions[next].Pos.String()
+			next++
+		}
+
+		line := def.Name
+		for _, operand := range operands {
+			line += " " + strconv.Itoa(operand)
+		}
+		if comment := b.describeOperands(code.Opcode(ins[i]), operands, fn); comment != "" {
+			line = fmt.Sprintf("%-24s ; %s", line, comment)
+		}
+		fmt.Fprintf(out, "  %04d %7s  %s\n", i, pos, line)
+		i += 1 + read
+	}
+}
+
+// describeOperands tells what the operands of an instruction refer to, names of variables, values of constants...
+func (b *Bytecode) describeOperands(op code.Opcode, operands []int, fn *object.CompiledFunction) string {
+	switch op {
+	case code.OpConstant, code.OpMember, code.OpClosure, code.OpQuote:
+		if operands[0] < len(b.Constants) {
+			return describeConstant(b.Constants[operands[0]])
+		}
+	case code.OpGetGlobal, code.OpSetGlobal:
+		if operands[0] < len(b.GlobalNames) {
+			return b.GlobalNames[operands[0]]
+		}
+	case code.OpGetBuiltin:
+		if names := evaluator.BuiltinNames(); operands[0] < len(names) {
+			return names[operands[0]]
+		}
+	case code.OpGetLocal, code.OpSetLocal, code.OpGetCell, code.OpSetCell, code.OpCaptureLocal:
+		if fn != nil && operands[0] < len(fn.LocalNames) {
+			return fn.LocalNames[operands[0]]
+		}
+	case code.OpGetFree, code.OpCaptureFree:
+		if fn != nil && operands[0] < len(fn.FreeNames) {
+			return fn.FreeNames[operands[0]]
+		}
+	}
+	return ""
+}
+
+func describeConstant(constant object.Object) string {
+	switch constant := constant.(type) {
+	case *object.String:
+		return strconv.Quote(constant.Value)
+	case *object.CompiledFunction:
+		name := constant.Name
+		if name == "" {
+			name = "<anonymous>"
+		}
+		return fmt.Sprintf("fn %s(%s)", name, strings.Join(constant.Parameters, ", "))
+	case *object.Quote:
+		return constant.Node.String()
+	default:
+		return constant.Inspect()
+	}
+}
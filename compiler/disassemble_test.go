@@ -0,0 +1,35 @@
+package compiler
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestDisassemble(t *testing.T) {
+	bytecode := compileTestProgram(t, "let add = fn(a, b) { a + b };\nputs(add(1, 2));")
+
+	expected := `globals: add
+constants:
+  0000 COMPILED_FUNCTION fn add(a, b)
+  0001 INTEGER 1
+  0002 INTEGER 2
+main:
+  0000     1:1  OpClosure 0 0            ; fn add(a, b)
+  0004          OpSetGlobal 0            ; add
+  0007     2:1  OpGetBuiltin ` + strconv.Itoa(builtinIndex["puts"]) + `          ; puts
+  0010          OpGetGlobal 0            ; add
+  0013          OpConstant 1             ; 1
+  0016          OpConstant 2             ; 2
+  0019     2:9  OpCall 2
+  0021     2:5  OpCall 1
+  0023          OpReturnValue
+fn add(a, b) [constant 0, 2 locals]:
+  0000    1:22  OpGetLocal 1             ; b
+  0002          OpGetLocal 0             ; a
+  0004          OpAdd
+  0005          OpReturnValue
+`
+	if got := bytecode.Disassemble(); got != expected {
+		t.Errorf("wrong disassembly.\nwant=\n%s\ngot=\n%s", expected, got)
+	}
+}
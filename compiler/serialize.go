@@ -0,0 +1,374 @@
+package compiler
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/ShivankSharma070/go-interpreter/code"
+	"github.com/ShivankSharma070/go-interpreter/evaluator"
+	"github.com/ShivankSharma070/go-interpreter/object"
+	"github.com/ShivankSharma070/go-interpreter/token"
+)
+
+// A compiled program on disk is laid out as follows, every number is big endian:
+//
+//	magic        4 bytes, "MNKB"
+//	version      uint16
+//	builtins     uint16 count, then the name of every builtin the program was compiled against
+//	globals      uint16 count, then the name of every global
+//	constants    uint32 count, then every constant as a one byte tag followed by its value
+//	instructions the top level code, uint32 length then the bytes
+//	positions    the debug line table of the top level code, uint32 count then offset, line and column as uint32
+//
+// Strings are a uint32 length followed by their bytes. Functions store their name, parameters, number of locals,
+// the names of the locals, the uint16 count and slots of the locals held in cells, the uint16 count and names of
+// their free variables, their instructions and line table. Builtins are stored by name, so a program keeps working when builtins are added.
+const (
+	BytecodeMagic   = "MNKB"
+	BytecodeVersion = 2
+)
+
+// Tags of the constants
+const (
+	tagInteger byte = iota + 1
+	tagFloat
+	tagString
+	tagFunction
+)
+
+var ErrNotBytecode = errors.New("not a compiled monkey program")
+
+// IsBytecode reports whether data starts like a compiled program
+func IsBytecode(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(BytecodeMagic))
+}
+
+// MarshalBinary encodes the program in the format described above
+func (b *Bytecode) MarshalBinary() ([]byte, error) {
+	e := &encoder{}
+	e.buf.WriteString(BytecodeMagic)
+	e.uint16(BytecodeVersion)
+
+	builtins := evaluator.BuiltinNames()
+	e.uint16(len(builtins))
+	for _, name := range builtins {
+		e.string(name)
+	}
+
+	e.uint16(len(b.GlobalNames))
+	for _, name := range b.GlobalNames {
+		e.string(name)
+	}
+
+	e.uint32(len(b.Constants))
+	for i, constant := range b.Constants {
+		if err := e.constant(constant); err != nil {
+			return nil, fmt.Errorf("constant %d: %w", i, err)
+		}
+	}
+
+	e.bytes(b.Instructions)
+	e.positions(b.Positions)
+	if e.err != nil {
+		return nil, e.err
+	}
+	return e.buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes a program encoded by MarshalBinary
+func (b *Bytecode) UnmarshalBinary(data []byte) error {
+	if !IsBytecode(data) {
+		return ErrNotBytecode
+	}
+	d := &decoder{data: data[len(BytecodeMagic):]}
+	if version := d.uint16(); d.err == nil && version != BytecodeVersion {
+		return fmt.Errorf("unsupported bytecode version %d, want %d", version, BytecodeVersion)
+	}
+
+	builtins := make([]string, d.uint16())
+	for i := range builtins {
+		builtins[i] = d.string()
+	}
+
+	globals := make([]string, d.uint16())
+	for i := range globals {
+		globals[i] = d.string()
+	}
+
+	var constants []object.Object
+	for n := d.uint32(); n > 0 && d.err == nil; n-- {
+		constants = append(constants, d.constant())
+	}
+
+	instructions := code.Instructions(d.bytes())
+	positions := d.positions()
+	if d.err != nil {
+		return fmt.Errorf("corrupted bytecode: %w", d.err)
+	}
+	if len(d.data) != 0 {
+		return fmt.Errorf("corrupted bytecode: %d trailing bytes", len(d.data))
+	}
+
+	decoded := &Bytecode{Instructions: instructions, Positions: positions, Constants: constants, GlobalNames: globals}
+	if err := decoded.link(builtins); err != nil {
+		return err
+	}
+	*b = *decoded
+	return nil
+}
+
+// link checks that the operands of every instruction of a decoded program refer to constants, globals and builtins
+// that exist, and rewrites the builtin operands of a program compiled against another list of builtins.
+func (b *Bytecode) link(compiledWith []string) error {
+	if err := b.linkInstructions(b.Instructions, nil, compiledWith); err != nil {
+		return err
+	}
+	for i, constant := range b.Constants {
+		if fn, ok := constant.(*object.CompiledFunction); ok {
+			if slices.ContainsFunc(fn.Cells, func(slot int) bool { return slot >= fn.NumLocals }) {
+				return fmt.Errorf("function %d: corrupted bytecode: cells out of range", i)
+			}
+			if err := b.linkInstructions(fn.Instructions, fn, compiledWith); err != nil {
+				return fmt.Errorf("function %d: %w", i, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (b *Bytecode) linkInstructions(ins code.Instructions, fn *object.CompiledFunction, compiledWith []string) error {
+	var last code.Opcode
+	for i := 0; i < len(ins); {
+		op := code.Opcode(ins[i])
+		def, err := code.Lookup(ins[i])
+		if err != nil {
+			return fmt.Errorf("corrupted bytecode: %w", err)
+		}
+		width := 0
+		for _, w := range def.OperandWidths {
+			width += w
+		}
+		if i+1+width > len(ins) {
+			return fmt.Errorf("corrupted bytecode: truncated %s at %d", def.Name, i)
+		}
+		operands, read := code.ReadOperands(def, ins[i+1:])
+
+		inRange := true
+		switch op {
+		case code.OpConstant, code.OpQuote:
+			inRange = operands[0] < len(b.Constants)
+		case code.OpMember:
+			inRange = operands[0] < len(b.Constants) && b.Constants[operands[0]].Type() == object.STRING_OBJ
+		case code.OpClosure:
+			inRange = operands[0] < len(b.Constants) && b.Constants[operands[0]].Type() == object.COMPILED_FUNCTION_OBJ
+		case code.OpGetGlobal, code.OpSetGlobal:
+			inRange = operands[0] < len(b.GlobalNames)
+		case code.OpGetLocal, code.OpSetLocal:
+			inRange = fn != nil && operands[0] < fn.NumLocals
+		case code.OpGetCell, code.OpSetCell, code.OpCaptureLocal:
+			inRange = fn != nil && slices.Contains(fn.Cells, operands[0])
+		case code.OpGetFree, code.OpCaptureFree:
+			inRange = fn != nil && operands[0] < len(fn.FreeNames)
+		case code.OpJump, code.OpJumpNotTruthy:
+			inRange = operands[0] < len(ins)
+		case code.OpGetBuiltin:
+			inRange = operands[0] < len(compiledWith)
+			if inRange {
+				index, ok := builtinIndex[compiledWith[operands[0]]]
+				if !ok {
+					return fmt.Errorf("program uses builtin %s, which does not exist anymore", compiledWith[operands[0]])
+				}
+				copy(ins[i:], code.Make(code.OpGetBuiltin, index))
+			}
+		}
+		if !inRange {
+			return fmt.Errorf("corrupted bytecode: operand of %s at %d out of range", def.Name, i)
+		}
+		i += 1 + read
+		last = op
+	}
+	if last != code.OpReturn && last != code.OpReturnValue {
+		return fmt.Errorf("corrupted bytecode: instructions do not end with a return")
+	}
+	return nil
+}
+
+// encoder writes the parts of a program one after the other, err is set by the first count too large for its field
+type encoder struct {
+	buf bytes.Buffer
+	err error
+}
+
+func (e *encoder) uint8(v int) { e.buf.WriteByte(byte(v)) }
+
+func (e *encoder) uint16(v int) {
+	if (v < 0 || v > math.MaxUint16) && e.err == nil {
+		e.err = fmt.Errorf("%d does not fit in 16 bits", v)
+	}
+	e.buf.Write(binary.BigEndian.AppendUint16(nil, uint16(v)))
+}
+
+func (e *encoder) uint32(v int) { e.buf.Write(binary.BigEndian.AppendUint32(nil, uint32(v))) }
+
+func (e *encoder) uint64(v uint64) { e.buf.Write(binary.BigEndian.AppendUint64(nil, v)) }
+
+func (e *encoder) bytes(v []byte) {
+	e.uint32(len(v))
+	e.buf.Write(v)
+}
+
+func (e *encoder) string(v string) { e.bytes([]byte(v)) }
+
+func (e *encoder) positions(positions code.Positions) {
+	e.uint32(len(positions))
+	for _, entry := range positions {
+		e.uint32(entry.Offset)
+		e.uint32(entry.Pos.Line)
+		e.uint32(entry.Pos.Column)
+	}
+}
+
+func (e *encoder) constant(constant object.Object) error {
+	switch constant := constant.(type) {
+	case *object.Integer:
+		e.uint8(int(tagInteger))
+		e.uint64(uint64(constant.Value))
+	case *object.Float:
+		e.uint8(int(tagFloat))
+		e.uint64(math.Float64bits(constant.Value))
+	case *object.String:
+		e.uint8(int(tagString))
+		e.string(constant.Value)
+	case *object.CompiledFunction:
+		e.uint8(int(tagFunction))
+		e.string(constant.Name)
+		e.uint16(len(constant.Parameters))
+		for _, param := range constant.Parameters {
+			e.string(param)
+		}
+		e.uint16(constant.NumLocals)
+		for _, name := range constant.LocalNames {
+			e.string(name)
+		}
+		e.uint16(len(constant.Cells))
+		for _, slot := range constant.Cells {
+			e.uint16(slot)
+		}
+		e.uint16(len(constant.FreeNames))
+		for _, name := range constant.FreeNames {
+			e.string(name)
+		}
+		e.bytes(constant.Instructions)
+		e.positions(constant.Positions)
+	default:
+		// Quoted code has no binary form
+		return fmt.Errorf("cannot serialize constant of type %s", constant.Type())
+	}
+	return nil
+}
+
+// decoder reads the parts of a program one after the other, the first error stops it
+type decoder struct {
+	data []byte
+	err  error
+}
+
+func (d *decoder) next(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if len(d.data) < n {
+		d.err = errors.New("unexpected end of data")
+		return nil
+	}
+	v := d.data[:n]
+	d.data = d.data[n:]
+	return v
+}
+
+func (d *decoder) uint8() int {
+	if v := d.next(1); v != nil {
+		return int(v[0])
+	}
+	return 0
+}
+
+func (d *decoder) uint16() int {
+	if v := d.next(2); v != nil {
+		return int(binary.BigEndian.Uint16(v))
+	}
+	return 0
+}
+
+func (d *decoder) uint32() int {
+	if v := d.next(4); v != nil {
+		return int(binary.BigEndian.Uint32(v))
+	}
+	return 0
+}
+
+func (d *decoder) uint64() uint64 {
+	if v := d.next(8); v != nil {
+		return binary.BigEndian.Uint64(v)
+	}
+	return 0
+}
+
+func (d *decoder) bytes() []byte {
+	v := d.next(d.uint32())
+	return append([]byte{}, v...)
+}
+
+func (d *decoder) string() string { return string(d.next(d.uint32())) }
+
+func (d *decoder) positions() code.Positions {
+	var positions code.Positions
+	for n := d.uint32(); n > 0 && d.err == nil; n-- {
+		offset, line, column := d.uint32(), d.uint32(), d.uint32()
+		positions = append(positions, code.PositionEntry{Offset: offset, Pos: token.Position{Line: line, Column: column}})
+	}
+	return positions
+}
+
+func (d *decoder) constant() object.Object {
+	switch tag := byte(d.uint8()); tag {
+	case tagInteger:
+		return &object.Integer{Value: int64(d.uint64())}
+	case tagFloat:
+		return &object.Float{Value: math.Float64frombits(d.uint64())}
+	case tagString:
+		return &object.String{Value: d.string()}
+	case tagFunction:
+		fn := &object.CompiledFunction{Name: d.string()}
+		fn.Parameters = make([]string, d.uint16())
+		for i := range fn.Parameters {
+			fn.Parameters[i] = d.string()
+		}
+		fn.NumParameters = len(fn.Parameters)
+		fn.NumLocals = d.uint16()
+		fn.LocalNames = make([]string, fn.NumLocals)
+		for i := range fn.LocalNames {
+			fn.LocalNames[i] = d.string()
+		}
+		fn.Cells = make([]int, d.uint16())
+		for i := range fn.Cells {
+			fn.Cells[i] = d.uint16()
+		}
+		fn.FreeNames = make([]string, d.uint16())
+		for i := range fn.FreeNames {
+			fn.FreeNames[i] = d.string()
+		}
+		fn.Instructions = d.bytes()
+		fn.Positions = d.positions()
+		return fn
+	default:
+		if d.err == nil {
+			d.err = fmt.Errorf("unknown constant tag %d", tag)
+		}
+		return nil
+	}
+}
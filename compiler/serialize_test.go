@@ -0,0 +1,137 @@
+package compiler
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ShivankSharma070/go-interpreter/code"
+	"github.com/ShivankSharma070/go-interpreter/evaluator"
+)
+
+func compileTestProgram(t *testing.T, input string) *Bytecode {
+	t.Helper()
+	c := New()
+	if err := c.Compile(parse(input)); err != nil {
+		t.Fatalf("compiler error: %s", err)
+	}
+	return c.Bytecode()
+}
+
+func TestBytecodeRoundTrip(t *testing.T) {
+	bytecode := compileTestProgram(t, `
+let greet = fn(name, punctuation) { "hello " + name + punctuation };
+let fib = fn(n) { if (n < 2) { n } else { fib(n - 1) + fib(n - 2) } };
+let adder = fn(a) { fn(b) { a + b } };
+puts(greet("monkey", "!"), fib(-3), 2.5 * PI, adder(1)(2), later);`)
+
+	data, err := bytecode.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary failed: %s", err)
+	}
+	if !IsBytecode(data) {
+		t.Fatalf("encoded program does not start with the magic")
+	}
+
+	decoded := &Bytecode{}
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary failed: %s", err)
+	}
+	if !reflect.DeepEqual(decoded, bytecode) {
+		t.Errorf("decoded program differs.\nwant=%s\ngot =%s", bytecode.Disassemble(), decoded.Disassemble())
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	data, err := compileTestProgram(t, `let f = fn(x) { x + 1 }; f(len("ab"))`).MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary failed: %s", err)
+	}
+
+	wrongVersion := slices.Clone(data)
+	wrongVersion[len(BytecodeMagic)+1] = 99
+
+	tests := []struct {
+		name     string
+		data     []byte
+		expected string
+	}{
+		{"source code", []byte("let a = 1;"), "not a compiled monkey program"},
+		{"version", wrongVersion, "unsupported bytecode version 99, want 2"},
+		{"truncated", data[:len(data)-3], "corrupted bytecode: unexpected end of data"},
+		{"trailing", append(slices.Clone(data), 0), "corrupted bytecode: 1 trailing bytes"},
+	}
+
+	for _, tt := range tests {
+		err := (&Bytecode{}).UnmarshalBinary(tt.data)
+		if err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+			continue
+		}
+		if err.Error() != tt.expected {
+			t.Errorf("%s: wrong error. want=%q, got=%q", tt.name, tt.expected, err)
+		}
+	}
+}
+
+func TestMarshalQuoteFails(t *testing.T) {
+	_, err := compileTestProgram(t, `quote(1 + 2)`).MarshalBinary()
+	if err == nil || err.Error() != "constant 0: cannot serialize constant of type QUOTE" {
+		t.Errorf("wrong error, got %v", err)
+	}
+}
+
+func TestLinkChecksOperands(t *testing.T) {
+	builtins := evaluator.BuiltinNames()
+	tests := []struct {
+		bytecode *Bytecode
+		expected string
+	}{
+		{
+			&Bytecode{Instructions: concatInstructions([]code.Instructions{code.Make(code.OpConstant, 3), code.Make(code.OpReturnValue)})},
+			"corrupted bytecode: operand of OpConstant at 0 out of range",
+		},
+		{
+			&Bytecode{Instructions: concatInstructions([]code.Instructions{code.Make(code.OpGetLocal, 0), code.Make(code.OpReturnValue)})},
+			"corrupted bytecode: operand of OpGetLocal at 0 out of range",
+		},
+		{
+			&Bytecode{Instructions: code.Make(code.OpTrue)},
+			"corrupted bytecode: instructions do not end with a return",
+		},
+		{
+			&Bytecode{Instructions: code.Make(code.OpConstant, 1)[:2]},
+			"corrupted bytecode: truncated OpConstant at 0",
+		},
+	}
+
+	for _, tt := range tests {
+		err := tt.bytecode.link(builtins)
+		if err == nil || err.Error() != tt.expected {
+			t.Errorf("wrong error. want=%q, got=%v", tt.expected, err)
+		}
+	}
+}
+
+func TestLinkRemapsBuiltins(t *testing.T) {
+	// The program was compiled when len was the only builtin besides one that was removed since
+	compiledWith := []string{"removed", "len"}
+	bytecode := &Bytecode{Instructions: concatInstructions([]code.Instructions{
+		code.Make(code.OpGetBuiltin, 1),
+		code.Make(code.OpReturnValue),
+	})}
+
+	if err := bytecode.link(compiledWith); err != nil {
+		t.Fatalf("link failed: %s", err)
+	}
+	if got := code.ReadUint16(bytecode.Instructions[1:]); int(got) != builtinIndex["len"] {
+		t.Errorf("len was not relinked, operand is %d", got)
+	}
+
+	bytecode.Instructions = concatInstructions([]code.Instructions{code.Make(code.OpGetBuiltin, 0), code.Make(code.OpReturnValue)})
+	err := bytecode.link(compiledWith)
+	if err == nil || !strings.Contains(err.Error(), "builtin removed, which does not exist anymore") {
+		t.Errorf("missing builtin was not reported, got %v", err)
+	}
+}
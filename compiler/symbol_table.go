@@ -0,0 +1,46 @@
+package compiler
+
+// Symbol is a global variable, Index is its slot in the globals of the program
+type Symbol struct {
+	Name  string
+	Index int
+}
+
+// SymbolTable holds the global variables of a program. Locals are not in it, the resolver of the evaluator gives
+// every identifier naming one its function and slot, see evaluator.Resolve.
+type SymbolTable struct {
+	store map[string]Symbol
+	names []string
+}
+
+func NewSymbolTable() *SymbolTable {
+	return &SymbolTable{store: make(map[string]Symbol)}
+}
+
+// Define binds name. Defining a name again reuses its slot, the same way a let statement in the evaluator replaces
+// the previous value in the environment.
+func (s *SymbolTable) Define(name string) Symbol {
+	if symbol, ok := s.store[name]; ok {
+		return symbol
+	}
+
+	symbol := Symbol{Name: name, Index: len(s.names)}
+	s.store[name] = symbol
+	s.names = append(s.names, name)
+	return symbol
+}
+
+func (s *SymbolTable) Resolve(name string) (Symbol, bool) {
+	symbol, ok := s.store[name]
+	return symbol, ok
+}
+
+// NumDefinitions is the number of slots needed by the variables defined so far
+func (s *SymbolTable) NumDefinitions() int {
+	return len(s.names)
+}
+
+// Names returns the names of the variables defined so far, indexed by their slot
+func (s *SymbolTable) Names() []string {
+	return s.names
+}
@@ -0,0 +1,49 @@
+package compiler
+
+import "testing"
+
+func TestDefineAndResolve(t *testing.T) {
+	global := NewSymbolTable()
+	global.Define("a")
+	global.Define("b")
+
+	tests := []struct {
+		name     string
+		expected Symbol
+	}{
+		{"a", Symbol{Name: "a", Index: 0}},
+		{"b", Symbol{Name: "b", Index: 1}},
+	}
+
+	for _, tt := range tests {
+		symbol, ok := global.Resolve(tt.name)
+		if !ok {
+			t.Errorf("name %s not resolvable", tt.name)
+			continue
+		}
+		if symbol != tt.expected {
+			t.Errorf("expected %s to resolve to %+v, got=%+v", tt.name, tt.expected, symbol)
+		}
+	}
+
+	if _, ok := global.Resolve("missing"); ok {
+		t.Errorf("missing should not resolve")
+	}
+}
+
+func TestDefineAgainReusesSlot(t *testing.T) {
+	global := NewSymbolTable()
+	first := global.Define("a")
+	global.Define("b")
+	second := global.Define("a")
+
+	if first != second {
+		t.Errorf("defining a again changed its symbol, %+v and %+v", first, second)
+	}
+	if global.NumDefinitions() != 2 {
+		t.Errorf("wrong number of definitions, got %d", global.NumDefinitions())
+	}
+	if names := global.Names(); len(names) != 2 || names[0] != "a" || names[1] != "b" {
+		t.Errorf("wrong names, got %v", names)
+	}
+}
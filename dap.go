@@ -0,0 +1,71 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"os"
+
+	"github.com/ShivankSharma070/go-interpreter/dap"
+)
+
+// dapCommand implements the dap subcommand, a debug adapter talking to an editor over the standard input and output
+// or over TCP connections
+func dapCommand(args []string) int {
+	flags := flag.NewFlagSet("dap", flag.ContinueOnError)
+	listen := flags.String("listen", "", "serve clients connecting to this `address` one after another, like :4711, instead of the standard input and output")
+	flags.Var(&fileAccess, "files", fileAccessUsage)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "usage: %s dap [-listen address] [-files access]\n", os.Args[0])
+		fmt.Fprintln(flags.Output(), "Serves the Debug Adapter Protocol, the launched script runs with the tree walking evaluator.")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 0 {
+		flags.Usage()
+		return 2
+	}
+
+	if *listen == "" {
+		server := dap.NewServer(os.Stdin, os.Stdout)
+		server.Environment = newEnvironment
+		if err := server.Run(); err != nil {
+			fmt.Fprintln(os.Stderr, "dap:", err)
+			return 1
+		}
+		return 0
+	}
+
+	// Scripts can do whatever the user can, so only local clients are served when no host is given
+	host, port, err := net.SplitHostPort(*listen)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "dap:", err)
+		return 2
+	}
+	if host == "" {
+		host = "127.0.0.1"
+	}
+	listener, err := net.Listen("tcp", net.JoinHostPort(host, port))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "dap:", err)
+		return 1
+	}
+	defer listener.Close()
+	fmt.Fprintln(os.Stderr, "dap: listening on", listener.Addr())
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "dap:", err)
+			return 1
+		}
+		server := dap.NewServer(conn, conn)
+		server.Environment = newEnvironment
+		if err := server.Run(); err != nil {
+			fmt.Fprintln(os.Stderr, "dap:", err)
+		}
+		conn.Close()
+	}
+}
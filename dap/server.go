@@ -0,0 +1,542 @@
+// Package dap implements a Debug Adapter Protocol server, editors like VS Code use it to debug monkey scripts. The
+// scripts run with the tree walking evaluator under a debugger.
+package dap
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ShivankSharma070/go-interpreter/debugger"
+	"github.com/ShivankSharma070/go-interpreter/evaluator"
+	"github.com/ShivankSharma070/go-interpreter/lexer"
+	"github.com/ShivankSharma070/go-interpreter/object"
+	"github.com/ShivankSharma070/go-interpreter/parser"
+)
+
+// The program runs in a single thread
+const threadID = 1
+
+var errNotPaused = errors.New("the program is not paused")
+
+// Server serves one client, reading its requests from in and writing to out. It debugs one program, the one the
+// client launches.
+type Server struct {
+	in  *bufio.Reader
+	out io.Writer
+
+	// Environment creates the environment the program at path runs in, object.NewEnvironment when nil.
+	// The output of the program is sent to the client whatever the environment says.
+	Environment func(path string) *object.Environment
+
+	writing sync.Mutex // Responses and the events of the program are written from different goroutines
+	seq     int
+
+	linesStartAt1   bool
+	columnsStartAt1 bool
+
+	path     string
+	debugger *debugger.Debugger
+	env      *object.Environment
+	next     func()        // Work to do once the response to the current request is sent
+	done     chan struct{} // Closed when the program ends, nil until it runs
+
+	mu          sync.Mutex // Guards the state of the program, it runs in a goroutine of its own
+	paused      bool
+	terminating bool
+	resume      chan debugger.Action
+	refs        []any // Values of the variablesReference given to the client while paused, from 1
+}
+
+func NewServer(in io.Reader, out io.Writer) *Server {
+	return &Server{
+		in:              bufio.NewReader(in),
+		out:             out,
+		linesStartAt1:   true,
+		columnsStartAt1: true,
+		resume:          make(chan debugger.Action),
+	}
+}
+
+type handler func(s *Server, args json.RawMessage) (any, error)
+
+// Requests the server understands, the others fail
+var handlers = map[string]handler{
+	"initialize":        (*Server).initialize,
+	"launch":            (*Server).launch,
+	"setBreakpoints":    (*Server).setBreakpoints,
+	"configurationDone": (*Server).configurationDone,
+	"threads":           (*Server).threads,
+	"stackTrace":        (*Server).stackTrace,
+	"scopes":            (*Server).scopes,
+	"variables":         (*Server).variables,
+	"evaluate":          (*Server).evaluate,
+	"continue":          resumeWith(debugger.Continue),
+	"next":              resumeWith(debugger.StepOver),
+	"stepIn":            resumeWith(debugger.StepIn),
+	"stepOut":           resumeWith(debugger.StepOut),
+	"pause":             (*Server).pause,
+	"terminate":         (*Server).terminate,
+	"disconnect":        (*Server).terminate,
+}
+
+// Run serves requests until the client disconnects or its input ends. A program still running then is stopped
+// at its next statement, Run returns once it has ended.
+func (s *Server) Run() error {
+	defer func() {
+		s.stop()
+		if s.done != nil {
+			<-s.done
+		}
+	}()
+	for {
+		body, err := readMessage(s.in)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var req request
+		if err := json.Unmarshal(body, &req); err != nil || req.Type != "request" {
+			// Without a request there is nothing to respond to
+			continue
+		}
+		handle, ok := handlers[req.Command]
+		if !ok {
+			s.respond(&req, nil, fmt.Errorf("unknown command %q", req.Command))
+			continue
+		}
+		result, err := handle(s, req.Arguments)
+		s.respond(&req, result, err)
+
+		if next := s.next; next != nil {
+			s.next = nil
+			next()
+		}
+		if req.Command == "disconnect" {
+			return nil
+		}
+	}
+}
+
+func (s *Server) respond(req *request, body any, err error) {
+	resp := &response{Type: "response", RequestSeq: req.Seq, Command: req.Command, Success: err == nil, Body: body}
+	if err != nil {
+		resp.Message = err.Error()
+	}
+	s.send(func(seq int) any {
+		resp.Seq = seq
+		return resp
+	})
+}
+
+func (s *Server) event(name string, body any) {
+	s.send(func(seq int) any {
+		return &event{Seq: seq, Type: "event", Event: name, Body: body}
+	})
+}
+
+// send writes the message msg makes with the next sequence number, a client that went away is noticed when
+// reading from it
+func (s *Server) send(msg func(seq int) any) {
+	s.writing.Lock()
+	defer s.writing.Unlock()
+	s.seq++
+	writeMessage(s.out, msg(s.seq))
+}
+
+// decode unmarshals the arguments of a request into a value of type T
+func decode[T any](args json.RawMessage) (T, error) {
+	var value T
+	if len(args) == 0 {
+		return value, nil
+	}
+	if err := json.Unmarshal(args, &value); err != nil {
+		return value, fmt.Errorf("invalid arguments: %s", err)
+	}
+	return value, nil
+}
+
+func (s *Server) initialize(args json.RawMessage) (any, error) {
+	a, err := decode[InitializeArguments](args)
+	if err != nil {
+		return nil, err
+	}
+	if a.LinesStartAt1 != nil {
+		s.linesStartAt1 = *a.LinesStartAt1
+	}
+	if a.ColumnsStartAt1 != nil {
+		s.columnsStartAt1 = *a.ColumnsStartAt1
+	}
+	return map[string]any{
+		"supportsConfigurationDoneRequest": true,
+		"supportsEvaluateForHovers":        true,
+		"supportsTerminateRequest":         true,
+	}, nil
+}
+
+// launch loads the program, it starts once the client is done setting breakpoints
+func (s *Server) launch(args json.RawMessage) (any, error) {
+	a, err := decode[LaunchArguments](args)
+	if err != nil {
+		return nil, err
+	}
+	if s.debugger != nil {
+		return nil, errors.New("a program was already launched")
+	}
+	if a.Program == "" {
+		return nil, errors.New("launch needs the path of a program")
+	}
+	source, err := os.ReadFile(a.Program)
+	if err != nil {
+		return nil, err
+	}
+
+	p := parser.New(lexer.New(string(source)))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		return nil, errors.New(strings.Join(p.Errors(), "\n"))
+	}
+	env := object.NewEnvironment()
+	if s.Environment != nil {
+		env = s.Environment(a.Program)
+	}
+	env.Runtime.Stdout = &output{s, "stdout"}
+	env.Runtime.Stderr = &output{s, "stderr"}
+	expanded, errObj := evaluator.ExpandProgram(program, env)
+	if errObj != nil {
+		return nil, errors.New(errObj.Message)
+	}
+
+	s.path, s.env = a.Program, env
+	s.debugger = debugger.New(expanded, s.wait, a.StopOnEntry)
+	s.next = func() { s.event("initialized", nil) }
+	return nil, nil
+}
+
+// output sends what the program writes to the client
+type output struct {
+	s        *Server
+	category string
+}
+
+func (o *output) Write(p []byte) (int, error) {
+	o.s.event("output", OutputEventBody{Category: o.category, Output: string(p)})
+	return len(p), nil
+}
+
+func (s *Server) setBreakpoints(args json.RawMessage) (any, error) {
+	a, err := decode[SetBreakpointsArguments](args)
+	if err != nil {
+		return nil, err
+	}
+	if s.debugger == nil {
+		return nil, errors.New("no program was launched")
+	}
+
+	breakpoints := []Breakpoint{}
+	if !samePath(a.Source.Path, s.path) {
+		for range a.Breakpoints {
+			breakpoints = append(breakpoints, Breakpoint{Message: "not the launched program", Source: a.Source})
+		}
+		return map[string]any{"breakpoints": breakpoints}, nil
+	}
+
+	s.debugger.ClearBreakpoints()
+	for _, requested := range a.Breakpoints {
+		breakpoint := Breakpoint{Source: a.Source}
+		if line, ok := s.debugger.SetBreakpoint(s.fromClientLine(requested.Line)); ok {
+			breakpoint.Verified, breakpoint.Line = true, s.toClientLine(line)
+		} else {
+			breakpoint.Message = "no statement at or after this line"
+		}
+		breakpoints = append(breakpoints, breakpoint)
+	}
+	return map[string]any{"breakpoints": breakpoints}, nil
+}
+
+func samePath(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && absA == absB
+}
+
+// configurationDone starts the program
+func (s *Server) configurationDone(json.RawMessage) (any, error) {
+	if s.debugger == nil {
+		return nil, errors.New("no program was launched")
+	}
+	if s.done != nil {
+		return nil, errors.New("the program already runs")
+	}
+	s.done = make(chan struct{})
+	s.next = s.run
+	return nil, nil
+}
+
+// run runs the program in a goroutine of its own, the server goes on serving requests
+func (s *Server) run() {
+	go func() {
+		defer close(s.done)
+		code := 0
+		switch result := s.debugger.Run(s.env).(type) {
+		case *object.Exit:
+			code = result.Code
+		case *object.Error:
+			s.event("output", OutputEventBody{Category: "stderr", Output: result.Inspect() + "\n"})
+			code = 1
+		}
+		s.event("exited", ExitedEventBody{ExitCode: code})
+		s.event("terminated", nil)
+	}()
+}
+
+// wait is the PauseFunc of the debugger, it runs in the goroutine of the program and waits for the client to
+// resume it
+func (s *Server) wait(d *debugger.Debugger, reason debugger.Reason) debugger.Action {
+	s.mu.Lock()
+	if s.terminating {
+		s.mu.Unlock()
+		return debugger.Stop
+	}
+	s.paused, s.refs = true, nil
+	s.mu.Unlock()
+
+	s.event("stopped", StoppedEventBody{Reason: reason.String(), ThreadID: threadID, AllThreadsStopped: true})
+	return <-s.resume
+}
+
+// resumeWith handles the requests resuming the program with action
+func resumeWith(action debugger.Action) handler {
+	return func(s *Server, _ json.RawMessage) (any, error) {
+		if err := s.resumeProgram(action); err != nil {
+			return nil, err
+		}
+		if action == debugger.Continue {
+			return map[string]any{"allThreadsContinued": true}, nil
+		}
+		return nil, nil
+	}
+}
+
+func (s *Server) resumeProgram(action debugger.Action) error {
+	s.mu.Lock()
+	if !s.paused {
+		s.mu.Unlock()
+		return errNotPaused
+	}
+	s.paused = false
+	s.mu.Unlock()
+	s.resume <- action
+	return nil
+}
+
+func (s *Server) pause(json.RawMessage) (any, error) {
+	if s.done == nil {
+		return nil, errors.New("the program does not run")
+	}
+	s.debugger.Interrupt()
+	return nil, nil
+}
+
+// terminate stops the program at its next statement, the client is told when it has ended
+func (s *Server) terminate(json.RawMessage) (any, error) {
+	s.stop()
+	return nil, nil
+}
+
+func (s *Server) stop() {
+	if s.done == nil {
+		return
+	}
+	s.mu.Lock()
+	s.terminating = true
+	s.mu.Unlock()
+	if s.resumeProgram(debugger.Stop) == errNotPaused {
+		s.debugger.Interrupt()
+	}
+}
+
+func (s *Server) threads(json.RawMessage) (any, error) {
+	return map[string]any{"threads": []Thread{{ID: threadID, Name: "main"}}}, nil
+}
+
+// pausedFrames returns the frames of the paused program, requests inspecting the program fail while it runs
+func (s *Server) pausedFrames() ([]*debugger.Frame, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.paused {
+		return nil, errNotPaused
+	}
+	return s.debugger.Frames(), nil
+}
+
+// frame returns the frame with id, frame ids are their index in the frames of the paused program plus one
+func (s *Server) frame(id int) (*debugger.Frame, error) {
+	frames, err := s.pausedFrames()
+	if err != nil {
+		return nil, err
+	}
+	if id < 1 || id > len(frames) {
+		return nil, fmt.Errorf("no frame %d", id)
+	}
+	return frames[id-1], nil
+}
+
+func (s *Server) stackTrace(args json.RawMessage) (any, error) {
+	a, err := decode[StackTraceArguments](args)
+	if err != nil {
+		return nil, err
+	}
+	frames, err := s.pausedFrames()
+	if err != nil {
+		return nil, err
+	}
+
+	stack := []StackFrame{}
+	source := Source{Name: filepath.Base(s.path), Path: s.path}
+	for i, frame := range frames {
+		if i < a.StartFrame || (a.Levels > 0 && i >= a.StartFrame+a.Levels) {
+			continue
+		}
+		stack = append(stack, StackFrame{
+			ID:     i + 1,
+			Name:   frame.Name,
+			Source: source,
+			Line:   s.toClientLine(frame.Pos.Line),
+			Column: s.toClientColumn(frame.Pos.Column),
+		})
+	}
+	return map[string]any{"stackFrames": stack, "totalFrames": len(frames)}, nil
+}
+
+func (s *Server) scopes(args json.RawMessage) (any, error) {
+	a, err := decode[ScopesArguments](args)
+	if err != nil {
+		return nil, err
+	}
+	frame, err := s.frame(a.FrameID)
+	if err != nil {
+		return nil, err
+	}
+
+	scopes := []Scope{}
+	for _, scope := range s.debugger.Scopes(frame) {
+		scopes = append(scopes, Scope{Name: scope.Name, VariablesReference: s.reference(scope.Variables)})
+	}
+	return map[string]any{"scopes": scopes}, nil
+}
+
+// reference gives the variables of a scope, or the elements of an array, a hash or a module, a number the client
+// can ask for them with. It returns 0 for values without elements.
+func (s *Server) reference(value any) int {
+	switch value.(type) {
+	case []debugger.Variable, *object.Array, *object.Hash, *object.Module:
+	default:
+		return 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.refs = append(s.refs, value)
+	return len(s.refs)
+}
+
+func (s *Server) variables(args json.RawMessage) (any, error) {
+	a, err := decode[VariablesArguments](args)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	paused, refs := s.paused, s.refs
+	s.mu.Unlock()
+	if !paused {
+		return nil, errNotPaused
+	}
+	if a.VariablesReference < 1 || a.VariablesReference > len(refs) {
+		return nil, fmt.Errorf("no variables with reference %d", a.VariablesReference)
+	}
+
+	var children []debugger.Variable
+	switch value := refs[a.VariablesReference-1].(type) {
+	case []debugger.Variable:
+		children = value
+	case *object.Array:
+		for i, element := range value.Elements {
+			children = append(children, debugger.Variable{Name: fmt.Sprintf("[%d]", i), Value: element})
+		}
+	case *object.Hash:
+		for _, pair := range value.Pair {
+			children = append(children, debugger.Variable{Name: pair.Key.Inspect(), Value: pair.Value})
+		}
+		sort.Slice(children, func(i, j int) bool { return children[i].Name < children[j].Name })
+	case *object.Module:
+		for name, export := range value.Exports {
+			children = append(children, debugger.Variable{Name: name, Value: export})
+		}
+		sort.Slice(children, func(i, j int) bool { return children[i].Name < children[j].Name })
+	}
+
+	variables := []Variable{}
+	for _, child := range children {
+		variables = append(variables, Variable{
+			Name:               child.Name,
+			Value:              object.Describe(child.Value),
+			Type:               string(child.Value.Type()),
+			VariablesReference: s.reference(child.Value),
+		})
+	}
+	return map[string]any{"variables": variables}, nil
+}
+
+// evaluate evaluates an expression in a frame of the paused program, the innermost one without a frame id
+func (s *Server) evaluate(args json.RawMessage) (any, error) {
+	a, err := decode[EvaluateArguments](args)
+	if err != nil {
+		return nil, err
+	}
+	frame, err := s.frame(max(a.FrameID, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	result := s.debugger.Evaluate(frame, a.Expression)
+	if errObj, ok := result.(*object.Error); ok {
+		return nil, errors.New(errObj.Message)
+	}
+	return map[string]any{
+		"result":             object.Describe(result),
+		"type":               string(result.Type()),
+		"variablesReference": s.reference(result),
+	}, nil
+}
+
+// The client counts lines and columns from 1 unless it said otherwise, the lexer always does
+func (s *Server) toClientLine(line int) int {
+	if s.linesStartAt1 {
+		return line
+	}
+	return line - 1
+}
+
+func (s *Server) fromClientLine(line int) int {
+	if s.linesStartAt1 {
+		return line
+	}
+	return line + 1
+}
+
+func (s *Server) toClientColumn(column int) int {
+	if s.columnsStartAt1 {
+		return column
+	}
+	return column - 1
+}
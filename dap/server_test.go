@@ -0,0 +1,303 @@
+package dap
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const script = `let base = 10;
+let add = fn(a, b) {
+  let sum = a + b;
+  sum + base
+};
+puts(add(1, 2));
+let values = [1, {"two": 2}];
+puts(len(values));
+`
+
+// message is anything the server sends, the fields of responses and events together
+type message struct {
+	Seq        int             `json:"seq"`
+	Type       string          `json:"type"`
+	Event      string          `json:"event"`
+	RequestSeq int             `json:"request_seq"`
+	Command    string          `json:"command"`
+	Success    bool            `json:"success"`
+	Message    string          `json:"message"`
+	Body       json.RawMessage `json:"body"`
+}
+
+// client talks to a server running in a goroutine of its own
+type client struct {
+	t       *testing.T
+	send    chan any // Requests are written by a goroutine of their own, as pipes have no buffer
+	r       *bufio.Reader
+	seq     int
+	events  []message
+	done    chan error
+	program string
+}
+
+func newClient(t *testing.T) *client {
+	t.Helper()
+	program := filepath.Join(t.TempDir(), "add.monkey")
+	if err := os.WriteFile(program, []byte(script), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	clientR, serverW := io.Pipe()
+	serverR, clientW := io.Pipe()
+	c := &client{t: t, send: make(chan any, 16), r: bufio.NewReader(clientR), done: make(chan error, 1), program: program}
+	go func() {
+		for req := range c.send {
+			writeMessage(clientW, req)
+		}
+	}()
+	go func() {
+		c.done <- NewServer(serverR, serverW).Run()
+		serverW.Close()
+	}()
+	return c
+}
+
+// request sends a request and returns its response, the events sent before it are kept for event
+func (c *client) request(command string, args any) message {
+	c.t.Helper()
+	c.seq++
+	req := map[string]any{"seq": c.seq, "type": "request", "command": command}
+	if args != nil {
+		req["arguments"] = args
+	}
+	c.send <- req
+	for {
+		msg := c.read()
+		if msg.Type == "response" && msg.RequestSeq == c.seq {
+			return msg
+		}
+		c.events = append(c.events, msg)
+	}
+}
+
+// event returns the first event called name not returned yet, the others stay for later calls
+func (c *client) event(name string) message {
+	c.t.Helper()
+	for i, msg := range c.events {
+		if msg.Type == "event" && msg.Event == name {
+			c.events = append(c.events[:i], c.events[i+1:]...)
+			return msg
+		}
+	}
+	for {
+		msg := c.read()
+		if msg.Type == "event" && msg.Event == name {
+			return msg
+		}
+		c.events = append(c.events, msg)
+	}
+}
+
+func (c *client) read() message {
+	c.t.Helper()
+	body, err := readMessage(c.r)
+	if err != nil {
+		c.t.Fatalf("cannot read from the server: %s", err)
+	}
+	var msg message
+	if err := json.Unmarshal(body, &msg); err != nil {
+		c.t.Fatal(err)
+	}
+	return msg
+}
+
+// body checks a response succeeded and decodes its body into a value of type T
+func body[T any](c *client, msg message) T {
+	c.t.Helper()
+	if !msg.Success {
+		c.t.Fatalf("%s failed: %s", msg.Command, msg.Message)
+	}
+	var value T
+	if len(msg.Body) != 0 {
+		if err := json.Unmarshal(msg.Body, &value); err != nil {
+			c.t.Fatal(err)
+		}
+	}
+	return value
+}
+
+// launch starts a session with breakpoints at lines
+func (c *client) launch(stopOnEntry bool, lines ...int) {
+	c.t.Helper()
+	body[map[string]any](c, c.request("initialize", map[string]any{"adapterID": "monkey"}))
+	body[any](c, c.request("launch", map[string]any{"program": c.program, "stopOnEntry": stopOnEntry}))
+	c.event("initialized")
+
+	var breakpoints []SourceBreakpoint
+	for _, line := range lines {
+		breakpoints = append(breakpoints, SourceBreakpoint{Line: line})
+	}
+	body[any](c, c.request("setBreakpoints", SetBreakpointsArguments{Source: Source{Path: c.program}, Breakpoints: breakpoints}))
+	body[any](c, c.request("configurationDone", nil))
+}
+
+// stopped waits for the program to pause and returns why and the innermost frame
+func (c *client) stopped() (string, StackFrame) {
+	c.t.Helper()
+	var stopped StoppedEventBody
+	json.Unmarshal(c.event("stopped").Body, &stopped)
+	trace := body[struct{ StackFrames []StackFrame }](c, c.request("stackTrace", StackTraceArguments{ThreadID: threadID}))
+	return stopped.Reason, trace.StackFrames[0]
+}
+
+func (c *client) finish() {
+	c.t.Helper()
+	body[any](c, c.request("disconnect", nil))
+	close(c.send)
+	// The last events of the program are written before Run returns
+	go io.Copy(io.Discard, c.r)
+	if err := <-c.done; err != nil {
+		c.t.Errorf("Run failed: %s", err)
+	}
+}
+
+func TestSession(t *testing.T) {
+	c := newClient(t)
+	c.launch(false, 4)
+
+	reason, frame := c.stopped()
+	if reason != "breakpoint" || frame.Name != "add" || frame.Line != 4 || frame.Source.Path != c.program {
+		t.Errorf("wrong stop. got=%s at %+v", reason, frame)
+	}
+
+	trace := body[struct {
+		StackFrames []StackFrame
+		TotalFrames int
+	}](c, c.request("stackTrace", StackTraceArguments{ThreadID: threadID}))
+	var names []string
+	for _, frame := range trace.StackFrames {
+		names = append(names, frame.Name)
+	}
+	if strings.Join(names, " ") != "add <main>" || trace.TotalFrames != 2 {
+		t.Errorf("wrong stack. got=%v, %d frames", names, trace.TotalFrames)
+	}
+
+	scopes := body[struct{ Scopes []Scope }](c, c.request("scopes", ScopesArguments{FrameID: frame.ID})).Scopes
+	if len(scopes) != 2 || scopes[0].Name != "locals of add" || scopes[1].Name != "globals" {
+		t.Fatalf("wrong scopes. got=%+v", scopes)
+	}
+	locals := body[struct{ Variables []Variable }](c, c.request("variables", VariablesArguments{VariablesReference: scopes[0].VariablesReference}))
+	expected := []Variable{{"a", "1", "INTEGER", 0}, {"b", "2", "INTEGER", 0}, {"sum", "3", "INTEGER", 0}}
+	if len(locals.Variables) != len(expected) {
+		t.Fatalf("wrong locals. got=%+v", locals.Variables)
+	}
+	for i, variable := range locals.Variables {
+		if variable != expected[i] {
+			t.Errorf("wrong local %d. expected=%+v, got=%+v", i, expected[i], variable)
+		}
+	}
+
+	evaluated := body[map[string]any](c, c.request("evaluate", EvaluateArguments{Expression: "sum * base", FrameID: frame.ID}))
+	if evaluated["result"] != "30" {
+		t.Errorf("wrong result. got=%v", evaluated)
+	}
+	if failed := c.request("evaluate", EvaluateArguments{Expression: "nope"}); failed.Success || failed.Message != "identifier not found: nope" {
+		t.Errorf("evaluating an unknown name did not fail. got=%+v", failed)
+	}
+
+	body[any](c, c.request("stepOut", nil))
+	if reason, frame := c.stopped(); reason != "step" || frame.Name != "<main>" || frame.Line != 7 {
+		t.Errorf("wrong stop after stepping out. got=%s at %+v", reason, frame)
+	}
+	var output OutputEventBody
+	json.Unmarshal(c.event("output").Body, &output)
+	if output.Category != "stdout" || output.Output != "13\n" {
+		t.Errorf("wrong output. got=%+v", output)
+	}
+
+	body[any](c, c.request("next", nil))
+	c.stopped()
+	values := body[map[string]any](c, c.request("evaluate", EvaluateArguments{Expression: "values"}))
+	elements := body[struct{ Variables []Variable }](c, c.request("variables", VariablesArguments{VariablesReference: int(values["variablesReference"].(float64))}))
+	if len(elements.Variables) != 2 || elements.Variables[1].Name != "[1]" || elements.Variables[1].VariablesReference == 0 {
+		t.Fatalf("wrong elements. got=%+v", elements.Variables)
+	}
+	pairs := body[struct{ Variables []Variable }](c, c.request("variables", VariablesArguments{VariablesReference: elements.Variables[1].VariablesReference}))
+	if len(pairs.Variables) != 1 || pairs.Variables[0] != (Variable{"two", "2", "INTEGER", 0}) {
+		t.Errorf("wrong pairs. got=%+v", pairs.Variables)
+	}
+
+	body[any](c, c.request("continue", nil))
+	var exited ExitedEventBody
+	json.Unmarshal(c.event("exited").Body, &exited)
+	if exited.ExitCode != 0 {
+		t.Errorf("wrong exit code. got=%d", exited.ExitCode)
+	}
+	c.event("terminated")
+	if failed := c.request("stackTrace", StackTraceArguments{ThreadID: threadID}); failed.Success {
+		t.Errorf("stack trace of an ended program did not fail")
+	}
+	c.finish()
+}
+
+func TestBreakpointsAndEntry(t *testing.T) {
+	c := newClient(t)
+	body[any](c, c.request("initialize", map[string]any{"linesStartAt1": false}))
+	body[any](c, c.request("launch", map[string]any{"program": c.program, "stopOnEntry": true}))
+
+	set := body[struct{ Breakpoints []Breakpoint }](c, c.request("setBreakpoints", SetBreakpointsArguments{
+		Source:      Source{Path: c.program},
+		Breakpoints: []SourceBreakpoint{{Line: 4}, {Line: 40}},
+	}))
+	if len(set.Breakpoints) != 2 || !set.Breakpoints[0].Verified || set.Breakpoints[0].Line != 5 || set.Breakpoints[1].Verified {
+		t.Errorf("wrong breakpoints. got=%+v", set.Breakpoints)
+	}
+	other := body[struct{ Breakpoints []Breakpoint }](c, c.request("setBreakpoints", SetBreakpointsArguments{
+		Source:      Source{Path: "other.monkey"},
+		Breakpoints: []SourceBreakpoint{{Line: 1}},
+	}))
+	if other.Breakpoints[0].Verified {
+		t.Errorf("breakpoint in another file verified")
+	}
+	body[any](c, c.request("configurationDone", nil))
+
+	// Lines are counted from 0 for this client
+	if reason, frame := c.stopped(); reason != "entry" || frame.Line != 0 {
+		t.Errorf("wrong stop on entry. got=%s at %+v", reason, frame)
+	}
+	body[any](c, c.request("continue", nil))
+	if reason, frame := c.stopped(); reason != "breakpoint" || frame.Line != 5 {
+		t.Errorf("wrong stop at the breakpoint. got=%s at %+v", reason, frame)
+	}
+
+	// Disconnecting stops the paused program
+	c.finish()
+}
+
+func TestFailures(t *testing.T) {
+	c := newClient(t)
+	tests := []struct {
+		command string
+		args    any
+		message string
+	}{
+		{"launch", map[string]any{}, "launch needs the path of a program"},
+		{"setBreakpoints", SetBreakpointsArguments{}, "no program was launched"},
+		{"configurationDone", nil, "no program was launched"},
+		{"continue", nil, "the program is not paused"},
+		{"scopes", ScopesArguments{FrameID: 1}, "the program is not paused"},
+		{"bogus", nil, `unknown command "bogus"`},
+		{"launch", "not an object", "invalid arguments: json: cannot unmarshal string into Go value of type dap.LaunchArguments"},
+	}
+
+	for _, tt := range tests {
+		resp := c.request(tt.command, tt.args)
+		if resp.Success || resp.Message != tt.message {
+			t.Errorf("%s: wrong response. expected=%q, got=%+v", tt.command, tt.message, resp)
+		}
+	}
+	c.finish()
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/ShivankSharma070/go-interpreter/debugger"
+)
+
+// debugCommand implements the debug subcommand, it runs a script with the tree walking evaluator under a debugger
+// driven from the terminal
+func debugCommand(args []string) int {
+	var lines []int
+	flags := flag.NewFlagSet("debug", flag.ContinueOnError)
+	flags.Func("b", "comma separated `lines` to set breakpoints at, the script then runs until the first one", func(value string) error {
+		for _, item := range splitList(value) {
+			line, err := strconv.Atoi(item)
+			if err != nil {
+				return fmt.Errorf("not a line number: %q", item)
+			}
+			lines = append(lines, line)
+		}
+		return nil
+	})
+	flags.Var(&fileAccess, "files", fileAccessUsage)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "usage: %s debug [-b lines] [-files access] script\n", os.Args[0])
+		fmt.Fprintln(flags.Output(), "Without breakpoints the script pauses before its first statement, type help there for the commands.")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+
+	path := flags.Arg(0)
+	source, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	env := newEnvironment(path)
+	program, errObj := expandProgram(string(source), env)
+	if errObj != nil {
+		fmt.Fprintln(os.Stderr, errObj.Message)
+		return 1
+	}
+
+	console := debugger.NewConsole(os.Stdin, os.Stdout, path, string(source))
+	d := debugger.New(program, console.Pause, len(lines) == 0)
+	for _, line := range lines {
+		if _, ok := d.SetBreakpoint(line); !ok {
+			fmt.Fprintf(os.Stderr, "%s: no statement at or after line %d\n", path, line)
+		}
+	}
+	return exitCode(d.Run(env))
+}
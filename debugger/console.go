@@ -0,0 +1,155 @@
+package debugger
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/ShivankSharma070/go-interpreter/object"
+)
+
+// Lines shown around the current one by list
+const listContext = 5
+
+const consoleHelp = `commands:
+  break LINE, b LINE     pause at LINE
+  clear LINE             remove the breakpoint at LINE
+  breakpoints, bl        list the breakpoints
+  continue, c            run until a breakpoint
+  step, s                run to the next statement, into the functions it calls
+  next, n                run to the next statement of this function
+  out, o                 run until the current function returns
+  where, bt              show the calls in progress
+  frame N, f N           select the Nth call shown by where
+  vars, v                show the variables of the selected call
+  print CODE, p CODE     evaluate CODE in the selected call
+  list, l                show the code around the selected call
+  quit, q                end the program
+`
+
+// Console drives a debugger with commands read from a terminal, one per line. Its Pause method is the PauseFunc
+// of the debugger.
+type Console struct {
+	in    *bufio.Scanner
+	out   io.Writer
+	path  string
+	lines []string
+	frame int // Index of the frame selected, in Frames
+}
+
+// NewConsole creates a console reading commands from in and writing to out, for the script at path
+func NewConsole(in io.Reader, out io.Writer, path, source string) *Console {
+	lines := strings.Split(strings.TrimSuffix(source, "\n"), "\n")
+	return &Console{in: bufio.NewScanner(in), out: out, path: path, lines: lines}
+}
+
+// Pause shows where the program paused and runs commands until one of them resumes it. The end of the input
+// stops the program.
+func (c *Console) Pause(d *Debugger, reason Reason) Action {
+	c.frame = 0
+	frame := d.Frames()[0]
+	fmt.Fprintf(c.out, "%s at %s:%d in %s\n", reason, c.path, frame.Pos.Line, frame.Name)
+	c.showLine(frame.Pos.Line, true)
+
+	for {
+		fmt.Fprint(c.out, "(debug) ")
+		if !c.in.Scan() {
+			fmt.Fprintln(c.out)
+			return Stop
+		}
+		command, arg, _ := strings.Cut(strings.TrimSpace(c.in.Text()), " ")
+		arg = strings.TrimSpace(arg)
+
+		switch command {
+		case "":
+		case "break", "b":
+			if line, ok := c.line(arg); ok {
+				if line, ok := d.SetBreakpoint(line); ok {
+					fmt.Fprintf(c.out, "breakpoint at line %d\n", line)
+				} else {
+					fmt.Fprintf(c.out, "no statement at or after line %s\n", arg)
+				}
+			}
+		case "clear":
+			if line, ok := c.line(arg); ok && !d.ClearBreakpoint(line) {
+				fmt.Fprintf(c.out, "no breakpoint at line %d\n", line)
+			}
+		case "breakpoints", "bl":
+			for _, line := range d.Breakpoints() {
+				c.showLine(line, false)
+			}
+		case "continue", "c":
+			return Continue
+		case "step", "s":
+			return StepIn
+		case "next", "n":
+			return StepOver
+		case "out", "o":
+			return StepOut
+		case "quit", "q":
+			return Stop
+		case "where", "bt":
+			for i, frame := range d.Frames() {
+				marker := " "
+				if i == c.frame {
+					marker = "*"
+				}
+				fmt.Fprintf(c.out, "%s #%d %s at %s:%d\n", marker, i, frame.Name, c.path, frame.Pos.Line)
+			}
+		case "frame", "f":
+			n, err := strconv.Atoi(arg)
+			if err != nil || n < 0 || n >= len(d.Frames()) {
+				fmt.Fprintf(c.out, "no frame %q, see where\n", arg)
+				continue
+			}
+			c.frame = n
+			frame := d.Frames()[n]
+			fmt.Fprintf(c.out, "#%d %s at %s:%d\n", n, frame.Name, c.path, frame.Pos.Line)
+		case "vars", "v":
+			for _, scope := range d.Scopes(d.Frames()[c.frame]) {
+				fmt.Fprintf(c.out, "%s:\n", scope.Name)
+				for _, variable := range scope.Variables {
+					fmt.Fprintf(c.out, "  %s = %s\n", variable.Name, object.Describe(variable.Value))
+				}
+			}
+		case "print", "p":
+			if arg == "" {
+				fmt.Fprintln(c.out, "print needs code to evaluate")
+				continue
+			}
+			fmt.Fprintln(c.out, d.Evaluate(d.Frames()[c.frame], arg).Inspect())
+		case "list", "l":
+			current := d.Frames()[c.frame].Pos.Line
+			for line := max(current-listContext, 1); line <= min(current+listContext, len(c.lines)); line++ {
+				c.showLine(line, line == current)
+			}
+		case "help", "h":
+			fmt.Fprint(c.out, consoleHelp)
+		default:
+			fmt.Fprintf(c.out, "unknown command %q, see help\n", command)
+		}
+	}
+}
+
+// line parses the line number given to a command
+func (c *Console) line(arg string) (int, bool) {
+	line, err := strconv.Atoi(arg)
+	if err != nil || line < 1 {
+		fmt.Fprintf(c.out, "not a line number: %q\n", arg)
+		return 0, false
+	}
+	return line, true
+}
+
+func (c *Console) showLine(line int, current bool) {
+	if line < 1 || line > len(c.lines) {
+		return
+	}
+	marker := "  "
+	if current {
+		marker = "->"
+	}
+	fmt.Fprintf(c.out, "%s %4d  %s\n", marker, line, c.lines[line-1])
+}
@@ -0,0 +1,77 @@
+package debugger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ShivankSharma070/go-interpreter/object"
+)
+
+func TestConsole(t *testing.T) {
+	commands := strings.Join([]string{
+		"b 3", "b x", "b 40", "bl", "c",
+		"bt", "v", "p a + b", "f 1", "p x", "f 9",
+		"n", "o", "clear 3", "clear 3", "l", "oops", "c",
+	}, "\n")
+	var out bytes.Buffer
+	console := NewConsole(strings.NewReader(commands), &out, "add.monkey", script+"\n")
+	result := newDebugger(t, script, console.Pause, true).Run(object.NewEnvironment())
+	if result.Inspect() != "38" {
+		t.Errorf("wrong result. got=%s", result.Inspect())
+	}
+
+	expected := `entry at add.monkey:1 in <main>
+->    1  let base = 10;
+(debug) breakpoint at line 3
+(debug) not a line number: "x"
+(debug) no statement at or after line 40
+(debug)       3    let sum = a + b;
+(debug) breakpoint at add.monkey:3 in add
+->    3    let sum = a + b;
+(debug) * #0 add at add.monkey:3
+  #1 twice at add.monkey:7
+  #2 <main> at add.monkey:10
+(debug) locals of add:
+  a = 2
+  b = 2
+globals:
+  add = fn(a, b)
+  base = 10
+  twice = fn(x)
+(debug) 4
+(debug) #1 twice at add.monkey:7
+(debug) 2
+(debug) no frame "9", see where
+(debug) step at add.monkey:4 in add
+->    4    sum + base
+(debug) step at add.monkey:8 in twice
+->    8    add(once, once)
+(debug) (debug) no breakpoint at line 3
+(debug)       3    let sum = a + b;
+      4    sum + base
+      5  };
+      6  let twice = fn(x) {
+      7    let once = add(x, x);
+->    8    add(once, once)
+      9  };
+     10  let result = twice(2);
+     11  result
+(debug) unknown command "oops", see help
+(debug) `
+	if out.String() != expected {
+		t.Errorf("wrong output.\nexpected:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
+
+func TestConsoleEndOfInput(t *testing.T) {
+	var out bytes.Buffer
+	console := NewConsole(strings.NewReader("help\n"), &out, "add.monkey", script)
+	result := newDebugger(t, script, console.Pause, true).Run(object.NewEnvironment())
+	if exit, ok := result.(*object.Exit); !ok || exit.Code != 1 {
+		t.Errorf("end of input did not stop the program. got=%v", result)
+	}
+	if !strings.Contains(out.String(), consoleHelp) {
+		t.Errorf("help not shown. got=%q", out.String())
+	}
+}
@@ -0,0 +1,435 @@
+// Package debugger runs a program with the tree walking evaluator while a frontend, like the console of the debug
+// subcommand, pauses it at breakpoints or step by step, inspects its variables and evaluates expressions where
+// it is paused.
+package debugger
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ShivankSharma070/go-interpreter/ast"
+	"github.com/ShivankSharma070/go-interpreter/evaluator"
+	"github.com/ShivankSharma070/go-interpreter/lexer"
+	"github.com/ShivankSharma070/go-interpreter/object"
+	"github.com/ShivankSharma070/go-interpreter/parser"
+	"github.com/ShivankSharma070/go-interpreter/token"
+)
+
+// Reason a program paused
+type Reason int
+
+const (
+	Entry      Reason = iota // Before the first statement
+	Breakpoint               // At a line with a breakpoint
+	Step                     // After a step
+	Pause                    // When asked to by Interrupt
+)
+
+func (r Reason) String() string {
+	switch r {
+	case Entry:
+		return "entry"
+	case Breakpoint:
+		return "breakpoint"
+	case Pause:
+		return "pause"
+	default:
+		return "step"
+	}
+}
+
+// Action tells a paused program how to go on
+type Action int
+
+const (
+	Continue Action = iota // Run until a breakpoint
+	StepIn                 // Pause at the next statement, in a function it calls as well
+	StepOver               // Pause at the next statement of the current function or of one calling it
+	StepOut                // Pause at the next statement of a function calling the current one
+	Stop                   // End the program, as if it called exit(1)
+)
+
+// PauseFunc is called every time the program pauses, the program goes on as the returned action says once it
+// returns. Until then d can inspect the program.
+type PauseFunc func(d *Debugger, reason Reason) Action
+
+// Frame is a call in progress, of the program itself or of one of its functions
+type Frame struct {
+	Name     string                  // Name of the function, <main> for the program and <anonymous> without one
+	Function *ast.FunctionExpression // Nil for the program and functions of imported modules
+	Pos      token.Position          // Statement being run, or the call being made for the frames of callers
+	Env      *object.Environment
+
+	line int // Line of the last statement run in the frame
+}
+
+// Scope is a set of variables of a frame, its locals, the ones of the function around it or the globals
+type Scope struct {
+	Name      string
+	Variables []Variable
+}
+
+type Variable struct {
+	Name  string
+	Value object.Object
+}
+
+// Debugger runs one program
+type Debugger struct {
+	program *ast.Program
+	pause   PauseFunc
+
+	// What the program is made of, found before it runs
+	statements map[ast.Statement]bool
+	lines      map[int]bool
+	functions  map[*ast.BlockStatement]*ast.FunctionExpression
+	enclosing  map[*ast.FunctionExpression]*ast.FunctionExpression
+	names      map[*ast.FunctionExpression]string
+	slots      map[*ast.FunctionExpression][]string
+
+	mu          sync.Mutex // Breakpoints and interruptions can be asked for while the program runs
+	breakpoints map[int]bool
+	interrupted bool
+
+	frames     []*Frame // Innermost last
+	action     Action
+	depth      int  // Number of frames when the last action was chosen
+	entry      bool // Whether the next pause is the one on entry
+	evaluating bool // Whether an expression is being evaluated for the frontend
+}
+
+// New creates a debugger for program, with macros already expanded. It pauses before the first statement when
+// stopOnEntry is set, otherwise at the first breakpoint.
+func New(program *ast.Program, pause PauseFunc, stopOnEntry bool) *Debugger {
+	d := &Debugger{
+		program:     program,
+		pause:       pause,
+		statements:  make(map[ast.Statement]bool),
+		lines:       make(map[int]bool),
+		functions:   make(map[*ast.BlockStatement]*ast.FunctionExpression),
+		enclosing:   make(map[*ast.FunctionExpression]*ast.FunctionExpression),
+		names:       make(map[*ast.FunctionExpression]string),
+		slots:       make(map[*ast.FunctionExpression][]string),
+		breakpoints: make(map[int]bool),
+		entry:       stopOnEntry,
+	}
+	if stopOnEntry {
+		d.action = StepIn
+	}
+
+	// The nodes being inspected are kept to know the function around every other one, Inspect calls f(nil) once
+	// it is done with the children of a node
+	var nodes []ast.Node
+	var functions []*ast.FunctionExpression
+	ast.Inspect(program, func(node ast.Node) bool {
+		if node == nil {
+			if _, ok := nodes[len(nodes)-1].(*ast.FunctionExpression); ok {
+				functions = functions[:len(functions)-1]
+			}
+			nodes = nodes[:len(nodes)-1]
+			return true
+		}
+		nodes = append(nodes, node)
+
+		switch node := node.(type) {
+		case *ast.BlockStatement:
+		case ast.Statement:
+			d.statements[node] = true
+			d.lines[ast.Start(node).Line] = true
+			if let, ok := node.(*ast.LetStatement); ok {
+				if fn, ok := let.Value.(*ast.FunctionExpression); ok && let.Name != nil {
+					d.names[fn] = let.Name.Value
+				}
+			}
+		case *ast.FunctionExpression:
+			if len(functions) > 0 {
+				d.enclosing[node] = functions[len(functions)-1]
+			}
+			d.functions[node.Body] = node
+			functions = append(functions, node)
+		}
+		return true
+	})
+	return d
+}
+
+// SetBreakpoint pauses the program at line, or at the first line after it with a statement. It returns the line
+// of the breakpoint, false when no line from line on has a statement.
+func (d *Debugger) SetBreakpoint(line int) (int, bool) {
+	last := 0
+	for l := range d.lines {
+		last = max(last, l)
+	}
+	for ; line <= last; line++ {
+		if d.lines[line] {
+			d.mu.Lock()
+			d.breakpoints[line] = true
+			d.mu.Unlock()
+			return line, true
+		}
+	}
+	return 0, false
+}
+
+// ClearBreakpoint removes the breakpoint at line, it reports whether there was one
+func (d *Debugger) ClearBreakpoint(line int) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	ok := d.breakpoints[line]
+	delete(d.breakpoints, line)
+	return ok
+}
+
+// ClearBreakpoints removes every breakpoint
+func (d *Debugger) ClearBreakpoints() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	clear(d.breakpoints)
+}
+
+// Breakpoints returns the lines with a breakpoint, sorted
+func (d *Debugger) Breakpoints() []int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	lines := make([]int, 0, len(d.breakpoints))
+	for line := range d.breakpoints {
+		lines = append(lines, line)
+	}
+	sort.Ints(lines)
+	return lines
+}
+
+func (d *Debugger) breakpoint(line int) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.breakpoints[line]
+}
+
+// Interrupt pauses the program before its next statement, it is safe to call while the program runs
+func (d *Debugger) Interrupt() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.interrupted = true
+}
+
+// interruption reports whether Interrupt was called since the last time it was asked
+func (d *Debugger) interruption() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	interrupted := d.interrupted
+	d.interrupted = false
+	return interrupted
+}
+
+// Run evaluates the program in env and returns its result. The debugger follows the evaluation through the
+// runtime of env until the program ends.
+func (d *Debugger) Run(env *object.Environment) object.Object {
+	d.frames = []*Frame{{Name: "<main>", Env: env}}
+	h := &hook{d: d}
+	env.Runtime.Hooks.Attach(h)
+	defer env.Runtime.Hooks.Detach(h)
+	return evaluator.Eval(d.program, env)
+}
+
+// hook is what the evaluator sees of the debugger, it follows statements and calls of monkey functions
+type hook struct {
+	object.NopHook
+	d *Debugger
+}
+
+func (h *hook) Enter(node ast.Node, env *object.Environment) object.Object {
+	d := h.d
+	// Statements of imported modules run without pausing
+	statement, ok := node.(ast.Statement)
+	if d.evaluating || !ok || !d.statements[statement] {
+		return nil
+	}
+
+	frame := d.frames[len(d.frames)-1]
+	frame.Pos = ast.Start(statement)
+	newLine := frame.Pos.Line != frame.line
+	frame.line = frame.Pos.Line
+
+	reason, pause := Step, false
+	switch d.action {
+	case StepIn:
+		pause = true
+	case StepOver:
+		pause = len(d.frames) <= d.depth
+	case StepOut:
+		pause = len(d.frames) < d.depth
+	}
+	if d.interruption() {
+		reason, pause = Pause, true
+	}
+	// A line with several statements is only stopped at once
+	if newLine && d.breakpoint(frame.line) {
+		reason, pause = Breakpoint, true
+	}
+	if d.entry {
+		reason, pause, d.entry = Entry, true, false
+	}
+	if !pause {
+		return nil
+	}
+
+	d.action = d.pause(d, reason)
+	d.depth = len(d.frames)
+	if d.action == Stop {
+		return &object.Exit{Code: 1}
+	}
+	return nil
+}
+
+func (h *hook) Call(callee object.Object, _ []object.Object, env *object.Environment, pos token.Position) {
+	d := h.d
+	fn, ok := callee.(*object.FunctionLiteral)
+	if d.evaluating || !ok {
+		return
+	}
+	d.frames[len(d.frames)-1].Pos = pos
+
+	frame := &Frame{Name: "<anonymous>", Function: d.functions[fn.Body], Env: env}
+	if name, ok := d.names[frame.Function]; ok {
+		frame.Name = name
+	}
+	d.frames = append(d.frames, frame)
+}
+
+func (h *hook) Return(callee object.Object, _ object.Object) {
+	d := h.d
+	if _, ok := callee.(*object.FunctionLiteral); ok && !d.evaluating {
+		d.frames = d.frames[:len(d.frames)-1]
+	}
+}
+
+// Frames returns the calls in progress, the one paused in first and the program last
+func (d *Debugger) Frames() []*Frame {
+	frames := make([]*Frame, len(d.frames))
+	for i, frame := range d.frames {
+		frames[len(frames)-1-i] = frame
+	}
+	return frames
+}
+
+// Scopes returns the variables frame can use, its own first. A function sees the variables of the functions
+// around it, then the globals.
+func (d *Debugger) Scopes(frame *Frame) []Scope {
+	scopes, env := d.localScopes(frame)
+	for ; env != nil; env = env.Outer {
+		scope := Scope{Name: "globals"}
+		if env.Outer != nil {
+			scope.Name = "environment"
+		}
+		names := make([]string, 0, len(env.Store))
+		for name := range env.Store {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			scope.Variables = append(scope.Variables, Variable{Name: name, Value: env.Store[name]})
+		}
+		scopes = append(scopes, scope)
+	}
+	return scopes
+}
+
+// localScopes returns the scopes of the calls frame can see the locals of, and the environment of the variables
+// stored by name that comes after them
+func (d *Debugger) localScopes(frame *Frame) ([]Scope, *object.Environment) {
+	var scopes []Scope
+	env, fn := frame.Env, frame.Function
+	for ; env != nil && env.Slots != nil; env = env.Outer {
+		name := "locals"
+		if len(scopes) > 0 {
+			name = "closure"
+		}
+		if fn != nil {
+			name += " of " + d.functionName(fn)
+		}
+		scope := Scope{Name: name}
+		names := d.slotNames(fn, len(env.Slots))
+		for slot, value := range env.Slots {
+			// Lets that did not run yet have no value
+			if value != nil {
+				scope.Variables = append(scope.Variables, Variable{Name: names[slot], Value: value})
+			}
+		}
+		scopes = append(scopes, scope)
+		if fn != nil {
+			fn = d.enclosing[fn]
+		}
+	}
+	return scopes, env
+}
+
+func (d *Debugger) functionName(fn *ast.FunctionExpression) string {
+	if name, ok := d.names[fn]; ok {
+		return name
+	}
+	return "<anonymous>"
+}
+
+// slotNames returns the names of the size slots of a call of fn, the resolver gave every parameter and let of
+// the function a slot. Slots of an unknown function are named by their number.
+func (d *Debugger) slotNames(fn *ast.FunctionExpression, size int) []string {
+	names, ok := d.slots[fn]
+	if !ok && fn != nil {
+		names = make([]string, fn.NumLocals)
+		for i, param := range fn.Parameters {
+			names[i] = param.Value
+		}
+		ast.Inspect(fn.Body, func(node ast.Node) bool {
+			switch node := node.(type) {
+			case *ast.FunctionExpression, *ast.MacroLiteral:
+				return false
+			case *ast.LetStatement:
+				if node.Name != nil && node.Name.Local && node.Name.Slot < len(names) {
+					names[node.Name.Slot] = node.Name.Value
+				}
+			}
+			return true
+		})
+		d.slots[fn] = names
+	}
+
+	named := make([]string, size)
+	for slot := range named {
+		if slot < len(names) && names[slot] != "" {
+			named[slot] = names[slot]
+		} else {
+			named[slot] = fmt.Sprintf("$%d", slot)
+		}
+	}
+	return named
+}
+
+// Evaluate evaluates an expression, or any code, as if it was written where frame is paused. The code can use
+// the variables of the frame but the lets it runs do not change them.
+func (d *Debugger) Evaluate(frame *Frame, code string) object.Object {
+	p := parser.New(lexer.New(code))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		return &object.Error{Message: strings.Join(p.Errors(), "; ")}
+	}
+
+	// The code runs in an environment of its own, holding the variables of the frame the globals do not have
+	scopes, globals := d.localScopes(frame)
+	env := object.NewEnclosingEnvironment(globals)
+	for i := len(scopes) - 1; i >= 0; i-- {
+		for _, variable := range scopes[i].Variables {
+			env.Set(variable.Name, variable.Value)
+		}
+	}
+
+	// Functions called by the code run without pausing
+	d.evaluating = true
+	defer func() { d.evaluating = false }()
+	result := evaluator.Eval(program, env)
+	if result == nil {
+		return object.NULL
+	}
+	return result
+}
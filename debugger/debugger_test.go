@@ -0,0 +1,189 @@
+package debugger
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ShivankSharma070/go-interpreter/lexer"
+	"github.com/ShivankSharma070/go-interpreter/object"
+	"github.com/ShivankSharma070/go-interpreter/parser"
+)
+
+const script = `let base = 10;
+let add = fn(a, b) {
+  let sum = a + b;
+  sum + base
+};
+let twice = fn(x) {
+  let once = add(x, x);
+  add(once, once)
+};
+let result = twice(2);
+result`
+
+func TestStepping(t *testing.T) {
+	tests := []struct {
+		name        string
+		stopOnEntry bool
+		breakpoints []int
+		actions     []Action
+		expected    []string
+	}{
+		{"step in", true, nil, []Action{StepIn, StepIn, StepIn, StepIn, StepIn, Continue}, []string{
+			"entry 1 <main>", "step 2 <main>", "step 6 <main>", "step 10 <main>", "step 7 twice", "step 3 add",
+		}},
+		{"step over", true, nil, []Action{StepOver, StepOver, StepOver, StepOver, StepOver}, []string{
+			"entry 1 <main>", "step 2 <main>", "step 6 <main>", "step 10 <main>", "step 11 <main>",
+		}},
+		{"step out", false, []int{3}, []Action{StepOut, StepOut, StepOut}, []string{
+			"breakpoint 3 add", "step 8 twice", "breakpoint 3 add", "step 11 <main>",
+		}},
+		{"breakpoints", false, []int{8, 4}, []Action{Continue, Continue, Continue, Continue}, []string{
+			"breakpoint 4 add", "breakpoint 8 twice", "breakpoint 4 add",
+		}},
+		{"breakpoint moved to a statement", false, []int{5}, []Action{StepOver}, []string{
+			"breakpoint 6 <main>", "step 10 <main>",
+		}},
+	}
+
+	for _, tt := range tests {
+		var got []string
+		d := newDebugger(t, script, func(d *Debugger, reason Reason) Action {
+			frame := d.Frames()[0]
+			got = append(got, fmt.Sprintf("%s %d %s", reason, frame.Pos.Line, frame.Name))
+			if len(got) > len(tt.actions) {
+				return Stop
+			}
+			return tt.actions[len(got)-1]
+		}, tt.stopOnEntry)
+		for _, line := range tt.breakpoints {
+			d.SetBreakpoint(line)
+		}
+		d.Run(object.NewEnvironment())
+
+		if strings.Join(got, "\n") != strings.Join(tt.expected, "\n") {
+			t.Errorf("%s: wrong pauses.\nexpected:\n%s\ngot:\n%s", tt.name, strings.Join(tt.expected, "\n"), strings.Join(got, "\n"))
+		}
+	}
+}
+
+func TestInspection(t *testing.T) {
+	var frames, scopes []string
+	var evaluated []string
+	d := newDebugger(t, script, func(d *Debugger, reason Reason) Action {
+		for _, frame := range d.Frames() {
+			frames = append(frames, fmt.Sprintf("%s:%d", frame.Name, frame.Pos.Line))
+		}
+		for _, scope := range d.Scopes(d.Frames()[0]) {
+			var variables []string
+			for _, variable := range scope.Variables {
+				variables = append(variables, variable.Name+"="+object.Describe(variable.Value))
+			}
+			scopes = append(scopes, scope.Name+": "+strings.Join(variables, " "))
+		}
+		for _, code := range []string{"a * sum + base", "add(a, 1)", "x", "let base = 0; base"} {
+			evaluated = append(evaluated, d.Evaluate(d.Frames()[0], code).Inspect())
+		}
+		evaluated = append(evaluated, d.Evaluate(d.Frames()[1], "x * base").Inspect())
+		return Stop
+	}, false)
+	d.SetBreakpoint(4)
+
+	result := d.Run(object.NewEnvironment())
+	if exit, ok := result.(*object.Exit); !ok || exit.Code != 1 {
+		t.Errorf("stopping did not exit. got=%v", result)
+	}
+
+	expectedFrames := []string{"add:4", "twice:7", "<main>:10"}
+	if strings.Join(frames, " ") != strings.Join(expectedFrames, " ") {
+		t.Errorf("wrong frames. expected=%v, got=%v", expectedFrames, frames)
+	}
+	expectedScopes := []string{"locals of add: a=2 b=2 sum=4", "globals: add=fn(a, b) base=10 twice=fn(x)"}
+	if strings.Join(scopes, "\n") != strings.Join(expectedScopes, "\n") {
+		t.Errorf("wrong scopes. expected=%q, got=%q", expectedScopes, scopes)
+	}
+	expectedEvaluated := []string{"18", "13", "Error: identifier not found: x", "0", "20"}
+	if strings.Join(evaluated, "\n") != strings.Join(expectedEvaluated, "\n") {
+		t.Errorf("wrong results. expected=%q, got=%q", expectedEvaluated, evaluated)
+	}
+}
+
+func TestClosureScopes(t *testing.T) {
+	input := `let outer = fn(a) {
+  let b = a * 2;
+  fn(c) {
+    c + a + b
+  }
+};
+outer(1)(5);`
+
+	var scopes []string
+	d := newDebugger(t, input, func(d *Debugger, reason Reason) Action {
+		for _, scope := range d.Scopes(d.Frames()[0]) {
+			var variables []string
+			for _, variable := range scope.Variables {
+				variables = append(variables, variable.Name+"="+object.Describe(variable.Value))
+			}
+			scopes = append(scopes, scope.Name+": "+strings.Join(variables, " "))
+		}
+		return Continue
+	}, false)
+	d.SetBreakpoint(4)
+	d.Run(object.NewEnvironment())
+
+	expected := []string{"locals of <anonymous>: c=5", "closure of outer: a=1 b=2", "globals: outer=fn(a)"}
+	if strings.Join(scopes, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("wrong scopes.\nexpected:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(scopes, "\n"))
+	}
+}
+
+func TestInterrupt(t *testing.T) {
+	var got []string
+	d := newDebugger(t, script, func(d *Debugger, reason Reason) Action {
+		got = append(got, fmt.Sprintf("%s %d", reason, d.Frames()[0].Pos.Line))
+		if len(got) == 1 {
+			d.Interrupt()
+		}
+		return Continue
+	}, false)
+	d.SetBreakpoint(3)
+	d.Interrupt()
+	d.Run(object.NewEnvironment())
+
+	expected := []string{"pause 1", "pause 2", "breakpoint 3", "breakpoint 3"}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("wrong pauses. expected=%q, got=%q", expected, got)
+	}
+}
+
+func TestBreakpoints(t *testing.T) {
+	d := newDebugger(t, script, nil, false)
+	if line, ok := d.SetBreakpoint(5); !ok || line != 6 {
+		t.Errorf("wrong line for a breakpoint at 5. got=%d, %t", line, ok)
+	}
+	if _, ok := d.SetBreakpoint(12); ok {
+		t.Errorf("breakpoint after the last statement was set")
+	}
+	d.SetBreakpoint(2)
+	if got := d.Breakpoints(); fmt.Sprint(got) != "[2 6]" {
+		t.Errorf("wrong breakpoints. got=%v", got)
+	}
+	if !d.ClearBreakpoint(2) || d.ClearBreakpoint(2) {
+		t.Errorf("clearing a breakpoint twice did not fail only once")
+	}
+	d.ClearBreakpoints()
+	if got := d.Breakpoints(); len(got) != 0 {
+		t.Errorf("breakpoints left after clearing them all. got=%v", got)
+	}
+}
+
+func newDebugger(t *testing.T, input string, pause PauseFunc, stopOnEntry bool) *Debugger {
+	t.Helper()
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parser errors: %v", p.Errors())
+	}
+	return New(program, pause, stopOnEntry)
+}
@@ -0,0 +1,132 @@
+package evaluator
+
+import "fmt"
+
+// Arity is the number of arguments a builtin accepts, Max is -1 when there is no limit
+type Arity struct {
+	Min, Max int
+}
+
+// Accepts reports whether a call with n arguments passes the argument count check of the builtin
+func (a Arity) Accepts(n int) bool {
+	return n >= a.Min && (a.Max < 0 || n <= a.Max)
+}
+
+func (a Arity) String() string {
+	switch {
+	case a.Max < 0:
+		return fmt.Sprintf("at least %d", a.Min)
+	case a.Min == a.Max:
+		return fmt.Sprintf("%d", a.Min)
+	case a.Max == a.Min+1:
+		return fmt.Sprintf("%d or %d", a.Min, a.Max)
+	default:
+		return fmt.Sprintf("%d to %d", a.Min, a.Max)
+	}
+}
+
+// BuiltinArity returns the number of arguments the builtin called name accepts, tools use it to check calls
+// without running them.
+func BuiltinArity(name string) (Arity, bool) {
+	arity, ok := arities[name]
+	return arity, ok
+}
+
+// The builtins check their arguments themselves, this table follows those checks and is tested against them
+var arities = map[string]Arity{
+	// builtins.go
+	"len":   {1, 1},
+	"exit":  {0, 1},
+	"first": {1, 1},
+	"last":  {1, 1},
+	"rest":  {1, 1},
+	"push":  {2, 2},
+	"puts":  {0, -1},
+
+	// builtins_collection.go
+	"map":       {2, 2},
+	"filter":    {2, 2},
+	"reduce":    {2, 3},
+	"each":      {2, 2},
+	"find":      {2, 2},
+	"any":       {1, 2},
+	"all":       {1, 2},
+	"zip":       {2, -1},
+	"enumerate": {1, 1},
+	"flatten":   {1, 2},
+	"range":     {1, 3},
+	"sum":       {1, 1},
+	"min":       {1, -1},
+	"max":       {1, -1},
+
+	// builtins_file.go
+	"read_file":   {1, 1},
+	"read_lines":  {1, 1},
+	"write_file":  {2, 2},
+	"list_dir":    {0, 1},
+	"file_exists": {1, 1},
+
+	// builtins_json.go
+	"json_encode": {1, 2},
+	"json_decode": {1, 1},
+
+	// builtins_math.go
+	"abs":   {1, 1},
+	"pow":   {2, 2},
+	"sqrt":  {1, 1},
+	"floor": {1, 1},
+	"ceil":  {1, 1},
+	"round": {1, 1},
+	"clamp": {3, 3},
+	"sin":   {1, 1},
+	"cos":   {1, 1},
+	"tan":   {1, 1},
+	"asin":  {1, 1},
+	"acos":  {1, 1},
+	"atan":  {1, 1},
+	"atan2": {2, 2},
+	"exp":   {1, 1},
+	"log":   {1, 1},
+	"log2":  {1, 1},
+	"log10": {1, 1},
+
+	// builtins_module.go
+	"import": {1, 1},
+
+	// builtins_sort.go
+	"sort":    {1, 2},
+	"sort_by": {2, 2},
+
+	// builtins_string.go
+	"split":       {1, 2},
+	"join":        {1, 2},
+	"trim":        {1, 2},
+	"upper":       {1, 1},
+	"lower":       {1, 1},
+	"chars":       {1, 1},
+	"lines":       {1, 1},
+	"replace":     {3, 4},
+	"contains":    {2, 2},
+	"starts_with": {2, 2},
+	"ends_with":   {2, 2},
+	"index_of":    {2, 2},
+	"repeat":      {2, 2},
+	"format":      {1, -1},
+	"sprintf":     {1, -1},
+
+	// builtins_type.go
+	"type":        {1, 1},
+	"str":         {1, 1},
+	"int":         {1, 2},
+	"float":       {1, 1},
+	"bool":        {1, 1},
+	"is_int":      {1, 1},
+	"is_float":    {1, 1},
+	"is_number":   {1, 1},
+	"is_string":   {1, 1},
+	"is_bool":     {1, 1},
+	"is_array":    {1, 1},
+	"is_hash":     {1, 1},
+	"is_function": {1, 1},
+	"is_null":     {1, 1},
+}
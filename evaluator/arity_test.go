@@ -0,0 +1,76 @@
+package evaluator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ShivankSharma070/go-interpreter/object"
+)
+
+func TestBuiltinArityCoversEveryBuiltin(t *testing.T) {
+	for _, name := range BuiltinNames() {
+		if _, ok := BuiltinArity(name); !ok {
+			t.Errorf("builtin %s has no arity", name)
+		}
+	}
+	for name := range arities {
+		if _, ok := LookupBuiltin(name); !ok {
+			t.Errorf("arity given for %s, which is not a builtin", name)
+		}
+	}
+}
+
+// Calls with too few or too many arguments fail before the builtins do anything, so every builtin can be tried
+func TestBuiltinArityMatchesBuiltins(t *testing.T) {
+	call := func(name string, n int) object.Object {
+		builtin, _ := LookupBuiltin(name)
+		args := make([]object.Object, n)
+		for i := range args {
+			args[i] = NULL
+		}
+		return builtin.Fn(&object.BuiltinContext{Env: object.NewEnvironment()}, args...)
+	}
+	wrongCount := func(obj object.Object) bool {
+		err, ok := obj.(*object.Error)
+		return ok && strings.HasPrefix(err.Message, "wrong number of arguments")
+	}
+
+	for name, arity := range arities {
+		if arity.Min > 0 && !wrongCount(call(name, arity.Min-1)) {
+			t.Errorf("%s accepts %d arguments, want at least %d", name, arity.Min-1, arity.Min)
+		}
+		if arity.Max >= 0 && !wrongCount(call(name, arity.Max+1)) {
+			t.Errorf("%s accepts %d arguments, want at most %d", name, arity.Max+1, arity.Max)
+		}
+	}
+}
+
+func TestArity(t *testing.T) {
+	tests := []struct {
+		arity    Arity
+		accepts  []int
+		rejects  []int
+		expected string
+	}{
+		{Arity{1, 1}, []int{1}, []int{0, 2}, "1"},
+		{Arity{0, 1}, []int{0, 1}, []int{2}, "0 or 1"},
+		{Arity{1, 3}, []int{1, 2, 3}, []int{0, 4}, "1 to 3"},
+		{Arity{2, -1}, []int{2, 3, 10}, []int{0, 1}, "at least 2"},
+	}
+
+	for _, tt := range tests {
+		for _, n := range tt.accepts {
+			if !tt.arity.Accepts(n) {
+				t.Errorf("%v does not accept %d", tt.arity, n)
+			}
+		}
+		for _, n := range tt.rejects {
+			if tt.arity.Accepts(n) {
+				t.Errorf("%v accepts %d", tt.arity, n)
+			}
+		}
+		if tt.arity.String() != tt.expected {
+			t.Errorf("wrong string. expected=%q, got=%q", tt.expected, tt.arity.String())
+		}
+	}
+}
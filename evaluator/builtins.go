@@ -2,13 +2,12 @@ package evaluator
 
 import (
 	"github.com/ShivankSharma070/go-interpreter/object"
-	"os"
 	"fmt"
 )
 
 var builtins = map[string]*object.Builtin{
 	"len": {
-		Fn: func(args ...object.Object) object.Object {
+		Fn: func(ctx *object.BuiltinContext, args ...object.Object) object.Object {
 			if len(args) != 1 {
 				return newError("wrong number of arguments. got=%d, want=1", len(args))
 			}
@@ -25,14 +24,24 @@ var builtins = map[string]*object.Builtin{
 	},
 
 	"exit": {
-		Fn: func(args ...object.Object) object.Object {
-			os.Exit(1)
-			return NULL
+		Fn: func(ctx *object.BuiltinContext, args ...object.Object) object.Object {
+			if len(args) > 1 {
+				return newError("wrong number of arguments. got=%d, want=0 or 1", len(args))
+			}
+			// Without a code exit ends the process with status 1, like it always did
+			if len(args) == 0 {
+				return &object.Exit{Code: 1}
+			}
+			code, ok := args[0].(*object.Integer)
+			if !ok {
+				return newError("argument to `exit` must be INTEGER, got %s", args[0].Type())
+			}
+			return &object.Exit{Code: int(code.Value)}
 		},
 	},
 
 	"first": {
-		Fn: func(args ...object.Object) object.Object {
+		Fn: func(ctx *object.BuiltinContext, args ...object.Object) object.Object {
 			if len(args) != 1 {
 				return newError("wrong number of arguments. got=%d, want=1", len(args))
 			}
@@ -48,7 +57,7 @@ var builtins = map[string]*object.Builtin{
 	},
 
 	"last": {
-		Fn: func(args ...object.Object) object.Object {
+		Fn: func(ctx *object.BuiltinContext, args ...object.Object) object.Object {
 			if len(args) != 1 {
 				return newError("wrong number of arguments. got=%d, want=1", len(args))
 			}
@@ -64,7 +73,7 @@ var builtins = map[string]*object.Builtin{
 	},
 
 	"rest": {
-		Fn: func(args ...object.Object) object.Object {
+		Fn: func(ctx *object.BuiltinContext, args ...object.Object) object.Object {
 			if len(args) != 1 {
 				return newError("wrong number of arguments. got=%d, want=1", len(args))
 			}
@@ -84,7 +93,7 @@ var builtins = map[string]*object.Builtin{
 	},
 
 	"push": {
-		Fn: func(args ...object.Object) object.Object {
+		Fn: func(ctx *object.BuiltinContext, args ...object.Object) object.Object {
 			if len(args) != 2 {
 				return newError("wrong number of arguments. got=%d, want=2", len(args))
 			}
@@ -101,12 +110,19 @@ var builtins = map[string]*object.Builtin{
 		},
 	},
 	"puts": {
-		Fn: func(args ...object.Object) object.Object {
+		Fn: func(ctx *object.BuiltinContext, args ...object.Object) object.Object {
 			for _, arg := range args {
-				fmt.Println(arg.Inspect())
+				fmt.Fprintln(ctx.Stdout, arg.Inspect())
 			}
 
 			return NULL
 		},
 	},
 }
+
+// registerBuiltins adds a group of builtins to the ones available to every program
+func registerBuiltins(group map[string]*object.Builtin) {
+	for name, builtin := range group {
+		builtins[name] = builtin
+	}
+}
@@ -0,0 +1,396 @@
+package evaluator
+
+import (
+	"cmp"
+
+	"github.com/ShivankSharma070/go-interpreter/object"
+)
+
+// Longest array `range` builds, a longer one would rather be a mistake than worth the memory
+const maxRangeLength = 1 << 24
+
+// Builtins working on arrays, the ones taking a callback accept both monkey functions and builtins
+var collectionBuiltins = map[string]*object.Builtin{
+	"map": {
+		Fn: func(ctx *object.BuiltinContext, args ...object.Object) object.Object {
+			arr, fn, err := arrayAndCallback("map", args)
+			if err != nil {
+				return err
+			}
+			elements := make([]object.Object, 0, len(arr.Elements))
+			for _, el := range arr.Elements {
+				result := ctx.Apply(fn, []object.Object{el})
+				if isError(result) {
+					return result
+				}
+				elements = append(elements, result)
+			}
+			return &object.Array{Elements: elements}
+		},
+	},
+
+	"filter": {
+		Fn: func(ctx *object.BuiltinContext, args ...object.Object) object.Object {
+			arr, fn, err := arrayAndCallback("filter", args)
+			if err != nil {
+				return err
+			}
+			elements := []object.Object{}
+			for _, el := range arr.Elements {
+				result := ctx.Apply(fn, []object.Object{el})
+				if isError(result) {
+					return result
+				}
+				if isTruthy(result) {
+					elements = append(elements, el)
+				}
+			}
+			return &object.Array{Elements: elements}
+		},
+	},
+
+	"reduce": {
+		Fn: func(ctx *object.BuiltinContext, args ...object.Object) object.Object {
+			if len(args) != 2 && len(args) != 3 {
+				return newError("wrong number of arguments. got=%d, want=2 or 3", len(args))
+			}
+			arr, fn, err := arrayAndCallback("reduce", args[:2])
+			if err != nil {
+				return err
+			}
+
+			// Without an initial value the first element is used as one
+			elements := arr.Elements
+			var acc object.Object
+			if len(args) == 3 {
+				acc = args[2]
+			} else {
+				if len(elements) == 0 {
+					return NULL
+				}
+				acc = elements[0]
+				elements = elements[1:]
+			}
+
+			for _, el := range elements {
+				acc = ctx.Apply(fn, []object.Object{acc, el})
+				if isError(acc) {
+					return acc
+				}
+			}
+			return acc
+		},
+	},
+
+	"each": {
+		Fn: func(ctx *object.BuiltinContext, args ...object.Object) object.Object {
+			arr, fn, err := arrayAndCallback("each", args)
+			if err != nil {
+				return err
+			}
+			for _, el := range arr.Elements {
+				result := ctx.Apply(fn, []object.Object{el})
+				if isError(result) {
+					return result
+				}
+			}
+			return NULL
+		},
+	},
+
+	"find": {
+		Fn: func(ctx *object.BuiltinContext, args ...object.Object) object.Object {
+			arr, fn, err := arrayAndCallback("find", args)
+			if err != nil {
+				return err
+			}
+			for _, el := range arr.Elements {
+				result := ctx.Apply(fn, []object.Object{el})
+				if isError(result) {
+					return result
+				}
+				if isTruthy(result) {
+					return el
+				}
+			}
+			return NULL
+		},
+	},
+
+	"any": {
+		Fn: func(ctx *object.BuiltinContext, args ...object.Object) object.Object {
+			return matchElements(ctx, "any", args, true)
+		},
+	},
+
+	"all": {
+		Fn: func(ctx *object.BuiltinContext, args ...object.Object) object.Object {
+			return matchElements(ctx, "all", args, false)
+		},
+	},
+
+	"zip": {
+		Fn: func(ctx *object.BuiltinContext, args ...object.Object) object.Object {
+			if len(args) < 2 {
+				return newError("wrong number of arguments. got=%d, want at least 2", len(args))
+			}
+
+			// The result is as long as the shortest array
+			length := -1
+			for _, arg := range args {
+				arr, ok := arg.(*object.Array)
+				if !ok {
+					return newError("argument to `zip` must be ARRAY, got %s", arg.Type())
+				}
+				if length == -1 || len(arr.Elements) < length {
+					length = len(arr.Elements)
+				}
+			}
+
+			elements := make([]object.Object, length)
+			for i := range elements {
+				tuple := make([]object.Object, len(args))
+				for j, arg := range args {
+					tuple[j] = arg.(*object.Array).Elements[i]
+				}
+				elements[i] = &object.Array{Elements: tuple}
+			}
+			return &object.Array{Elements: elements}
+		},
+	},
+
+	"enumerate": {
+		Fn: func(ctx *object.BuiltinContext, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			arr, ok := args[0].(*object.Array)
+			if !ok {
+				return newError("argument to `enumerate` must be ARRAY, got %s", args[0].Type())
+			}
+			elements := make([]object.Object, len(arr.Elements))
+			for i, el := range arr.Elements {
+				elements[i] = &object.Array{Elements: []object.Object{&object.Integer{Value: int64(i)}, el}}
+			}
+			return &object.Array{Elements: elements}
+		},
+	},
+
+	"flatten": {
+		Fn: func(ctx *object.BuiltinContext, args ...object.Object) object.Object {
+			if len(args) != 1 && len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=1 or 2", len(args))
+			}
+			arr, ok := args[0].(*object.Array)
+			if !ok {
+				return newError("argument to `flatten` must be ARRAY, got %s", args[0].Type())
+			}
+
+			// A negative depth flattens every level of nesting
+			depth := int64(-1)
+			if len(args) == 2 {
+				d, ok := args[1].(*object.Integer)
+				if !ok {
+					return newError("depth given to `flatten` must be INTEGER, got %s", args[1].Type())
+				}
+				depth = d.Value
+			}
+			return &object.Array{Elements: flattenElements(arr.Elements, depth)}
+		},
+	},
+
+	"range": {
+		Fn: func(ctx *object.BuiltinContext, args ...object.Object) object.Object {
+			if len(args) < 1 || len(args) > 3 {
+				return newError("wrong number of arguments. got=%d, want=1 to 3", len(args))
+			}
+			bounds := make([]int64, len(args))
+			for i, arg := range args {
+				integer, ok := arg.(*object.Integer)
+				if !ok {
+					return newError("argument to `range` must be INTEGER, got %s", arg.Type())
+				}
+				bounds[i] = integer.Value
+			}
+
+			start, end, step := int64(0), bounds[0], int64(1)
+			if len(bounds) > 1 {
+				start, end = bounds[0], bounds[1]
+			}
+			if len(bounds) > 2 {
+				step = bounds[2]
+			}
+			if step == 0 {
+				return newError("step given to `range` must not be 0")
+			}
+
+			count := rangeLength(start, end, step)
+			if count > maxRangeLength {
+				return newError("array built by `range` would have more than %d elements", maxRangeLength)
+			}
+			elements := make([]object.Object, count)
+			for i := range elements {
+				elements[i] = &object.Integer{Value: start + int64(i)*step}
+			}
+			return &object.Array{Elements: elements}
+		},
+	},
+
+	"sum": {
+		Fn: func(ctx *object.BuiltinContext, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			arr, ok := args[0].(*object.Array)
+			if !ok {
+				return newError("argument to `sum` must be ARRAY, got %s", args[0].Type())
+			}
+			// The sum stays an integer unless one of the elements is a float
+			var total int64
+			var floatTotal float64
+			isFloat := false
+			for _, el := range arr.Elements {
+				switch el := el.(type) {
+				case *object.Integer:
+					total += el.Value
+					floatTotal += float64(el.Value)
+				case *object.Float:
+					floatTotal += el.Value
+					isFloat = true
+				default:
+					return newError("elements given to `sum` must be INTEGER or FLOAT, got %s", el.Type())
+				}
+			}
+			if isFloat {
+				return &object.Float{Value: floatTotal}
+			}
+			return &object.Integer{Value: total}
+		},
+	},
+
+	"min": {
+		Fn: func(ctx *object.BuiltinContext, args ...object.Object) object.Object {
+			return extremum("min", args, -1)
+		},
+	},
+
+	"max": {
+		Fn: func(ctx *object.BuiltinContext, args ...object.Object) object.Object {
+			return extremum("max", args, 1)
+		},
+	},
+}
+
+func init() {
+	registerBuiltins(collectionBuiltins)
+}
+
+// isCallable reports whether obj is a function, compiled functions of the vm count as well
+func isCallable(obj object.Object) bool {
+	return obj.Type() == object.FUNCTION_OBJ || obj.Type() == object.BUILTIN_OBJ
+}
+
+// arrayAndCallback validates the (array, function) arguments shared by most builtins in this file
+func arrayAndCallback(name string, args []object.Object) (*object.Array, object.Object, *object.Error) {
+	if len(args) != 2 {
+		return nil, nil, newError("wrong number of arguments. got=%d, want=2", len(args))
+	}
+	arr, ok := args[0].(*object.Array)
+	if !ok {
+		return nil, nil, newError("argument to `%s` must be ARRAY, got %s", name, args[0].Type())
+	}
+	if !isCallable(args[1]) {
+		return nil, nil, newError("argument to `%s` must be FUNCTION, got %s", name, args[1].Type())
+	}
+	return arr, args[1], nil
+}
+
+// matchElements implements any and all, the predicate is optional and defaults to the truthiness of each element.
+// It stops at the first element whose result is equal to stopOn.
+func matchElements(ctx *object.BuiltinContext, name string, args []object.Object, stopOn bool) object.Object {
+	var arr *object.Array
+	var fn object.Object
+	var err *object.Error
+	if len(args) == 1 {
+		var ok bool
+		if arr, ok = args[0].(*object.Array); !ok {
+			return newError("argument to `%s` must be ARRAY, got %s", name, args[0].Type())
+		}
+	} else if arr, fn, err = arrayAndCallback(name, args); err != nil {
+		return err
+	}
+
+	for _, el := range arr.Elements {
+		result := el
+		if fn != nil {
+			result = ctx.Apply(fn, []object.Object{el})
+			if isError(result) {
+				return result
+			}
+		}
+		if isTruthy(result) == stopOn {
+			return nativeBoolToBooleanObject(stopOn)
+		}
+	}
+	return nativeBoolToBooleanObject(!stopOn)
+}
+
+func flattenElements(elements []object.Object, depth int64) []object.Object {
+	result := []object.Object{}
+	for _, el := range elements {
+		if arr, ok := el.(*object.Array); ok && depth != 0 {
+			result = append(result, flattenElements(arr.Elements, depth-1)...)
+		} else {
+			result = append(result, el)
+		}
+	}
+	return result
+}
+
+// extremum implements min and max, it works on a single array or on all of its arguments.
+// sign is -1 to look for the smallest value and 1 to look for the largest.
+func extremum(name string, args []object.Object, sign int) object.Object {
+	if len(args) == 0 {
+		return newError("wrong number of arguments. got=0, want at least 1")
+	}
+	values := args
+	if len(args) == 1 {
+		arr, ok := args[0].(*object.Array)
+		if !ok {
+			return newError("argument to `%s` must be ARRAY, got %s", name, args[0].Type())
+		}
+		values = arr.Elements
+	}
+	if len(values) == 0 {
+		return NULL
+	}
+
+	best := values[0]
+	for _, value := range values[1:] {
+		switch {
+		case isNumber(value) && isNumber(best):
+			if compareNumbers(value, best) == sign {
+				best = value
+			}
+		case value.Type() == object.STRING_OBJ && best.Type() == object.STRING_OBJ:
+			if cmp.Compare(value.(*object.String).Value, best.(*object.String).Value) == sign {
+				best = value
+			}
+		default:
+			return newError("cannot compare %s with %s in `%s`", best.Type(), value.Type(), name)
+		}
+	}
+	return best
+}
+
+// rangeLength returns the number of integers from start up to end, excluded, by step. The distances are unsigned,
+// they do not overflow whatever the bounds.
+func rangeLength(start, end, step int64) uint64 {
+	switch {
+	case step > 0 && start < end:
+		return (uint64(end)-uint64(start)-1)/uint64(step) + 1
+	case step < 0 && start > end:
+		return (uint64(start)-uint64(end)-1)/(-uint64(step)) + 1
+	}
+	return 0
+}
@@ -0,0 +1,94 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/ShivankSharma070/go-interpreter/object"
+)
+
+func TestCollectionBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"map([1, 2, 3], fn(x) { x * 2 })", "[2, 4, 6]"},
+		{"map([], fn(x) { x })", "[]"},
+		{`map(["a", "bb"], len)`, "[1, 2]"},
+		{"filter([1, 2, 3, 4], fn(x) { x > 2 })", "[3, 4]"},
+		{"reduce([1, 2, 3, 4], fn(acc, x) { acc + x })", "10"},
+		{"reduce([1, 2, 3], fn(acc, x) { acc * x }, 10)", "60"},
+		{"reduce([], fn(acc, x) { acc + x })", "NULL"},
+		{"each([1, 2], fn(x) { x })", "NULL"},
+		{"find([1, 5, 10], fn(x) { x > 3 })", "5"},
+		{"find([1, 2], fn(x) { x > 3 })", "NULL"},
+		{"any([1, 5, 10], fn(x) { x > 7 })", "true"},
+		{"any([1, 5], fn(x) { x > 7 })", "false"},
+		{"any([false, 0])", "true"},
+		{"all([1, 5, 10], fn(x) { x > 0 })", "true"},
+		{"all([1, 5, 10], fn(x) { x > 1 })", "false"},
+		{"all([])", "true"},
+		{"zip([1, 2, 3], [\"a\", \"b\"])", "[[1, a], [2, b]]"},
+		{"enumerate([\"a\", \"b\"])", "[[0, a], [1, b]]"},
+		{"flatten([1, [2, [3, [4]]]])", "[1, 2, 3, 4]"},
+		{"flatten([1, [2, [3, [4]]]], 1)", "[1, 2, [3, [4]]]"},
+		{"range(4)", "[0, 1, 2, 3]"},
+		{"range(2, 5)", "[2, 3, 4]"},
+		{"range(5, 0, -2)", "[5, 3, 1]"},
+		{"range(9223372036854775806, 9223372036854775807, 2)", "[9223372036854775806]"},
+		{"range(-9223372036854775807 - 1, -9223372036854775807 - 1 + 3, 9223372036854775807)", "[-9223372036854775808]"},
+		{"range(1, -9223372036854775807 - 1, -9223372036854775807 - 1)", "[1, -9223372036854775807]"},
+		{"range(3, 3)", "[]"},
+		{"sum([1, 2, 3])", "6"},
+		{"sum([])", "0"},
+		{"min([3, 1, 2])", "1"},
+		{"max(3, 7, 2)", "7"},
+		{`min(["b", "a"])`, "a"},
+		{"max([])", "NULL"},
+		{"map([1, 2], fn(x) {})", "[NULL, NULL]"},
+		{"map([1], fn(x) { let y = x; })", "[NULL]"},
+		{"filter([1, 2], fn(x) {})", "[]"},
+		{"find([1, 2], fn(x) {})", "NULL"},
+		{"let f = fn() {}; [f(), f()]", "[NULL, NULL]"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated == nil {
+			t.Errorf("%s evaluated to nil", tt.input)
+			continue
+		}
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("%s is not %s, got %s", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
+func TestCollectionBuiltinErrors(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+	}{
+		{"map(1, fn(x) { x })", "argument to `map` must be ARRAY, got INTEGER"},
+		{"map([1], 2)", "argument to `map` must be FUNCTION, got INTEGER"},
+		{"map([1, true], fn(x) { -x })", "unknown operator: -BOOLEAN"},
+		{"filter([1], fn(a, b) { a })", "wrong number of arguments. got=1, want=2"},
+		{"reduce([1, 2], fn(acc, x) { acc + true })", "type mismatch: INTEGER + BOOLEAN"},
+		{"range(1, 5, 0)", "step given to `range` must not be 0"},
+		{"range(0, 4611686018427387904)", "array built by `range` would have more than 16777216 elements"},
+		{`sum([1, "a"])`, "elements given to `sum` must be INTEGER or FLOAT, got STRING"},
+		{`max([1, "a"])`, "cannot compare INTEGER with STRING in `max`"},
+		{"zip([1])", "wrong number of arguments. got=1, want at least 2"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		errorObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("No error object returned for %s, got %T (%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+		if errorObj.Message != tt.expectedMessage {
+			t.Errorf("ErrorObj.Message is not %s, got %s", tt.expectedMessage, errorObj.Message)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package evaluator
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+
+	"github.com/ShivankSharma070/go-interpreter/object"
+)
+
+// Builtins reading and writing files, they only work inside what the runtime's file policy allows.
+// Paths are slash separated and relative to the root of the policy, they can not go above it.
+var fileBuiltins = map[string]*object.Builtin{
+	"read_file": {
+		Fn: func(ctx *object.BuiltinContext, args ...object.Object) object.Object {
+			policy, name, err := fileArgs(ctx, "read_file", 1, args)
+			if err != nil {
+				return err
+			}
+			content, readErr := fs.ReadFile(policy.FS, name)
+			if readErr != nil {
+				return fileError("read", name, readErr)
+			}
+			return &object.String{Value: string(content)}
+		},
+	},
+
+	"read_lines": {
+		Fn: func(ctx *object.BuiltinContext, args ...object.Object) object.Object {
+			policy, name, err := fileArgs(ctx, "read_lines", 1, args)
+			if err != nil {
+				return err
+			}
+			content, readErr := fs.ReadFile(policy.FS, name)
+			if readErr != nil {
+				return fileError("read", name, readErr)
+			}
+			return stringArray(splitLines(string(content)))
+		},
+	},
+
+	"write_file": {
+		Fn: func(ctx *object.BuiltinContext, args ...object.Object) object.Object {
+			policy, name, err := fileArgs(ctx, "write_file", 2, args)
+			if err != nil {
+				return err
+			}
+			if policy.Mode != object.FilesReadWrite {
+				return newError("file access is read-only")
+			}
+			if !policy.CanWrite() {
+				return newError("files cannot be written, no root directory is configured")
+			}
+			content, ok := args[1].(*object.String)
+			if !ok {
+				return newError("argument to `write_file` must be STRING, got %s", args[1].Type())
+			}
+
+			file, openErr := policy.Root.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+			if openErr != nil {
+				return fileError("write", name, openErr)
+			}
+			_, writeErr := file.WriteString(content.Value)
+			if closeErr := file.Close(); writeErr == nil {
+				writeErr = closeErr
+			}
+			if writeErr != nil {
+				return fileError("write", name, writeErr)
+			}
+			return NULL
+		},
+	},
+
+	"list_dir": {
+		Fn: func(ctx *object.BuiltinContext, args ...object.Object) object.Object {
+			// The directory is optional and defaults to the root
+			if len(args) == 0 {
+				args = []object.Object{&object.String{Value: "."}}
+			}
+			policy, name, err := fileArgs(ctx, "list_dir", 1, args)
+			if err != nil {
+				return err
+			}
+			entries, readErr := fs.ReadDir(policy.FS, name)
+			if readErr != nil {
+				return fileError("list", name, readErr)
+			}
+			names := make([]string, len(entries))
+			for i, entry := range entries {
+				names[i] = entry.Name()
+			}
+			return stringArray(names)
+		},
+	},
+
+	"file_exists": {
+		Fn: func(ctx *object.BuiltinContext, args ...object.Object) object.Object {
+			policy, name, err := fileArgs(ctx, "file_exists", 1, args)
+			if err != nil {
+				return err
+			}
+			_, statErr := fs.Stat(policy.FS, name)
+			if errors.Is(statErr, fs.ErrNotExist) {
+				return FALSE
+			}
+			if statErr != nil {
+				return fileError("check", name, statErr)
+			}
+			return TRUE
+		},
+	},
+}
+
+func init() {
+	registerBuiltins(fileBuiltins)
+}
+
+// fileArgs checks the arguments of a file builtin, whose first one is a path, and that reading files is allowed.
+// It returns the policy of the runtime along with the cleaned path.
+func fileArgs(ctx *object.BuiltinContext, name string, want int, args []object.Object) (*object.FilePolicy, string, *object.Error) {
+	return fileArgsIn(ctx, ".", name, want, args)
+}
+
+// fileArgsIn is fileArgs for a path relative to dir, a directory below the root of the file policy
+func fileArgsIn(ctx *object.BuiltinContext, dir, name string, want int, args []object.Object) (*object.FilePolicy, string, *object.Error) {
+	if len(args) != want {
+		return nil, "", newError("wrong number of arguments. got=%d, want=%d", len(args), want)
+	}
+	policy := ctx.Env.Runtime.Files
+	if !policy.CanRead() {
+		return nil, "", newError("file access is disabled")
+	}
+	p, ok := args[0].(*object.String)
+	if !ok {
+		return nil, "", newError("argument to `%s` must be STRING, got %s", name, args[0].Type())
+	}
+
+	cleaned := path.Join(dir, p.Value)
+	if path.IsAbs(p.Value) || !fs.ValidPath(cleaned) {
+		return nil, "", newError("invalid path %q, paths must be relative and stay inside the allowed directory", p.Value)
+	}
+	return policy, cleaned, nil
+}
+
+// fileError reports a failed file operation, without repeating the path go already puts in its errors
+func fileError(action, name string, err error) *object.Error {
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		err = pathErr.Err
+	}
+	return newError("cannot %s %q: %s", action, name, err)
+}
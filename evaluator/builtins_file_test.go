@@ -0,0 +1,91 @@
+package evaluator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/ShivankSharma070/go-interpreter/lexer"
+	"github.com/ShivankSharma070/go-interpreter/object"
+	"github.com/ShivankSharma070/go-interpreter/parser"
+)
+
+func testEvalWithFiles(input string, files *object.FilePolicy) object.Object {
+	env := object.NewEnvironment()
+	env.Runtime.Files = files
+	return runProgram(parser.New(lexer.New(input)).ParseProgram(), env)
+}
+
+func TestReadOnlyFileBuiltins(t *testing.T) {
+	files := object.NewReadOnlyFilePolicy(fstest.MapFS{
+		"data/numbers.txt": {Data: []byte("1\n2\r\n3\n")},
+		"hello.txt":        {Data: []byte("hello")},
+	})
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`read_file("hello.txt")`, "hello"},
+		{`read_file("./data/../hello.txt")`, "hello"},
+		{`read_lines("data/numbers.txt")`, "[1, 2, 3]"},
+		{`list_dir()`, "[data, hello.txt]"},
+		{`list_dir("data")`, "[numbers.txt]"},
+		{`file_exists("hello.txt")`, "true"},
+		{`file_exists("missing.txt")`, "false"},
+		{`read_file("missing.txt")`, `Error: cannot read "missing.txt": file does not exist`},
+		{`read_file("../secret")`, `Error: invalid path "../secret", paths must be relative and stay inside the allowed directory`},
+		{`read_file("/etc/passwd")`, `Error: invalid path "/etc/passwd", paths must be relative and stay inside the allowed directory`},
+		{`write_file("hello.txt", "bye")`, "Error: file access is read-only"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEvalWithFiles(tt.input, files)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("%s is not %q, got %q", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+
+	// Writes need a directory on disk, a policy reading from any fs.FS has none
+	writable := &object.FilePolicy{Mode: object.FilesReadWrite, FS: files.FS}
+	expected := "Error: files cannot be written, no root directory is configured"
+	if evaluated := testEvalWithFiles(`write_file("hello.txt", "bye")`, writable); evaluated.Inspect() != expected {
+		t.Errorf("write without a root is not %q, got %q", expected, evaluated.Inspect())
+	}
+}
+
+func TestRootedFileBuiltins(t *testing.T) {
+	dir := t.TempDir()
+	files, err := object.NewRootedFilePolicy(dir, object.FilesReadWrite)
+	if err != nil {
+		t.Fatalf("NewRootedFilePolicy returned error: %s", err)
+	}
+
+	evaluated := testEvalWithFiles(`write_file("out.txt", "result"); read_file("out.txt")`, files)
+	if evaluated.Inspect() != "result" {
+		t.Errorf("read_file did not return what was written, got %q", evaluated.Inspect())
+	}
+	content, err := os.ReadFile(filepath.Join(dir, "out.txt"))
+	if err != nil || string(content) != "result" {
+		t.Errorf("file was not written on disk, got %q (%v)", content, err)
+	}
+
+	// Symlinks can not be used to escape the root either
+	if err := os.Symlink(os.TempDir(), filepath.Join(dir, "escape")); err == nil {
+		evaluated = testEvalWithFiles(`write_file("escape/x.txt", "x")`, files)
+		if _, ok := evaluated.(*object.Error); !ok {
+			t.Errorf("writing through a symlink escaping the root did not fail, got %s", evaluated.Inspect())
+		}
+	}
+}
+
+func TestFileBuiltinsDisabled(t *testing.T) {
+	for _, input := range []string{`read_file("a")`, `write_file("a", "b")`, `list_dir()`, `file_exists("a")`} {
+		evaluated := testEval(input)
+		errorObj, ok := evaluated.(*object.Error)
+		if !ok || errorObj.Message != "file access is disabled" {
+			t.Errorf("%s did not fail with disabled file access, got %s", input, evaluated.Inspect())
+		}
+	}
+}
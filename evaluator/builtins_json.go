@@ -0,0 +1,172 @@
+package evaluator
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/ShivankSharma070/go-interpreter/object"
+)
+
+// Builtins to encode values to JSON and decode them back.
+// Hashes map to objects, arrays to arrays, and NULL to null. Integers and floats are kept apart when decoding:
+// whole numbers written without a fraction or an exponent become INTEGER when they fit in one, others become FLOAT.
+// Indentations given as a number of spaces are at most that wide
+const maxJSONIndent = 64
+
+var jsonBuiltins = map[string]*object.Builtin{
+	"json_encode": {
+		Fn: func(ctx *object.BuiltinContext, args ...object.Object) object.Object {
+			if len(args) != 1 && len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=1 or 2", len(args))
+			}
+
+			// The indentation is either a number of spaces or the string to indent with
+			indent := ""
+			if len(args) == 2 {
+				switch arg := args[1].(type) {
+				case *object.Integer:
+					if arg.Value < 0 {
+						return newError("indent given to `json_encode` must not be negative, got %d", arg.Value)
+					}
+					if arg.Value > maxJSONIndent {
+						return newError("indent given to `json_encode` must be at most %d, got %d", maxJSONIndent, arg.Value)
+					}
+					indent = strings.Repeat(" ", int(arg.Value))
+				case *object.String:
+					indent = arg.Value
+				default:
+					return newError("indent given to `json_encode` must be INTEGER or STRING, got %s", arg.Type())
+				}
+			}
+
+			value, err := toJSONValue(args[0])
+			if err != nil {
+				return err
+			}
+
+			var out bytes.Buffer
+			encoder := json.NewEncoder(&out)
+			encoder.SetEscapeHTML(false)
+			encoder.SetIndent("", indent)
+			if err := encoder.Encode(value); err != nil {
+				return newError("cannot encode to JSON: %s", err)
+			}
+			return &object.String{Value: strings.TrimSuffix(out.String(), "\n")}
+		},
+	},
+
+	"json_decode": {
+		Fn: func(ctx *object.BuiltinContext, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			s, ok := args[0].(*object.String)
+			if !ok {
+				return newError("argument to `json_decode` must be STRING, got %s", args[0].Type())
+			}
+
+			decoder := json.NewDecoder(strings.NewReader(s.Value))
+			decoder.UseNumber()
+			var value any
+			if err := decoder.Decode(&value); err != nil {
+				return newError("invalid JSON: %s", err)
+			}
+			if _, err := decoder.Token(); err != io.EOF {
+				return newError("invalid JSON: unexpected data after the top-level value")
+			}
+			return fromJSONValue(value)
+		},
+	},
+}
+
+func init() {
+	registerBuiltins(jsonBuiltins)
+}
+
+// toJSONValue turns an object into a value encoding/json knows how to encode
+func toJSONValue(obj object.Object) (any, *object.Error) {
+	switch obj := obj.(type) {
+	case *object.Null, nil:
+		return nil, nil
+	case *object.Boolean:
+		return obj.Value, nil
+	case *object.Integer:
+		return obj.Value, nil
+	case *object.Float:
+		// Inspect keeps the dot of whole floats, so they decode back as floats
+		return json.Number(obj.Inspect()), nil
+	case *object.String:
+		return obj.Value, nil
+	case *object.Array:
+		elements := make([]any, len(obj.Elements))
+		for i, el := range obj.Elements {
+			value, err := toJSONValue(el)
+			if err != nil {
+				return nil, err
+			}
+			elements[i] = value
+		}
+		return elements, nil
+	case *object.Hash:
+		pairs := make(map[string]any, len(obj.Pair))
+		for _, pair := range obj.Pair {
+			key, ok := pair.Key.(*object.String)
+			if !ok {
+				return nil, newError("cannot encode to JSON: hash key must be STRING, got %s", pair.Key.Type())
+			}
+			value, err := toJSONValue(pair.Value)
+			if err != nil {
+				return nil, err
+			}
+			pairs[key.Value] = value
+		}
+		return pairs, nil
+	default:
+		return nil, newError("cannot encode to JSON: unsupported type %s", obj.Type())
+	}
+}
+
+// fromJSONValue turns a value decoded by encoding/json, with numbers kept as json.Number, into an object
+func fromJSONValue(value any) object.Object {
+	switch value := value.(type) {
+	case nil:
+		return NULL
+	case bool:
+		return nativeBoolToBooleanObject(value)
+	case json.Number:
+		if integer, err := value.Int64(); err == nil {
+			return &object.Integer{Value: integer}
+		}
+		float, err := value.Float64()
+		if err != nil {
+			return newError("invalid JSON: number %s is out of range", value)
+		}
+		return &object.Float{Value: float}
+	case string:
+		return &object.String{Value: value}
+	case []any:
+		elements := make([]object.Object, len(value))
+		for i, el := range value {
+			elements[i] = fromJSONValue(el)
+			if isError(elements[i]) {
+				return elements[i]
+			}
+		}
+		return &object.Array{Elements: elements}
+	case map[string]any:
+		pairs := make(map[object.HashKey]object.HashPair, len(value))
+		for k, v := range value {
+			key := &object.String{Value: k}
+			el := fromJSONValue(v)
+			if isError(el) {
+				return el
+			}
+			pairs[key.HashKey()] = object.HashPair{Key: key, Value: el}
+		}
+		return &object.Hash{Pair: pairs}
+	default:
+		return newError("invalid JSON: unexpected value %v", value)
+	}
+}
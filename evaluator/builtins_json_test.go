@@ -0,0 +1,98 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/ShivankSharma070/go-interpreter/lexer"
+	"github.com/ShivankSharma070/go-interpreter/object"
+	"github.com/ShivankSharma070/go-interpreter/parser"
+)
+
+func TestJSONEncode(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`json_encode(1)`, `1`},
+		{`json_encode(2.0)`, `2.0`},
+		{`json_encode("a<b")`, `"a<b"`},
+		{`json_encode(if (false) { 1 })`, `null`},
+		{`json_encode([1, true, "x"])`, `[1,true,"x"]`},
+		{`json_encode({"b": 2, "a": [1]})`, `{"a":[1],"b":2}`},
+		{`json_encode({"a": [1, 2]}, 2)`, "{\n  \"a\": [\n    1,\n    2\n  ]\n}"},
+		{`json_encode([1], "--")`, "[\n--1\n]"},
+		{`json_encode([fn() {}()])`, `[null]`},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("%s is not %q, got %q", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+
+	// Values built by Go code may hold nil, it is encoded like NULL
+	value, err := toJSONValue(&object.Array{Elements: []object.Object{nil}})
+	if err != nil {
+		t.Fatalf("encoding nil failed: %s", err.Message)
+	}
+	if elements := value.([]any); len(elements) != 1 || elements[0] != nil {
+		t.Errorf("nil is not encoded as null, got %v", value)
+	}
+}
+
+func TestJSONDecode(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`json_decode("12")`, "12"},
+		{`type(json_decode("12"))`, "INTEGER"},
+		{`type(json_decode("1.5"))`, "FLOAT"},
+		{`type(json_decode("1e3"))`, "FLOAT"},
+		{`json_decode("null")`, "NULL"},
+		{`json_decode(json_encode({"x": [1, 2.5, "y"]}))["x"]`, "[1, 2.5, y]"},
+		// Monkey strings cannot contain quotes, documents come from the environment
+		{`json_decode(array)`, "[1, a, false, NULL]"},
+		{`json_decode(nested)["a"]["b"]`, "[1]"},
+	}
+
+	for _, tt := range tests {
+		env := object.NewEnvironment()
+		env.Set("array", &object.String{Value: `[1, "a", false, null]`})
+		env.Set("nested", &object.String{Value: `{"a": {"b": [1]}}`})
+
+		evaluated := runProgram(parser.New(lexer.New(tt.input)).ParseProgram(), env)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("%s is not %q, got %q", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
+func TestJSONErrors(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+	}{
+		{`json_encode(fn(x) { x })`, "cannot encode to JSON: unsupported type FUNCTION"},
+		{`json_encode([len])`, "cannot encode to JSON: unsupported type BUILTIN"},
+		{`json_encode({1: "a"})`, "cannot encode to JSON: hash key must be STRING, got INTEGER"},
+		{`json_encode([1], 9223372036854775807)`, "indent given to `json_encode` must be at most 64, got 9223372036854775807"},
+		{`json_encode(1, true)`, "indent given to `json_encode` must be INTEGER or STRING, got BOOLEAN"},
+		{`json_decode("{")`, "invalid JSON: unexpected EOF"},
+		{`json_decode("1 2")`, "invalid JSON: unexpected data after the top-level value"},
+		{`json_decode(1)`, "argument to `json_decode` must be STRING, got INTEGER"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		errorObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("No error object returned for %s, got %T (%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+		if errorObj.Message != tt.expectedMessage {
+			t.Errorf("ErrorObj.Message is not %s, got %s", tt.expectedMessage, errorObj.Message)
+		}
+	}
+}
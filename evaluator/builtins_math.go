@@ -0,0 +1,233 @@
+package evaluator
+
+import (
+	"math"
+
+	"github.com/ShivankSharma070/go-interpreter/object"
+)
+
+// Constants available to every program, they are looked up after builtins
+var constants = map[string]object.Object{
+	"PI": &object.Float{Value: math.Pi},
+	"E":  &object.Float{Value: math.E},
+}
+
+// Builtins for math, they accept integers as well as floats.
+// Arguments outside the domain of a function are reported as errors instead of producing NaN or infinity.
+var mathBuiltins = map[string]*object.Builtin{
+	"abs": {
+		Fn: func(ctx *object.BuiltinContext, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			switch arg := args[0].(type) {
+			case *object.Integer:
+				if arg.Value == math.MinInt64 {
+					return newError("integer overflow in `abs`")
+				}
+				if arg.Value < 0 {
+					return &object.Integer{Value: -arg.Value}
+				}
+				return arg
+			case *object.Float:
+				return &object.Float{Value: math.Abs(arg.Value)}
+			default:
+				return newError("argument to `abs` must be INTEGER or FLOAT, got %s", arg.Type())
+			}
+		},
+	},
+
+	"pow": {
+		Fn: func(ctx *object.BuiltinContext, args ...object.Object) object.Object {
+			values, err := numberArgs("pow", 2, args)
+			if err != nil {
+				return err
+			}
+
+			// An integer raised to a non negative integer stays an integer
+			base, baseOk := args[0].(*object.Integer)
+			exp, expOk := args[1].(*object.Integer)
+			if baseOk && expOk && exp.Value >= 0 {
+				result, ok := intPow(base.Value, exp.Value)
+				if !ok {
+					return newError("integer overflow in `pow`")
+				}
+				return &object.Integer{Value: result}
+			}
+
+			if values[0] == 0 && values[1] < 0 {
+				return newError("domain error: `pow` of 0 to a negative power")
+			}
+			if values[0] < 0 && values[1] != math.Trunc(values[1]) {
+				return newError("domain error: `pow` of a negative number to a fractional power")
+			}
+			return floatResult("pow", math.Pow(values[0], values[1]))
+		},
+	},
+
+	"sqrt": floatFunction("sqrt", math.Sqrt, func(x float64) string {
+		if x < 0 {
+			return "negative number"
+		}
+		return ""
+	}),
+
+	"floor": roundingFunction("floor", math.Floor),
+
+	"ceil": roundingFunction("ceil", math.Ceil),
+
+	"round": roundingFunction("round", math.Round),
+
+	"clamp": {
+		Fn: func(ctx *object.BuiltinContext, args ...object.Object) object.Object {
+			if _, err := numberArgs("clamp", 3, args); err != nil {
+				return err
+			}
+			value, low, high := args[0], args[1], args[2]
+			if compareNumbers(low, high) > 0 {
+				return newError("lower bound given to `clamp` is greater than the upper bound")
+			}
+			if compareNumbers(value, low) < 0 {
+				return low
+			}
+			if compareNumbers(value, high) > 0 {
+				return high
+			}
+			return value
+		},
+	},
+
+	"sin": floatFunction("sin", math.Sin, nil),
+
+	"cos": floatFunction("cos", math.Cos, nil),
+
+	"tan": floatFunction("tan", math.Tan, nil),
+
+	"asin": floatFunction("asin", math.Asin, outsideUnitRange),
+
+	"acos": floatFunction("acos", math.Acos, outsideUnitRange),
+
+	"atan": floatFunction("atan", math.Atan, nil),
+
+	"atan2": {
+		Fn: func(ctx *object.BuiltinContext, args ...object.Object) object.Object {
+			values, err := numberArgs("atan2", 2, args)
+			if err != nil {
+				return err
+			}
+			return &object.Float{Value: math.Atan2(values[0], values[1])}
+		},
+	},
+
+	"exp": floatFunction("exp", math.Exp, nil),
+
+	"log": floatFunction("log", math.Log, notPositive),
+
+	"log2": floatFunction("log2", math.Log2, notPositive),
+
+	"log10": floatFunction("log10", math.Log10, notPositive),
+}
+
+func init() {
+	registerBuiltins(mathBuiltins)
+}
+
+// numberArgs checks the number of arguments and that every one of them is an integer or a float,
+// it returns their values as floats
+func numberArgs(name string, want int, args []object.Object) ([]float64, *object.Error) {
+	if len(args) != want {
+		return nil, newError("wrong number of arguments. got=%d, want=%d", len(args), want)
+	}
+	values := make([]float64, len(args))
+	for i, arg := range args {
+		value, ok := toFloat(arg)
+		if !ok {
+			return nil, newError("argument to `%s` must be INTEGER or FLOAT, got %s", name, arg.Type())
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+// floatFunction creates a builtin taking a single number and returning a float.
+// domainError describes why its argument is invalid, or returns an empty string for valid ones.
+func floatFunction(name string, fn func(float64) float64, domainError func(float64) string) *object.Builtin {
+	return &object.Builtin{
+		Fn: func(ctx *object.BuiltinContext, args ...object.Object) object.Object {
+			values, err := numberArgs(name, 1, args)
+			if err != nil {
+				return err
+			}
+			if domainError != nil {
+				if reason := domainError(values[0]); reason != "" {
+					return newError("domain error: `%s` of %s %s", name, reason, args[0].Inspect())
+				}
+			}
+			return floatResult(name, fn(values[0]))
+		},
+	}
+}
+
+// roundingFunction creates a builtin rounding a float to an integer, integers are returned unchanged
+func roundingFunction(name string, fn func(float64) float64) *object.Builtin {
+	return &object.Builtin{
+		Fn: func(ctx *object.BuiltinContext, args ...object.Object) object.Object {
+			values, err := numberArgs(name, 1, args)
+			if err != nil {
+				return err
+			}
+			if integer, ok := args[0].(*object.Integer); ok {
+				return integer
+			}
+			rounded := fn(values[0])
+			if math.IsNaN(rounded) || rounded < math.MinInt64 || rounded >= math.MaxInt64 {
+				return newError("`%s` of %s does not fit in an INTEGER", name, args[0].Inspect())
+			}
+			return &object.Integer{Value: int64(rounded)}
+		},
+	}
+}
+
+func floatResult(name string, value float64) object.Object {
+	if math.IsInf(value, 0) || math.IsNaN(value) {
+		return newError("result of `%s` is out of range", name)
+	}
+	return &object.Float{Value: value}
+}
+
+func outsideUnitRange(x float64) string {
+	if x < -1 || x > 1 {
+		return "number outside [-1, 1]"
+	}
+	return ""
+}
+
+func notPositive(x float64) string {
+	if x <= 0 {
+		return "non positive number"
+	}
+	return ""
+}
+
+// intPow raises base to a non negative exponent, it reports false when the result overflows
+func intPow(base, exp int64) (int64, bool) {
+	result := int64(1)
+	for exp > 0 {
+		if exp&1 == 1 {
+			next := result * base
+			if base != 0 && next/base != result {
+				return 0, false
+			}
+			result = next
+		}
+		exp >>= 1
+		if exp > 0 {
+			next := base * base
+			if base != 0 && next/base != base {
+				return 0, false
+			}
+			base = next
+		}
+	}
+	return result, true
+}
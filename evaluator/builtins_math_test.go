@@ -0,0 +1,78 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/ShivankSharma070/go-interpreter/object"
+)
+
+func TestMathBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"abs(-5)", "5"},
+		{"abs(-2.5)", "2.5"},
+		{"pow(2, 10)", "1024"},
+		{"pow(2, -1)", "0.5"},
+		{"pow(2.0, 3)", "8.0"},
+		{"sqrt(16)", "4.0"},
+		{"floor(2.7)", "2"},
+		{"ceil(2.1)", "3"},
+		{"round(2.5)", "3"},
+		{"round(-2.5)", "-3"},
+		{"floor(7)", "7"},
+		{"min(3, 1.5, 2)", "1.5"},
+		{"max([1, 2.5, 2])", "2.5"},
+		{"clamp(15, 0, 10)", "10"},
+		{"clamp(-1.5, 0, 10)", "0"},
+		{"clamp(5, 0, 10)", "5"},
+		{"sin(0)", "0.0"},
+		{"cos(0)", "1.0"},
+		{"atan2(0, 1)", "0.0"},
+		{"log(E)", "1.0"},
+		{"log10(1000)", "3.0"},
+		{"log2(8)", "3.0"},
+		{"exp(0)", "1.0"},
+		{"floor(PI * 100)", "314"},
+		{"sum([1, 2.5])", "3.5"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("%s is not %s, got %s", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
+func TestMathBuiltinErrors(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+	}{
+		{"sqrt(-1)", "domain error: `sqrt` of negative number -1"},
+		{"log(0)", "domain error: `log` of non positive number 0"},
+		{"asin(2.0)", "domain error: `asin` of number outside [-1, 1] 2.0"},
+		{"pow(0, -1)", "domain error: `pow` of 0 to a negative power"},
+		{"pow(-8, 0.5)", "domain error: `pow` of a negative number to a fractional power"},
+		{"pow(10, 30)", "integer overflow in `pow`"},
+		{"exp(1000)", "result of `exp` is out of range"},
+		{"clamp(1, 10, 0)", "lower bound given to `clamp` is greater than the upper bound"},
+		{`abs("1")`, "argument to `abs` must be INTEGER or FLOAT, got STRING"},
+		{"1 / 0", "division by zero"},
+		{"1.5 / 0", "division by zero"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		errorObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("No error object returned for %s, got %T (%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+		if errorObj.Message != tt.expectedMessage {
+			t.Errorf("ErrorObj.Message is not %s, got %s", tt.expectedMessage, errorObj.Message)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package evaluator
+
+import (
+	"errors"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/ShivankSharma070/go-interpreter/lexer"
+	"github.com/ShivankSharma070/go-interpreter/object"
+	"github.com/ShivankSharma070/go-interpreter/parser"
+)
+
+// Extension tried when an imported path does not exist as written
+const moduleExtension = ".monkey"
+
+var moduleBuiltins = map[string]*object.Builtin{
+	"import": {Fn: importBuiltin},
+}
+
+func init() {
+	registerBuiltins(moduleBuiltins)
+}
+
+// importBuiltin evaluates another file in a fresh environment and returns its exports as a module.
+// Paths are relative to the directory of the script or module the call is written in, see Environment.Dir.
+// "lib/strings" loads lib/strings or lib/strings.monkey.
+// A file is only evaluated the first time it is imported, later imports share the same module.
+func importBuiltin(ctx *object.BuiltinContext, args ...object.Object) object.Object {
+	modules := ctx.Env.Runtime.Modules
+	policy, name, err := fileArgsIn(ctx, ctx.Env.Dir, "import", 1, args)
+	if err != nil {
+		return err
+	}
+	if module, ok := modules.Get(name); ok {
+		return module
+	}
+	if module, ok := modules.Get(name + moduleExtension); ok && path.Ext(name) == "" {
+		return module
+	}
+
+	name, source, readErr := readModule(policy.FS, name)
+	if readErr != nil {
+		return fileError("import", name, readErr)
+	}
+	if module, ok := modules.Get(name); ok {
+		return module
+	}
+	if cycleErr := modules.Begin(name); cycleErr != nil {
+		return newError("%s", cycleErr)
+	}
+
+	module, result := evalModule(ctx, name, source)
+	modules.End(name, module)
+	return result
+}
+
+// readModule reads the source of a module, it returns the path the module was found at
+func readModule(fsys fs.FS, name string) (string, string, error) {
+	source, err := fs.ReadFile(fsys, name)
+	if errors.Is(err, fs.ErrNotExist) && path.Ext(name) == "" {
+		if source, extErr := fs.ReadFile(fsys, name+moduleExtension); extErr == nil {
+			return name + moduleExtension, string(source), nil
+		}
+	}
+	return name, string(source), err
+}
+
+// evalModule evaluates the source of a module, it returns the module along with the result of the import,
+// which is either the module or whatever stopped its evaluation
+func evalModule(ctx *object.BuiltinContext, name, source string) (*object.Module, object.Object) {
+	p := parser.New(lexer.New(source))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		return nil, newError("cannot import %q: %s", name, strings.Join(p.Errors(), "; "))
+	}
+
+	expanded, err := ExpandProgram(program, ctx.Env)
+	if err != nil {
+		return nil, newError("in module %q: %s", name, err.Message)
+	}
+
+	env := object.NewEnvironment()
+	env.Runtime = ctx.Env.Runtime
+	env.Dir = path.Dir(name)
+	var result object.Object
+	if evaluate := ctx.Env.Runtime.Evaluate; evaluate != nil {
+		result = evaluate(expanded, env)
+	} else {
+		result = Eval(expanded, env)
+	}
+	switch result := result.(type) {
+	case *object.Error:
+		return nil, newError("in module %q: %s", name, result.Message)
+	case *object.Exit:
+		return nil, result
+	}
+
+	module := object.NewModule(name, env)
+	return module, module
+}
@@ -0,0 +1,97 @@
+package evaluator
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/ShivankSharma070/go-interpreter/lexer"
+	"github.com/ShivankSharma070/go-interpreter/object"
+	"github.com/ShivankSharma070/go-interpreter/parser"
+)
+
+func TestImport(t *testing.T) {
+	files := object.NewReadOnlyFilePolicy(fstest.MapFS{
+		"lib/math.monkey": {Data: []byte(`
+let _helper = fn(x) { x * x };
+let square = fn(x) { _helper(x) };
+let answer = 42;
+`)},
+		"lib/uses_math.monkey":  {Data: []byte(`let m = import("math"); let cube = fn(x) { m["square"](x) * x };`)},
+		"cycle/a.monkey":        {Data: []byte(`let b = import("b");`)},
+		"cycle/b.monkey":        {Data: []byte(`let a = import("a");`)},
+		"lib/text/upper.monkey": {Data: []byte(`let twice = import("../math")["square"](2);`)},
+		"lib/lazy.monkey":       {Data: []byte(`let load = fn() { import("math")["answer"] };`)},
+		"broken.monkey":         {Data: []byte(`let x = ;`)},
+		"failing.monkey":        {Data: []byte(`let x = 1 + true;`)},
+		"exiting.monkey":        {Data: []byte(`exit(3);`)},
+	})
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`let m = import("lib/math"); m["square"](5)`, "25"},
+		{`import("lib/math.monkey")["answer"]`, "42"},
+		{`import("lib/uses_math")["cube"](3)`, "27"},
+		{`import("lib/text/upper")["twice"]`, "4"},
+		{`import("./lib/../lib/math")["answer"]`, "42"},
+		// Functions of a module import from its directory, even once it is loaded
+		{`import("lib/lazy")["load"]()`, "42"},
+		{`import("lib/math")`, `module "lib/math.monkey" {answer, square}`},
+		{`import("lib/math")["_helper"]`, `Error: module "lib/math.monkey" has no member _helper`},
+		{`import("cycle/a")`, "Error: in module \"cycle/a.monkey\": in module \"cycle/b.monkey\": import cycle: cycle/a.monkey -> cycle/b.monkey -> cycle/a.monkey"},
+		{`import("broken")`, `Error: cannot import "broken.monkey": No prefix parsing function found for ;`},
+		{`import("failing")`, `Error: in module "failing.monkey": type mismatch: INTEGER + BOOLEAN`},
+		{`import("missing")`, `Error: cannot import "missing": file does not exist`},
+		{`import("exiting")`, "exit(3)"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEvalWithFiles(tt.input, files)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("%s is not %q, got %q", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
+func TestImportFromScriptDirectory(t *testing.T) {
+	env := object.NewEnvironment()
+	env.Runtime.Files = object.NewReadOnlyFilePolicy(fstest.MapFS{
+		"lib.monkey":     {Data: []byte(`let where = "root";`)},
+		"sub/lib.monkey": {Data: []byte(`let where = "sub";`)},
+	})
+	env.Dir = "sub"
+
+	program := parser.New(lexer.New(`import("lib")["where"]`)).ParseProgram()
+	if result := runProgram(program, env); result.Inspect() != "sub" {
+		t.Errorf("import was not relative to the directory of the script, got %s", result.Inspect())
+	}
+}
+
+func TestImportCachesModules(t *testing.T) {
+	files := object.NewReadOnlyFilePolicy(fstest.MapFS{
+		"counter.monkey": {Data: []byte(`puts("evaluated"); let value = 1;`)},
+	})
+
+	env := object.NewEnvironment()
+	env.Runtime.Files = files
+	var out strings.Builder
+	env.Runtime.Stdout = &out
+
+	program := parser.New(lexer.New(`let a = import("counter"); let b = import("counter.monkey"); [a, b]`)).ParseProgram()
+	modules := runProgram(program, env).(*object.Array).Elements
+	if modules[0] != modules[1] {
+		t.Errorf("importing the same file twice returned different modules")
+	}
+
+	// The cache is checked before the file is read again
+	delete(files.FS.(fstest.MapFS), "counter.monkey")
+	program = parser.New(lexer.New(`import("counter")`)).ParseProgram()
+	if module := runProgram(program, env); module != modules[0] {
+		t.Errorf("importing a cached module read the file again, got %s", module.Inspect())
+	}
+	if out.String() != "evaluated\n" {
+		t.Errorf("module was not evaluated exactly once, output %q", out.String())
+	}
+}
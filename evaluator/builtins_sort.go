@@ -0,0 +1,204 @@
+package evaluator
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/ShivankSharma070/go-interpreter/object"
+)
+
+// Sorting never modifies the given array, like push it returns a new one.
+// All of them are stable, elements which compare equal keep their original order.
+var sortBuiltins = map[string]*object.Builtin{
+	"sort": {
+		Fn: func(ctx *object.BuiltinContext, args ...object.Object) object.Object {
+			if len(args) != 1 && len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=1 or 2", len(args))
+			}
+			arr, ok := args[0].(*object.Array)
+			if !ok {
+				return newError("argument to `sort` must be ARRAY, got %s", args[0].Type())
+			}
+
+			compare := compareObjects
+			if len(args) == 2 {
+				if !isCallable(args[1]) {
+					return newError("argument to `sort` must be FUNCTION, got %s", args[1].Type())
+				}
+				compare = func(a, b object.Object) (int, object.Object) {
+					return applyComparator(ctx, args[1], a, b)
+				}
+			}
+
+			elements := make([]object.Object, len(arr.Elements))
+			copy(elements, arr.Elements)
+			if len(args) == 1 {
+				for _, el := range elements {
+					if err := checkOrderable(el); err != nil {
+						return err
+					}
+				}
+			}
+			if err := stableSort(elements, compare); err != nil {
+				return err
+			}
+			return &object.Array{Elements: elements}
+		},
+	},
+
+	"sort_by": {
+		Fn: func(ctx *object.BuiltinContext, args ...object.Object) object.Object {
+			arr, fn, err := arrayAndCallback("sort_by", args)
+			if err != nil {
+				return err
+			}
+
+			// Every key is computed only once, before sorting
+			type keyed struct {
+				key   object.Object
+				value object.Object
+			}
+			items := make([]keyed, len(arr.Elements))
+			for i, el := range arr.Elements {
+				key := ctx.Apply(fn, []object.Object{el})
+				if isError(key) {
+					return key
+				}
+				if err := checkOrderable(key); err != nil {
+					return err
+				}
+				items[i] = keyed{key: key, value: el}
+			}
+
+			if err := stableSort(items, func(a, b keyed) (int, object.Object) {
+				return compareObjects(a.key, b.key)
+			}); err != nil {
+				return err
+			}
+
+			elements := make([]object.Object, len(items))
+			for i, item := range items {
+				elements[i] = item.value
+			}
+			return &object.Array{Elements: elements}
+		},
+	},
+}
+
+func init() {
+	registerBuiltins(sortBuiltins)
+}
+
+// stableSort sorts items in place. compare returns an error or exit signal as its second value when
+// the items cannot be ordered, the first one is returned and compare is not called anymore.
+func stableSort[T any](items []T, compare func(a, b T) (int, object.Object)) object.Object {
+	var abort object.Object
+	slices.SortStableFunc(items, func(a, b T) int {
+		if abort != nil {
+			return 0
+		}
+		result, err := compare(a, b)
+		if err != nil {
+			abort = err
+		}
+		return result
+	})
+	return abort
+}
+
+// applyComparator calls a user comparator, which must return a negative integer when a comes before b,
+// a positive one when a comes after b and 0 when they are equal.
+func applyComparator(ctx *object.BuiltinContext, fn object.Object, a, b object.Object) (int, object.Object) {
+	result := ctx.Apply(fn, []object.Object{a, b})
+	if isError(result) {
+		return 0, result
+	}
+	integer, ok := result.(*object.Integer)
+	if !ok {
+		return 0, newError("comparator given to `sort` must return INTEGER, got %s", result.Type())
+	}
+	return cmp.Compare(integer.Value, 0), nil
+}
+
+// Rank of every orderable type, values of different types are ordered by it
+var orderRank = map[object.ObjectType]int{
+	object.NULL_OBJ:    0,
+	object.BOOLEAN_OBJ: 1,
+	object.INTEGER_OBJ: 2,
+	object.FLOAT_OBJ:   2,
+	object.STRING_OBJ:  3,
+	object.ARRAY_OBJ:   4,
+}
+
+// checkOrderable returns an error when obj, or an element of it, cannot be ordered by compareObjects.
+// Sorting checks every element up front, so arrays too short to compare anything fail the same way.
+func checkOrderable(obj object.Object) *object.Error {
+	if _, ok := orderRank[obj.Type()]; !ok {
+		return newError("cannot order values of type %s", obj.Type())
+	}
+	if arr, ok := obj.(*object.Array); ok {
+		for _, el := range arr.Elements {
+			if err := checkOrderable(el); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// compareObjects defines the total ordering used by sort:
+// NULL < booleans < numbers < strings < arrays, with false < true and arrays compared element by element.
+// Other types, like functions and hashes, cannot be ordered.
+func compareObjects(a, b object.Object) (int, object.Object) {
+	rankA, ok := orderRank[a.Type()]
+	if !ok {
+		return 0, newError("cannot order values of type %s", a.Type())
+	}
+	rankB, ok := orderRank[b.Type()]
+	if !ok {
+		return 0, newError("cannot order values of type %s", b.Type())
+	}
+	if rankA != rankB {
+		return cmp.Compare(rankA, rankB), nil
+	}
+
+	switch a := a.(type) {
+	case *object.Boolean:
+		return cmp.Compare(boolRank(a.Value), boolRank(b.(*object.Boolean).Value)), nil
+	case *object.Integer, *object.Float:
+		return compareNumbers(a, b), nil
+	case *object.String:
+		return cmp.Compare(a.Value, b.(*object.String).Value), nil
+	case *object.Array:
+		other := b.(*object.Array)
+		for i := 0; i < len(a.Elements) && i < len(other.Elements); i++ {
+			result, err := compareObjects(a.Elements[i], other.Elements[i])
+			if err != nil || result != 0 {
+				return result, err
+			}
+		}
+		return cmp.Compare(len(a.Elements), len(other.Elements)), nil
+	default:
+		// NULL is only equal to itself
+		return 0, nil
+	}
+}
+
+// compareNumbers compares two integers or floats, an integer compared with a float is turned into a float
+func compareNumbers(a, b object.Object) int {
+	if a, ok := a.(*object.Integer); ok {
+		if b, ok := b.(*object.Integer); ok {
+			return cmp.Compare(a.Value, b.Value)
+		}
+	}
+	aVal, _ := toFloat(a)
+	bVal, _ := toFloat(b)
+	return cmp.Compare(aVal, bVal)
+}
+
+func boolRank(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,67 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/ShivankSharma070/go-interpreter/object"
+)
+
+func TestSortBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"sort([3, 1, 2])", "[1, 2, 3]"},
+		{`sort(["b", "c", "a"])`, "[a, b, c]"},
+		{`sort([2, "a", true, [1], 1, false])`, "[false, true, 1, 2, a, [1]]"},
+		{"sort([[1, 2], [1], [0, 5]])", "[[0, 5], [1], [1, 2]]"},
+		{"sort([])", "[]"},
+		{"let a = [3, 1]; sort(a); a", "[3, 1]"},
+		{"sort([3, 1, 2], fn(a, b) { b - a })", "[3, 2, 1]"},
+		{`sort_by(["ccc", "a", "bb"], len)`, "[a, bb, ccc]"},
+		// Stable, elements with equal keys keep their order
+		{`sort_by([[1, "x"], [0, "y"], [1, "z"], [0, "w"]], first)`, "[[0, y], [0, w], [1, x], [1, z]]"},
+		{`sort([[1, "x"], [0, "y"], [1, "z"]], fn(a, b) { first(a) - first(b) })`, "[[0, y], [1, x], [1, z]]"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("%s is not %s, got %s", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
+func TestSortErrors(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+	}{
+		{"sort(1)", "argument to `sort` must be ARRAY, got INTEGER"},
+		{"sort([1, fn(x) { x }])", "cannot order values of type FUNCTION"},
+		{"sort([fn(x) { x }])", "cannot order values of type FUNCTION"},
+		{"sort([[{}]])", "cannot order values of type HASH"},
+		{"sort_by([1], fn(x) { {} })", "cannot order values of type HASH"},
+		{"sort([3, 1, 2], fn(a, b) { a < b })", "comparator given to `sort` must return INTEGER, got BOOLEAN"},
+		{"sort([3, 1, 2], fn(a, b) { a + true })", "type mismatch: INTEGER + BOOLEAN"},
+		{"sort_by([1, 2], fn(x) { {} })", "cannot order values of type HASH"},
+		{"sort_by([1, 2], fn(x) { -true })", "unknown operator: -BOOLEAN"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		errorObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("No error object returned for %s, got %T (%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+		if errorObj.Message != tt.expectedMessage {
+			t.Errorf("ErrorObj.Message is not %s, got %s", tt.expectedMessage, errorObj.Message)
+		}
+	}
+
+	exit, ok := testEval("sort([2, 1], fn(a, b) { exit(3) })").(*object.Exit)
+	if !ok || exit.Code != 3 {
+		t.Errorf("exit inside comparator did not unwind, got %+v", exit)
+	}
+}
@@ -0,0 +1,292 @@
+package evaluator
+
+import (
+	"strings"
+
+	"github.com/ShivankSharma070/go-interpreter/object"
+)
+
+// Longest string repeat builds, a larger count is a mistake rather than a string worth the memory
+const maxStringLength = 1 << 28
+
+// Builtins working on strings. Like len, positions and lengths are counted in bytes.
+var stringBuiltins = map[string]*object.Builtin{
+	"split": {
+		Fn: func(ctx *object.BuiltinContext, args ...object.Object) object.Object {
+			if len(args) != 1 && len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=1 or 2", len(args))
+			}
+			values, err := stringArgs("split", args)
+			if err != nil {
+				return err
+			}
+
+			// Without a separator the string is split around runs of whitespace
+			var parts []string
+			if len(values) == 1 {
+				parts = strings.Fields(values[0])
+			} else {
+				parts = strings.Split(values[0], values[1])
+			}
+			return stringArray(parts)
+		},
+	},
+
+	"join": {
+		Fn: func(ctx *object.BuiltinContext, args ...object.Object) object.Object {
+			if len(args) != 1 && len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=1 or 2", len(args))
+			}
+			arr, ok := args[0].(*object.Array)
+			if !ok {
+				return newError("argument to `join` must be ARRAY, got %s", args[0].Type())
+			}
+			sep := ""
+			if len(args) == 2 {
+				s, ok := args[1].(*object.String)
+				if !ok {
+					return newError("argument to `join` must be STRING, got %s", args[1].Type())
+				}
+				sep = s.Value
+			}
+
+			parts := make([]string, len(arr.Elements))
+			for i, el := range arr.Elements {
+				parts[i] = el.Inspect()
+			}
+			return &object.String{Value: strings.Join(parts, sep)}
+		},
+	},
+
+	"trim": {
+		Fn: func(ctx *object.BuiltinContext, args ...object.Object) object.Object {
+			if len(args) != 1 && len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=1 or 2", len(args))
+			}
+			values, err := stringArgs("trim", args)
+			if err != nil {
+				return err
+			}
+			if len(values) == 2 {
+				return &object.String{Value: strings.Trim(values[0], values[1])}
+			}
+			return &object.String{Value: strings.TrimSpace(values[0])}
+		},
+	},
+
+	"upper": stringFunction("upper", func(s string) object.Object {
+		return &object.String{Value: strings.ToUpper(s)}
+	}),
+
+	"lower": stringFunction("lower", func(s string) object.Object {
+		return &object.String{Value: strings.ToLower(s)}
+	}),
+
+	"chars": stringFunction("chars", func(s string) object.Object {
+		return stringArray(strings.Split(s, ""))
+	}),
+
+	"lines": stringFunction("lines", func(s string) object.Object {
+		return stringArray(splitLines(s))
+	}),
+
+	"replace": {
+		Fn: func(ctx *object.BuiltinContext, args ...object.Object) object.Object {
+			if len(args) != 3 && len(args) != 4 {
+				return newError("wrong number of arguments. got=%d, want=3 or 4", len(args))
+			}
+			values, err := stringArgs("replace", args[:3])
+			if err != nil {
+				return err
+			}
+
+			// Every occurrence is replaced unless a count is given
+			count := int64(-1)
+			if len(args) == 4 {
+				n, ok := args[3].(*object.Integer)
+				if !ok {
+					return newError("argument to `replace` must be INTEGER, got %s", args[3].Type())
+				}
+				count = n.Value
+			}
+			return &object.String{Value: strings.Replace(values[0], values[1], values[2], int(count))}
+		},
+	},
+
+	"contains": stringPredicate("contains", strings.Contains),
+
+	"starts_with": stringPredicate("starts_with", strings.HasPrefix),
+
+	"ends_with": stringPredicate("ends_with", strings.HasSuffix),
+
+	"index_of": {
+		Fn: func(ctx *object.BuiltinContext, args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=2", len(args))
+			}
+			values, err := stringArgs("index_of", args)
+			if err != nil {
+				return err
+			}
+			return &object.Integer{Value: int64(strings.Index(values[0], values[1]))}
+		},
+	},
+
+	"repeat": {
+		Fn: func(ctx *object.BuiltinContext, args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=2", len(args))
+			}
+			s, ok := args[0].(*object.String)
+			if !ok {
+				return newError("argument to `repeat` must be STRING, got %s", args[0].Type())
+			}
+			n, ok := args[1].(*object.Integer)
+			if !ok {
+				return newError("argument to `repeat` must be INTEGER, got %s", args[1].Type())
+			}
+			if n.Value < 0 {
+				return newError("count given to `repeat` must not be negative, got %d", n.Value)
+			}
+			if len(s.Value) > 0 && n.Value > maxStringLength/int64(len(s.Value)) {
+				return newError("string built by `repeat` would be longer than %d bytes", maxStringLength)
+			}
+			return &object.String{Value: strings.Repeat(s.Value, int(n.Value))}
+		},
+	},
+
+	"format": formatFunction("format"),
+
+	"sprintf": formatFunction("sprintf"),
+}
+
+func init() {
+	registerBuiltins(stringBuiltins)
+}
+
+// stringArgs checks that every argument is a string and returns their values
+func stringArgs(name string, args []object.Object) ([]string, *object.Error) {
+	values := make([]string, len(args))
+	for i, arg := range args {
+		s, ok := arg.(*object.String)
+		if !ok {
+			return nil, newError("argument to `%s` must be STRING, got %s", name, arg.Type())
+		}
+		values[i] = s.Value
+	}
+	return values, nil
+}
+
+func stringArray(values []string) *object.Array {
+	elements := make([]object.Object, len(values))
+	for i, value := range values {
+		elements[i] = &object.String{Value: value}
+	}
+	return &object.Array{Elements: elements}
+}
+
+// splitLines splits s around \n or \r\n, a trailing line break does not produce an empty line
+func splitLines(s string) []string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	if s == "" {
+		return []string{}
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// stringFunction creates a builtin taking a single string
+func stringFunction(name string, fn func(s string) object.Object) *object.Builtin {
+	return &object.Builtin{
+		Fn: func(ctx *object.BuiltinContext, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			values, err := stringArgs(name, args)
+			if err != nil {
+				return err
+			}
+			return fn(values[0])
+		},
+	}
+}
+
+// stringPredicate creates a builtin testing a string against another one
+func stringPredicate(name string, fn func(s, other string) bool) *object.Builtin {
+	return &object.Builtin{
+		Fn: func(ctx *object.BuiltinContext, args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=2", len(args))
+			}
+			values, err := stringArgs(name, args)
+			if err != nil {
+				return err
+			}
+			return nativeBoolToBooleanObject(fn(values[0], values[1]))
+		},
+	}
+}
+
+// formatFunction creates a builtin formatting its arguments according to a format string, name is the one
+// it is called by in errors. %d takes an INTEGER, %s a STRING, %v any value and %% writes a percent sign.
+func formatFunction(name string) *object.Builtin {
+	return &object.Builtin{
+		Fn: func(ctx *object.BuiltinContext, args ...object.Object) object.Object {
+			if len(args) == 0 {
+				return newError("wrong number of arguments. got=0, want at least 1")
+			}
+			format, ok := args[0].(*object.String)
+			if !ok {
+				return newError("argument to `%s` must be STRING, got %s", name, args[0].Type())
+			}
+
+			var out strings.Builder
+			values := args[1:]
+			next := 0
+			for i := 0; i < len(format.Value); i++ {
+				ch := format.Value[i]
+				if ch != '%' {
+					out.WriteByte(ch)
+					continue
+				}
+
+				i++
+				if i == len(format.Value) {
+					return newError("format string ends with a lone %%")
+				}
+				verb := format.Value[i]
+				if verb == '%' {
+					out.WriteByte('%')
+					continue
+				}
+
+				if next == len(values) {
+					return newError("missing argument for %%%c in format string", verb)
+				}
+				value := values[next]
+				next++
+
+				switch verb {
+				case 'd':
+					if value.Type() != object.INTEGER_OBJ {
+						return newError("%%d expects INTEGER, got %s", value.Type())
+					}
+					out.WriteString(value.Inspect())
+				case 's':
+					if value.Type() != object.STRING_OBJ {
+						return newError("%%s expects STRING, got %s", value.Type())
+					}
+					out.WriteString(value.Inspect())
+				case 'v':
+					out.WriteString(value.Inspect())
+				default:
+					return newError("unknown verb %%%c in format string", verb)
+				}
+			}
+
+			if next != len(values) {
+				return newError("too many arguments for format string, got=%d, used=%d", len(values), next)
+			}
+			return &object.String{Value: out.String()}
+		},
+	}
+}
@@ -0,0 +1,77 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/ShivankSharma070/go-interpreter/object"
+)
+
+func TestStringBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`split("a,b,,c", ",")`, "[a, b, , c]"},
+		{`split("  a b  c ")`, "[a, b, c]"},
+		{`join(["a", "b", "c"], "-")`, "a-b-c"},
+		{`join([1, true, "x"])`, "1truex"},
+		{`trim("  hi  ")`, "hi"},
+		{`trim("xxhixx", "x")`, "hi"},
+		{`upper("Monkey")`, "MONKEY"},
+		{`lower("Monkey")`, "monkey"},
+		{`replace("a-b-c", "-", "+")`, "a+b+c"},
+		{`replace("a-b-c", "-", "+", 1)`, "a+b-c"},
+		{`contains("monkey", "key")`, "true"},
+		{`contains("monkey", "dog")`, "false"},
+		{`starts_with("monkey", "mon")`, "true"},
+		{`ends_with("monkey", "mon")`, "false"},
+		{`index_of("monkey", "key")`, "3"},
+		{`index_of("monkey", "dog")`, "-1"},
+		{`repeat("ab", 3)`, "ababab"},
+		{`repeat("", 9223372036854775807)`, ""},
+		{`chars("héllo")`, "[h, é, l, l, o]"},
+		{"lines(\"one\ntwo\r\nthree\n\")", "[one, two, three]"},
+		{`lines("")`, "[]"},
+		{`format("%s is %d years old", "Monkey", 5)`, "Monkey is 5 years old"},
+		{`sprintf("%v and %v, 100%%", [1, 2], true)`, "[1, 2] and true, 100%"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("%s is not %q, got %q", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
+func TestStringBuiltinErrors(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+	}{
+		{`upper(1)`, "argument to `upper` must be STRING, got INTEGER"},
+		{`split("a", 1)`, "argument to `split` must be STRING, got INTEGER"},
+		{`repeat("a", -1)`, "count given to `repeat` must not be negative, got -1"},
+		{`repeat("ab", 9223372036854775807)`, "string built by `repeat` would be longer than 268435456 bytes"},
+		{`repeat("a", 268435457)`, "string built by `repeat` would be longer than 268435456 bytes"},
+		{`format("%d", "x")`, "%d expects INTEGER, got STRING"},
+		{`format(1)`, "argument to `format` must be STRING, got INTEGER"},
+		{`sprintf(1)`, "argument to `sprintf` must be STRING, got INTEGER"},
+		{`format("%s %s", "x")`, "missing argument for %s in format string"},
+		{`format("%s", "x", "y")`, "too many arguments for format string, got=2, used=1"},
+		{`format("%x", 1)`, "unknown verb %x in format string"},
+		{`format("50%")`, "format string ends with a lone %"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		errorObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("No error object returned for %s, got %T (%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+		if errorObj.Message != tt.expectedMessage {
+			t.Errorf("ErrorObj.Message is not %s, got %s", tt.expectedMessage, errorObj.Message)
+		}
+	}
+}
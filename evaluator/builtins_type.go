@@ -0,0 +1,155 @@
+package evaluator
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/ShivankSharma070/go-interpreter/object"
+)
+
+// Builtins to inspect the type of a value and to convert values from one type to another
+var typeBuiltins = map[string]*object.Builtin{
+	"type": {
+		Fn: func(ctx *object.BuiltinContext, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			return &object.String{Value: string(args[0].Type())}
+		},
+	},
+
+	"str": {
+		Fn: func(ctx *object.BuiltinContext, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			if s, ok := args[0].(*object.String); ok {
+				return s
+			}
+			return &object.String{Value: args[0].Inspect()}
+		},
+	},
+
+	"int": {
+		Fn: func(ctx *object.BuiltinContext, args ...object.Object) object.Object {
+			if len(args) != 1 && len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=1 or 2", len(args))
+			}
+			if len(args) == 2 {
+				return parseIntWithBase(args[0], args[1])
+			}
+
+			switch arg := args[0].(type) {
+			case *object.Integer:
+				return arg
+			case *object.Float:
+				if math.IsNaN(arg.Value) || arg.Value < math.MinInt64 || arg.Value >= math.MaxInt64 {
+					return newError("cannot convert %s to INTEGER: out of range", arg.Inspect())
+				}
+				return &object.Integer{Value: int64(arg.Value)}
+			case *object.Boolean:
+				if arg.Value {
+					return &object.Integer{Value: 1}
+				}
+				return &object.Integer{Value: 0}
+			case *object.String:
+				return parseIntWithBase(arg, &object.Integer{Value: 10})
+			default:
+				return newError("cannot convert %s to INTEGER", arg.Type())
+			}
+		},
+	},
+
+	"float": {
+		Fn: func(ctx *object.BuiltinContext, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			switch arg := args[0].(type) {
+			case *object.Float:
+				return arg
+			case *object.Integer:
+				return &object.Float{Value: float64(arg.Value)}
+			case *object.Boolean:
+				if arg.Value {
+					return &object.Float{Value: 1}
+				}
+				return &object.Float{Value: 0}
+			case *object.String:
+				value, err := strconv.ParseFloat(strings.TrimSpace(arg.Value), 64)
+				if err != nil {
+					return newError("cannot convert %q to FLOAT", arg.Value)
+				}
+				return &object.Float{Value: value}
+			default:
+				return newError("cannot convert %s to FLOAT", arg.Type())
+			}
+		},
+	},
+
+	// bool follows the same rules as conditions, only false and NULL are falsy
+	"bool": {
+		Fn: func(ctx *object.BuiltinContext, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			return nativeBoolToBooleanObject(isTruthy(args[0]))
+		},
+	},
+
+	"is_int":      typePredicate(object.INTEGER_OBJ),
+	"is_float":    typePredicate(object.FLOAT_OBJ),
+	"is_number":   typePredicate(object.INTEGER_OBJ, object.FLOAT_OBJ),
+	"is_string":   typePredicate(object.STRING_OBJ),
+	"is_bool":     typePredicate(object.BOOLEAN_OBJ),
+	"is_array":    typePredicate(object.ARRAY_OBJ),
+	"is_hash":     typePredicate(object.HASH_OBJ),
+	"is_null":     typePredicate(object.NULL_OBJ),
+	"is_function": typePredicate(object.FUNCTION_OBJ, object.BUILTIN_OBJ),
+}
+
+func init() {
+	registerBuiltins(typeBuiltins)
+}
+
+// typePredicate creates a builtin telling if its argument is of one of the given types
+func typePredicate(types ...object.ObjectType) *object.Builtin {
+	return &object.Builtin{
+		Fn: func(ctx *object.BuiltinContext, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			for _, t := range types {
+				if args[0].Type() == t {
+					return TRUE
+				}
+			}
+			return FALSE
+		},
+	}
+}
+
+// parseIntWithBase parses a string as an integer written in base, a base of 0 guesses it from the prefix (0x, 0o, 0b)
+func parseIntWithBase(value, base object.Object) object.Object {
+	s, ok := value.(*object.String)
+	if !ok {
+		return newError("cannot convert %s to INTEGER with a base, want STRING", value.Type())
+	}
+	b, ok := base.(*object.Integer)
+	if !ok {
+		return newError("base given to `int` must be INTEGER, got %s", base.Type())
+	}
+	if b.Value != 0 && (b.Value < 2 || b.Value > 36) {
+		return newError("base given to `int` must be 0 or between 2 and 36, got %d", b.Value)
+	}
+
+	result, err := strconv.ParseInt(strings.TrimSpace(s.Value), int(b.Value), 64)
+	if err != nil {
+		if numErr, ok := err.(*strconv.NumError); ok && numErr.Err == strconv.ErrRange {
+			return newError("cannot convert %q to INTEGER: out of range", s.Value)
+		}
+		return newError("cannot convert %q to INTEGER in base %d", s.Value, b.Value)
+	}
+	return &object.Integer{Value: result}
+}
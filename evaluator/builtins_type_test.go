@@ -0,0 +1,90 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/ShivankSharma070/go-interpreter/object"
+)
+
+func TestTypeBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"type(1)", "INTEGER"},
+		{"type(1.5)", "FLOAT"},
+		{`type("a")`, "STRING"},
+		{"type([])", "ARRAY"},
+		{"type({})", "HASH"},
+		{"type(fn() {})", "FUNCTION"},
+		{"type(len)", "BUILTIN"},
+		{"type(if (false) { 1 })", "NULL"},
+		{"type(fn() {}())", "NULL"},
+		{"type(if (true) {})", "NULL"},
+		{"str(fn() { let x = 1; }())", "NULL"},
+		{"[if (true) {}]", "[NULL]"},
+		{"let f = fn() { let x = if (true) {}; type(x) }; f()", "NULL"},
+		{"let f = fn() { let x = fn() {}(); [x] }; f()", "[NULL]"},
+		{"str(12)", "12"},
+		{"str([1, true])", "[1, true]"},
+		{`str("a")`, "a"},
+		{`int("42")`, "42"},
+		{`int(" -7 ")`, "-7"},
+		{`int("ff", 16)`, "255"},
+		{`int("0b101", 0)`, "5"},
+		{"int(3.9)", "3"},
+		{"int(true)", "1"},
+		{`float("2.5")`, "2.5"},
+		{"float(2)", "2.0"},
+		{"bool(0)", "true"},
+		{`bool("")`, "true"},
+		{"bool(if (false) { 1 })", "false"},
+		{"bool(false)", "false"},
+		{"is_int(1)", "true"},
+		{"is_int(1.0)", "false"},
+		{"is_number(1.0)", "true"},
+		{`is_string("a")`, "true"},
+		{"is_bool(true)", "true"},
+		{"is_array([])", "true"},
+		{"is_hash({})", "true"},
+		{"is_null(puts())", "true"},
+		{"is_function(len)", "true"},
+		{"is_function(fn(x) { x })", "true"},
+		{"is_function(1)", "false"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("%s is not %s, got %s", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
+func TestConversionErrors(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+	}{
+		{`int("abc")`, `cannot convert "abc" to INTEGER in base 10`},
+		{`int("12", 2)`, `cannot convert "12" to INTEGER in base 2`},
+		{`int("99999999999999999999")`, `cannot convert "99999999999999999999" to INTEGER: out of range`},
+		{`int("1", 40)`, "base given to `int` must be 0 or between 2 and 36, got 40"},
+		{`int(12, 16)`, "cannot convert INTEGER to INTEGER with a base, want STRING"},
+		{`int([1])`, "cannot convert ARRAY to INTEGER"},
+		{`float("x")`, `cannot convert "x" to FLOAT`},
+		{`float({})`, "cannot convert HASH to FLOAT"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		errorObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("No error object returned for %s, got %T (%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+		if errorObj.Message != tt.expectedMessage {
+			t.Errorf("ErrorObj.Message is not %s, got %s", tt.expectedMessage, errorObj.Message)
+		}
+	}
+}
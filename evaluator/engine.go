@@ -0,0 +1,79 @@
+package evaluator
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/ShivankSharma070/go-interpreter/ast"
+	"github.com/ShivankSharma070/go-interpreter/object"
+)
+
+// The bytecode vm runs programs with the operators and builtins defined here,
+// so both engines implement the same language and report the same errors.
+
+// InfixOperation applies an infix operator to two values
+func InfixOperation(operator string, left, right object.Object) object.Object {
+	return evalInfixExpression(operator, right, left)
+}
+
+// PrefixOperation applies a prefix operator to a value
+func PrefixOperation(operator string, right object.Object) object.Object {
+	return evalPrefixExpression(operator, right)
+}
+
+// IndexOperation evaluates left[index]
+func IndexOperation(left, index object.Object) object.Object {
+	return evalIndexExpression(left, index)
+}
+
+// MemberOperation evaluates obj.name
+func MemberOperation(obj object.Object, name string) object.Object {
+	return evalMemberExpression(obj, name)
+}
+
+// IsTruthy reports whether a condition holding obj is met
+func IsTruthy(obj object.Object) bool {
+	return isTruthy(obj)
+}
+
+// LookupBuiltin returns the builtin called name
+func LookupBuiltin(name string) (*object.Builtin, bool) {
+	builtin, ok := builtins[name]
+	return builtin, ok
+}
+
+// BuiltinNames returns the names of every builtin, sorted. The compiler refers to builtins by their index in it.
+func BuiltinNames() []string {
+	return sortedBuiltinNames()
+}
+
+// Builtins are all registered by init functions, so the names can be computed once
+var sortedBuiltinNames = sync.OnceValue(func() []string {
+	names := make([]string, 0, len(builtins))
+	for name := range builtins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+})
+
+// LookupConstant returns the predefined constant called name, like PI
+func LookupConstant(name string) (object.Object, bool) {
+	constant, ok := constants[name]
+	return constant, ok
+}
+
+// ConstantNames returns the names of every predefined constant, sorted
+func ConstantNames() []string {
+	names := make([]string, 0, len(constants))
+	for name := range constants {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// UnquoteNode turns the value of an unquote(...) call into the code it stands for
+func UnquoteNode(obj object.Object) (ast.Node, *object.Error) {
+	return convertObjectToASTNode(obj)
+}
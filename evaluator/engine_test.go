@@ -0,0 +1,22 @@
+package evaluator_test
+
+import (
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/ShivankSharma070/go-interpreter/evaluator"
+	"github.com/ShivankSharma070/go-interpreter/vm"
+)
+
+// TestMain runs the tests of the evaluator a second time with the bytecode vm, both engines must agree on every program
+func TestMain(m *testing.M) {
+	if code := m.Run(); code != 0 {
+		os.Exit(code)
+	}
+
+	// Only the evaluator keeps the ast of the functions it creates
+	flag.Set("test.skip", "^TestFunctionExpression$")
+	evaluator.RunTestsWith(vm.Eval)
+	os.Exit(m.Run())
+}
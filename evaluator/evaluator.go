@@ -4,15 +4,30 @@ import (
 	"fmt"
 	"github.com/ShivankSharma070/go-interpreter/ast"
 	"github.com/ShivankSharma070/go-interpreter/object"
+	"github.com/ShivankSharma070/go-interpreter/token"
 )
 
 var (
-	NULL  = &object.Null{}
-	TRUE  = &object.Boolean{Value: true}
-	FALSE = &object.Boolean{Value: false}
+	NULL  = object.NULL
+	TRUE  = object.TRUE
+	FALSE = object.FALSE
 )
 
+// Eval evaluates node in env, telling the hooks of the runtime of env about it if there are any
 func Eval(node ast.Node, env *object.Environment) object.Object {
+	hooks := &env.Runtime.Hooks
+	if hooks.Len() == 0 {
+		return eval(node, env)
+	}
+	if result := hooks.Enter(node, env); result != nil {
+		return result
+	}
+	result := eval(node, env)
+	hooks.Exit(node, env, result)
+	return result
+}
+
+func eval(node ast.Node, env *object.Environment) object.Object {
 	switch node := node.(type) {
 	case *ast.Program:
 		return evalProgram(node, env)
@@ -20,6 +35,8 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		return Eval(node.Expression, env)
 	case *ast.IntegerLiteral:
 		return &object.Integer{Value: node.Value}
+	case *ast.FloatLiteral:
+		return &object.Float{Value: node.Value}
 	case *ast.StringLiteral:
 		return &object.String{Value: node.Value}
 	case *ast.BoolExpression:
@@ -57,14 +74,30 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		if isError(val) {
 			return val
 		}
-		env.Set(node.Name.Value, val)
+		if node.Name.Local {
+			// nil marks the slots whose let did not run, a value without one is null
+			if val == nil {
+				val = NULL
+			}
+			env.Slots[node.Name.Slot] = val
+		} else {
+			env.Set(node.Name.Value, val)
+		}
 	case *ast.Identifier:
 		return evalIdentifier(node, env)
 	case *ast.FunctionExpression:
 		params := node.Parameters
 		body := node.Body
-		return &object.FunctionLiteral{Parameters: params, Body: body, Env: env}
+		return &object.FunctionLiteral{Parameters: params, Body: body, NumLocals: node.NumLocals, Env: env}
+	case *ast.MacroLiteral:
+		return newError("macro literals can only be bound by a top level let statement")
 	case *ast.CallExpression:
+		if iden, ok := node.Function.(*ast.Identifier); ok && iden.Value == "quote" {
+			if len(node.Argument) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(node.Argument))
+			}
+			return quote(node.Argument[0], env)
+		}
 		function := Eval(node.Function, env)
 		if isError(function) {
 			return function
@@ -73,7 +106,7 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		if len(args) == 1 && isError(args[0]) {
 			return args[0]
 		}
-		return applyFunction(function, args)
+		return applyFunction(function, args, env, node.Token.Pos)
 	case *ast.ArrayLiteral:
 		elements := evalExpressions(node.Elements, env)
 		if len(elements) == 1 && isError(elements[0]) {
@@ -91,6 +124,12 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 			return index
 		}
 		return evalIndexExpression(left, index)
+	case *ast.MemberExpression:
+		obj := Eval(node.Object, env)
+		if isError(obj) {
+			return obj
+		}
+		return evalMemberExpression(obj, node.Property.Value)
 	case *ast.HashLiteral:
 		return evalHashLiteral(node, env)
 	}
@@ -110,6 +149,9 @@ func evalHashLiteral(node *ast.HashLiteral, env *object.Environment) object.Obje
 		}
 
 		value := Eval(valueNode, env)
+		if isError(value) {
+			return value
+		}
 		pairs[hashKey.HashKey()] = object.HashPair{Key: key, Value: value}
 	}
 
@@ -122,6 +164,8 @@ func evalIndexExpression(left, index object.Object) object.Object {
 		return evalArrayIndexExpression(left, index)
 	case left.Type() == object.HASH_OBJ:
 		return evalHashIndexExpression(left, index)
+	case left.Type() == object.MODULE_OBJ && index.Type() == object.STRING_OBJ:
+		return evalModuleMember(left.(*object.Module), index.(*object.String).Value)
 	default:
 		return newError("index operator not supported: %s", left.Type())
 	}
@@ -142,6 +186,14 @@ func evalHashIndexExpression(left, index object.Object) object.Object {
 	return pair.Value
 }
 
+func evalModuleMember(module *object.Module, name string) object.Object {
+	member, ok := module.Exports[name]
+	if !ok {
+		return newError("module %q has no member %s", module.Path, name)
+	}
+	return member
+}
+
 func evalArrayIndexExpression(left, index object.Object) object.Object {
 	arrayObj := left.(*object.Array)
 	idx := index.(*object.Integer).Value
@@ -162,32 +214,67 @@ func evalExpressions(args []ast.Expression, env *object.Environment) []object.Ob
 		if evaluated != nil && isError(evaluated) {
 			return []object.Object{evaluated}
 		}
+		// Blocks without a value, like the one of if (true) {}, are null as arguments and elements
+		if evaluated == nil {
+			evaluated = NULL
+		}
 		result = append(result, evaluated)
 
 	}
 	return result
 }
 
-func applyFunction(fn object.Object, args []object.Object) object.Object {
+// applyFunction calls fn with args, env and pos describe the call site and are handed to builtins
+func applyFunction(fn object.Object, args []object.Object, env *object.Environment, pos token.Position) object.Object {
 	switch fn := fn.(type) {
 	case *object.FunctionLiteral:
-		extendedEnv := extendFunctionEnv(fn, args)
-		evaluated := Eval(fn.Body, extendedEnv)
-		return unwrapReturnValue(evaluated)
+		extendedEnv, err := extendFunctionEnv(fn, args)
+		if err != nil {
+			return err
+		}
+		env.Runtime.Hooks.Call(fn, args, extendedEnv, pos)
+		evaluated := unwrapReturnValue(Eval(fn.Body, extendedEnv))
+		// A body without a value, like an empty one or one ending with a let, returns null
+		if evaluated == nil {
+			evaluated = NULL
+		}
+		env.Runtime.Hooks.Return(fn, evaluated)
+		return evaluated
 	case *object.Builtin:
-		return fn.Fn(args...)
+		env.Runtime.Hooks.Call(fn, args, env, pos)
+		result := fn.Fn(newBuiltinContext(env, pos), args...)
+		if result == nil {
+			result = NULL
+		}
+		env.Runtime.Hooks.Return(fn, result)
+		return result
 	default:
 		return newError("not a function: %s", fn.Type())
 	}
 
 }
 
-func extendFunctionEnv(fn *object.FunctionLiteral, args []object.Object) *object.Environment {
-	env := object.NewEnclosingEnvironment(fn.Env)
-	for paramIdx, name := range fn.Parameters {
-		env.Set(name.Value, args[paramIdx])
+func newBuiltinContext(env *object.Environment, pos token.Position) *object.BuiltinContext {
+	return &object.BuiltinContext{
+		Env:    env,
+		Pos:    pos,
+		Stdout: env.Runtime.Stdout,
+		Stderr: env.Runtime.Stderr,
+		Apply: func(fn object.Object, args []object.Object) object.Object {
+			return applyFunction(fn, args, env, pos)
+		},
 	}
-	return env
+}
+
+func extendFunctionEnv(fn *object.FunctionLiteral, args []object.Object) (*object.Environment, *object.Error) {
+	if len(args) != len(fn.Parameters) {
+		return nil, newError("wrong number of arguments. got=%d, want=%d", len(args), len(fn.Parameters))
+	}
+
+	// Parameters take the first slots
+	env := object.NewFrameEnvironment(fn.Env, max(fn.NumLocals, len(args)))
+	copy(env.Slots, args)
+	return env, nil
 }
 
 func unwrapReturnValue(obj object.Object) object.Object {
@@ -198,6 +285,14 @@ func unwrapReturnValue(obj object.Object) object.Object {
 }
 
 func evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object {
+	if node.Local {
+		// A let of the function that was skipped, like one in the branch of an if that did not run
+		if val := env.GetLocal(node.Depth, node.Slot); val != nil {
+			return val
+		}
+		return newError("identifier not found: %s", node.Value)
+	}
+
 	if val, ok := env.Get(node.Value); ok {
 		return val
 	}
@@ -206,6 +301,10 @@ func evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object
 		return builtin
 	}
 
+	if constant, ok := constants[node.Value]; ok {
+		return constant
+	}
+
 	return newError("identifier not found: %s", node.Value)
 }
 
@@ -237,12 +336,14 @@ func evalPrefixExpression(operator string, value object.Object) object.Object {
 
 // Evaluate expresions with minus as prefix operators
 func evalMinusPrefixOperatorExpression(value object.Object) object.Object {
-	if value.Type() != object.INTEGER_OBJ {
+	switch value := value.(type) {
+	case *object.Integer:
+		return &object.Integer{Value: -value.Value}
+	case *object.Float:
+		return &object.Float{Value: -value.Value}
+	default:
 		return newError("unknown operator: -%s", value.Type())
 	}
-
-	int_value := value.(*object.Integer).Value
-	return &object.Integer{Value: -int_value}
 }
 
 // Evaluate bang prefix operations
@@ -264,6 +365,9 @@ func evalInfixExpression(operator string, right, left object.Object) object.Obje
 	switch {
 	case right.Type() == object.INTEGER_OBJ && left.Type() == object.INTEGER_OBJ:
 		return evalIntegerInfixExpression(operator, right, left)
+	case isNumber(right) && isNumber(left):
+		// Mixing a float with an integer turns the integer into a float
+		return evalFloatInfixExpression(operator, right, left)
 	case right.Type() == object.STRING_OBJ && left.Type() == object.STRING_OBJ:
 		return evalStringInfixExpression(operator, right, left)
 	case right.Type() == object.BOOLEAN_OBJ && left.Type() == object.BOOLEAN_OBJ:
@@ -306,6 +410,9 @@ func evalIntegerInfixExpression(operator string, right, left object.Object) obje
 	case "*":
 		return &object.Integer{Value: leftVal * rightVal}
 	case "/":
+		if rightVal == 0 {
+			return newError("division by zero")
+		}
 		return &object.Integer{Value: leftVal / rightVal}
 	case "-":
 		return &object.Integer{Value: leftVal - rightVal}
@@ -323,13 +430,57 @@ func evalIntegerInfixExpression(operator string, right, left object.Object) obje
 
 }
 
+func evalFloatInfixExpression(operator string, right, left object.Object) object.Object {
+	rightVal, _ := toFloat(right)
+	leftVal, _ := toFloat(left)
+	switch operator {
+	case "+":
+		return &object.Float{Value: leftVal + rightVal}
+	case "*":
+		return &object.Float{Value: leftVal * rightVal}
+	case "/":
+		if rightVal == 0 {
+			return newError("division by zero")
+		}
+		return &object.Float{Value: leftVal / rightVal}
+	case "-":
+		return &object.Float{Value: leftVal - rightVal}
+	case "<":
+		return nativeBoolToBooleanObject(leftVal < rightVal)
+	case ">":
+		return nativeBoolToBooleanObject(leftVal > rightVal)
+	case "==":
+		return nativeBoolToBooleanObject(leftVal == rightVal)
+	case "!=":
+		return nativeBoolToBooleanObject(leftVal != rightVal)
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+func isNumber(obj object.Object) bool {
+	return obj.Type() == object.INTEGER_OBJ || obj.Type() == object.FLOAT_OBJ
+}
+
+// toFloat returns the value of an integer or a float as a float64
+func toFloat(obj object.Object) (float64, bool) {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		return float64(obj.Value), true
+	case *object.Float:
+		return obj.Value, true
+	default:
+		return 0, false
+	}
+}
+
 func evalBlockStatement(stmts []ast.Statement, env *object.Environment) object.Object {
 	var result object.Object
 
 	for _, stmt := range stmts {
 		result = Eval(stmt, env)
 		if result != nil {
-			if result.Type() == object.RETURN_VALUE_OBJ || result.Type() == object.ERROR_OBJ {
+			if result.Type() == object.RETURN_VALUE_OBJ || isError(result) {
 				return result
 			}
 		}
@@ -339,6 +490,10 @@ func evalBlockStatement(stmts []ast.Statement, env *object.Environment) object.O
 }
 
 func evalProgram(node *ast.Program, env *object.Environment) object.Object {
+	if err := Resolve(node, env); err != nil {
+		return err
+	}
+
 	var result object.Object
 	for _, stmt := range node.Statements {
 		result = Eval(stmt, env)
@@ -348,6 +503,8 @@ func evalProgram(node *ast.Program, env *object.Environment) object.Object {
 			return result.Value
 		case *object.Error:
 			return result
+		case *object.Exit:
+			return result
 		}
 	}
 
@@ -378,9 +535,10 @@ func newError(format string, a ...any) *object.Error {
 	return &object.Error{Message: fmt.Sprintf(format, a...)}
 }
 
+// isError reports whether obj stops the evaluation, exit signals unwind the same way errors do
 func isError(obj object.Object) bool {
 	if obj != nil {
-		return obj.Type() == object.ERROR_OBJ
+		return obj.Type() == object.ERROR_OBJ || obj.Type() == object.EXIT_OBJ
 	}
 	return false
 }
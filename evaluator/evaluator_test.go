@@ -1,11 +1,14 @@
 package evaluator
 
 import (
+	"bytes"
 	"testing"
 
+	"github.com/ShivankSharma070/go-interpreter/ast"
 	"github.com/ShivankSharma070/go-interpreter/lexer"
 	"github.com/ShivankSharma070/go-interpreter/object"
 	"github.com/ShivankSharma070/go-interpreter/parser"
+	"github.com/ShivankSharma070/go-interpreter/token"
 )
 
 // ========= INTEGER ============
@@ -52,6 +55,37 @@ func testIntegerObject(t *testing.T, obj object.Object, expected int64) bool {
 	return true
 }
 
+// ========= FLOAT ============
+func TestEvalFloatExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected float64
+	}{
+		{"2.5", 2.5},
+		{"-2.5", -2.5},
+		{"1.5 + 1.5", 3},
+		{"1 + 0.5", 1.5},
+		{"0.5 * 4", 2},
+		{"7 / 2.0", 3.5},
+		{"10 - 0.25", 9.75},
+	}
+
+	for _, tt := range tests {
+		output := testEval(tt.input)
+		result, ok := output.(*object.Float)
+		if !ok {
+			t.Fatalf("Object not of type object.Float, got %T (%+v)", output, output)
+		}
+		if result.Value != tt.expected {
+			t.Errorf("result.Value is not %f, got %f", tt.expected, result.Value)
+		}
+	}
+
+	testBooleanObject(t, testEval("1.5 < 2"), true)
+	testBooleanObject(t, testEval("2.0 == 2"), true)
+	testBooleanObject(t, testEval("2.5 != 2.5"), false)
+}
+
 // ======== BOOLEAN ==========
 func TestEvalBooleanExpresion(t *testing.T) {
 	tests := []struct {
@@ -268,6 +302,36 @@ func TestClousers(t *testing.T) {
 	testIntegerObject(t, testEval(input), 4)
 }
 
+func TestClosuresShareLocals(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected any
+	}{
+		// Locals of a function can be used by the functions defined before them
+		{`let check = fn(x) {
+			let isEven = fn(n) { if (n == 0) { true } else { isOdd(n - 1) } };
+			let isOdd = fn(n) { if (n == 0) { false } else { isEven(n - 1) } };
+			isEven(x)
+		};
+		check(10)`, true},
+		// Closures see the locals they use as they are when they are called
+		{"let f = fn() { let x = 1; let get = fn() { x }; let x = 2; get() }; f()", 2},
+		{"let f = fn(x) { let get = fn() { fn() { x } }; let x = x + 1; get()() }; f(1)", 2},
+		{"let f = fn() { let n = 0; let inc = fn() { let m = n + 1; m }; let n = 5; inc() }; f()", 6},
+		{"let f = fn() { let g = fn() { g }; let h = g; let g = 1; h() }; f()", 1},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		}
+	}
+}
+
 // =============== ARRAY ====================
 func TestArrayLiteral(t *testing.T) {
 	input := "[1, 2*2, 3+3]"
@@ -438,6 +502,7 @@ func TestBuiltInFunction(t *testing.T) {
 		{`len("hello world")`, 11},
 		{`len(1)`, "argument to `len` not supported, got INTEGER"},
 		{`len("one", "two")`, "wrong number of arguments. got=2, want=1"},
+		{`exit("now")`, "argument to `exit` must be INTEGER, got STRING"},
 	}
 
 	for _, tt := range tests {
@@ -460,6 +525,61 @@ func TestBuiltInFunction(t *testing.T) {
 
 }
 
+func TestBuiltinContext(t *testing.T) {
+	var out bytes.Buffer
+	env := object.NewEnvironment()
+	env.Runtime.Stdout = &out
+
+	var pos token.Position
+	env.Set("twice", &object.Builtin{
+		Fn: func(ctx *object.BuiltinContext, args ...object.Object) object.Object {
+			pos = ctx.Pos
+			once := ctx.Apply(args[0], []object.Object{args[1]})
+			return ctx.Apply(args[0], []object.Object{once})
+		},
+	})
+
+	input := `puts("hello");
+let double = fn(x) { x * 2 };
+twice(double, 3)`
+	program := parser.New(lexer.New(input)).ParseProgram()
+	testIntegerObject(t, runProgram(program, env), 12)
+
+	if out.String() != "hello\n" {
+		t.Errorf("puts did not write to runtime stdout, got %q", out.String())
+	}
+	if pos != (token.Position{Line: 3, Column: 6}) {
+		t.Errorf("ctx.Pos is not 3:6, got %s", pos)
+	}
+}
+
+func TestExitBuiltin(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int
+	}{
+		{"exit()", 1},
+		{"exit(3)", 3},
+		{"exit(2); 5", 2},
+		{"let f = fn() { if (true) { exit(4) } return 1 }; f() + 10", 4},
+		{"[1, exit(5), 3]", 5},
+		{`{"a": exit(6)}`, 6},
+		{"puts(exit(7))", 7},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		exit, ok := evaluated.(*object.Exit)
+		if !ok {
+			t.Errorf("evaluated object is not object.Exit for %q, got %T (%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+		if exit.Code != tt.expected {
+			t.Errorf("exit.Code is not %d, got %d", tt.expected, exit.Code)
+		}
+	}
+}
+
 // =============== Errors ====================
 func TestErrors(t *testing.T) {
 	tests := []struct {
@@ -508,6 +628,10 @@ return 1;
 		{
 			"foobar", "identifier not found: foobar",
 		},
+		{
+			"let f = fn() { let g = fn() { x }; let r = g(); let x = 1; r }; f()",
+			"identifier not found: x",
+		},
 		{
 			`{"name": "Monkey"}[fn(x) { x }];`,
 			"unusable as hash key: FUNCTION",
@@ -536,5 +660,10 @@ func testEval(input string) object.Object {
 	env := object.NewEnvironment()
 
 	program := p.ParseProgram()
+	return runProgram(program, env)
+}
+
+// runProgram evaluates the programs of the tests, engine_test.go swaps it to run them on the vm as well
+var runProgram = func(program *ast.Program, env *object.Environment) object.Object {
 	return Eval(program, env)
 }
@@ -0,0 +1,11 @@
+package evaluator
+
+import (
+	"github.com/ShivankSharma070/go-interpreter/ast"
+	"github.com/ShivankSharma070/go-interpreter/object"
+)
+
+// RunTestsWith makes the tests of this package run their programs with eval instead of the evaluator
+func RunTestsWith(eval func(*ast.Program, *object.Environment) object.Object) {
+	runProgram = eval
+}
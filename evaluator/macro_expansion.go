@@ -0,0 +1,132 @@
+package evaluator
+
+import (
+	"github.com/ShivankSharma070/go-interpreter/ast"
+	"github.com/ShivankSharma070/go-interpreter/object"
+)
+
+// ExpandProgram defines the macros of program and expands their calls. The macros are evaluated in an environment
+// of their own, sharing the runtime of env.
+func ExpandProgram(program *ast.Program, env *object.Environment) (*ast.Program, *object.Error) {
+	macroEnv := object.NewEnvironment()
+	macroEnv.Runtime = env.Runtime
+	DefineMacros(program, macroEnv)
+	expanded, err := ExpandMacros(program, macroEnv)
+	if err != nil {
+		return nil, err
+	}
+	return expanded.(*ast.Program), nil
+}
+
+// DefineMacros binds the macros defined by top level let statements in env, and removes those statements
+// from the program so they are not evaluated.
+func DefineMacros(program *ast.Program, env *object.Environment) {
+	statements := program.Statements[:0]
+	for _, statement := range program.Statements {
+		if macro, name, ok := macroDefinition(statement); ok {
+			resolveMacro(macro, env)
+			env.Set(name, &object.Macro{Parameters: macro.Parameters, Body: macro.Body, NumLocals: macro.NumLocals, Env: env})
+			continue
+		}
+		statements = append(statements, statement)
+	}
+	program.Statements = statements
+}
+
+// macroDefinition reports whether statement is `let name = macro(...) { ... }`
+func macroDefinition(statement ast.Statement) (*ast.MacroLiteral, string, bool) {
+	let, ok := statement.(*ast.LetStatement)
+	if !ok {
+		return nil, "", false
+	}
+	macro, ok := let.Value.(*ast.MacroLiteral)
+	if !ok {
+		return nil, "", false
+	}
+	return macro, let.Name.Value, true
+}
+
+// ExpandMacros replaces every call to a macro defined in env by the code the macro returns.
+// The arguments of a macro call are not evaluated, the macro receives them quoted.
+// It fails when a macro does not return quoted code or when evaluating a macro fails.
+func ExpandMacros(program ast.Node, env *object.Environment) (ast.Node, *object.Error) {
+	var err *object.Error
+	expanded := ast.Modify(program, func(node ast.Node) ast.Node {
+		if err != nil {
+			return node
+		}
+		call, ok := node.(*ast.CallExpression)
+		if !ok {
+			return node
+		}
+		macro, ok := isMacroCall(call, env)
+		if !ok {
+			return node
+		}
+
+		if len(call.Argument) != len(macro.Parameters) {
+			err = newError("wrong number of arguments to macro %s. got=%d, want=%d",
+				call.Function.String(), len(call.Argument), len(macro.Parameters))
+			return node
+		}
+		evalEnv := object.NewFrameEnvironment(macro.Env, macro.NumLocals)
+		for i := range macro.Parameters {
+			evalEnv.Slots[i] = &object.Quote{Node: call.Argument[i]}
+		}
+
+		evaluated := unwrapReturnValue(Eval(macro.Body, evalEnv))
+		quote, ok := evaluated.(*object.Quote)
+		if !ok {
+			if e, isErr := evaluated.(*object.Error); isErr {
+				err = newError("in macro %s: %s", call.Function.String(), e.Message)
+			} else {
+				err = newError("macro %s must return QUOTE, got %s", call.Function.String(), typeName(evaluated))
+			}
+			return node
+		}
+		return copyIdentifiers(quote.Node)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return expanded, nil
+}
+
+// copyIdentifiers returns node with identifiers of its own. The resolver gives an identifier an address that depends on
+// where it is used, and the code a macro returns shares nodes with the macro and its arguments, which can end up in
+// more than one place.
+func copyIdentifiers(node ast.Node) ast.Node {
+	return ast.Modify(node, func(node ast.Node) ast.Node {
+		switch node := node.(type) {
+		case *ast.Identifier:
+			copied := *node
+			return &copied
+		case *ast.LetStatement:
+			// node is already a copy made by Modify, but its name is not
+			name := *node.Name
+			node.Name = &name
+		}
+		return node
+	})
+}
+
+func isMacroCall(call *ast.CallExpression, env *object.Environment) (*object.Macro, bool) {
+	iden, ok := call.Function.(*ast.Identifier)
+	if !ok {
+		return nil, false
+	}
+	obj, ok := env.Get(iden.Value)
+	if !ok {
+		return nil, false
+	}
+	macro, ok := obj.(*object.Macro)
+	return macro, ok
+}
+
+// typeName is the type of obj, a statement with no value counts as NULL
+func typeName(obj object.Object) object.ObjectType {
+	if obj == nil {
+		return object.NULL_OBJ
+	}
+	return obj.Type()
+}
@@ -0,0 +1,61 @@
+package evaluator
+
+import (
+	"slices"
+
+	"github.com/ShivankSharma070/go-interpreter/object"
+)
+
+// Builtins which can be called as methods of a type, value.name(args) calls the builtin name(value, args).
+var methods = map[object.ObjectType][]string{
+	object.STRING_OBJ: {
+		"len", "split", "trim", "upper", "lower", "replace", "contains", "starts_with", "ends_with",
+		"index_of", "repeat", "chars", "lines", "format", "int", "float", "json_decode",
+	},
+	object.ARRAY_OBJ: {
+		"len", "first", "last", "rest", "push", "map", "filter", "reduce", "each", "find", "any", "all",
+		"zip", "enumerate", "flatten", "sum", "min", "max", "sort", "sort_by", "join",
+	},
+	object.INTEGER_OBJ: {"abs", "pow", "sqrt", "floor", "ceil", "round", "clamp", "float"},
+	object.FLOAT_OBJ:   {"abs", "pow", "sqrt", "floor", "ceil", "round", "clamp", "int"},
+}
+
+// Methods every type has
+var commonMethods = []string{"type", "str", "json_encode"}
+
+func evalMemberExpression(obj object.Object, name string) object.Object {
+	switch obj := obj.(type) {
+	case *object.Module:
+		return evalModuleMember(obj, name)
+	case *object.Hash:
+		// Keys of a hash shadow its methods
+		if pair, ok := obj.Pair[(&object.String{Value: name}).HashKey()]; ok {
+			return pair.Value
+		}
+		if method := lookupMethod(obj, name); method != nil {
+			return method
+		}
+		return NULL
+	default:
+		if method := lookupMethod(obj, name); method != nil {
+			return method
+		}
+		return newError("%s has no method %s", obj.Type(), name)
+	}
+}
+
+// lookupMethod returns the method name of obj bound to it, or nil if there is no such method
+func lookupMethod(obj object.Object, name string) *object.Builtin {
+	if !slices.Contains(methods[obj.Type()], name) && !slices.Contains(commonMethods, name) {
+		return nil
+	}
+	builtin, ok := builtins[name]
+	if !ok {
+		return nil
+	}
+	return &object.Builtin{
+		Fn: func(ctx *object.BuiltinContext, args ...object.Object) object.Object {
+			return builtin.Fn(ctx, append([]object.Object{obj}, args...)...)
+		},
+	}
+}
@@ -0,0 +1,62 @@
+package evaluator
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/ShivankSharma070/go-interpreter/object"
+)
+
+func TestMemberExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`let h = {"name": "monkey", "age": 5}; h.name`, "monkey"},
+		{`{"a": {"b": 2}}.a.b`, "2"},
+		{`{"a": 1}.missing`, "NULL"},
+		{`let obj = {"double": fn(x) { x * 2 }}; obj.double(4)`, "8"},
+		{`{"type": "shadowed"}.type`, "shadowed"},
+		{`{"a": 1}.type()`, "HASH"},
+		{`"abc".upper()`, "ABC"},
+		{`"a,b".split(",")`, "[a, b]"},
+		{`"abc".len()`, "3"},
+		{`[3, 1, 2].push(0).sort()`, "[0, 1, 2, 3]"},
+		{`[1, 2, 3].map(fn(x) { x * x }).sum()`, "14"},
+		{`[1, 2].join("-")`, "1-2"},
+		{`(-2.5).abs()`, "2.5"},
+		{`2.pow(3)`, "8"},
+		{`1.5.floor()`, "1"},
+		{`let up = "x".upper; up()`, "X"},
+		{`5.upper()`, "Error: INTEGER has no method upper"},
+		{`[1].nope`, "Error: ARRAY has no method nope"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("%s is not %q, got %q", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
+func TestModuleMemberAccess(t *testing.T) {
+	files := object.NewReadOnlyFilePolicy(fstest.MapFS{
+		"greet.monkey": {Data: []byte(`let hello = fn(name) { "hello " + name }; let who = "world";`)},
+	})
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`let g = import("greet"); g.hello(g.who)`, "hello world"},
+		{`import("greet").missing`, `Error: module "greet.monkey" has no member missing`},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEvalWithFiles(tt.input, files)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("%s is not %q, got %q", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
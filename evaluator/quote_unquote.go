@@ -0,0 +1,75 @@
+package evaluator
+
+import (
+	"strconv"
+
+	"github.com/ShivankSharma070/go-interpreter/ast"
+	"github.com/ShivankSharma070/go-interpreter/object"
+	"github.com/ShivankSharma070/go-interpreter/token"
+)
+
+// quote returns node without evaluating it, except for the unquote(...) calls inside it,
+// which are evaluated in env and replaced by the code of their result.
+func quote(node ast.Node, env *object.Environment) object.Object {
+	var err *object.Error
+	node = ast.Modify(node, func(node ast.Node) ast.Node {
+		if err != nil || !isUnquoteCall(node) {
+			return node
+		}
+		call := node.(*ast.CallExpression)
+		if len(call.Argument) != 1 {
+			err = newError("wrong number of arguments to `unquote`. got=%d, want=1", len(call.Argument))
+			return node
+		}
+
+		unquoted := Eval(call.Argument[0], env)
+		if isError(unquoted) {
+			err, _ = unquoted.(*object.Error)
+			if err == nil {
+				err = newError("exit called inside unquote")
+			}
+			return node
+		}
+		converted, convErr := convertObjectToASTNode(unquoted)
+		if convErr != nil {
+			err = convErr
+			return node
+		}
+		return converted
+	})
+	if err != nil {
+		return err
+	}
+	return &object.Quote{Node: node}
+}
+
+func isUnquoteCall(node ast.Node) bool {
+	call, ok := node.(*ast.CallExpression)
+	if !ok {
+		return false
+	}
+	iden, ok := call.Function.(*ast.Identifier)
+	return ok && iden.Value == "unquote"
+}
+
+// convertObjectToASTNode turns the result of an unquote back into code
+func convertObjectToASTNode(obj object.Object) (ast.Node, *object.Error) {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		literal := strconv.FormatInt(obj.Value, 10)
+		return &ast.IntegerLiteral{Token: token.Token{Type: token.INT, Literal: literal}, Value: obj.Value}, nil
+	case *object.Float:
+		return &ast.FloatLiteral{Token: token.Token{Type: token.FLOAT, Literal: obj.Inspect()}, Value: obj.Value}, nil
+	case *object.String:
+		return &ast.StringLiteral{Token: token.Token{Type: token.STRING, Literal: obj.Value}, Value: obj.Value}, nil
+	case *object.Boolean:
+		if obj.Value {
+			return &ast.BoolExpression{Token: token.Token{Type: token.TRUE, Literal: "true"}, Value: true}, nil
+		}
+		return &ast.BoolExpression{Token: token.Token{Type: token.FALSE, Literal: "false"}, Value: false}, nil
+	case *object.Quote:
+		return obj.Node, nil
+	default:
+		return nil, newError("cannot unquote %s, only INTEGER, FLOAT, STRING, BOOLEAN and QUOTE can be turned into code", obj.Type())
+	}
+}
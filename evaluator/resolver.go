@@ -0,0 +1,202 @@
+package evaluator
+
+import (
+	"github.com/ShivankSharma070/go-interpreter/ast"
+	"github.com/ShivankSharma070/go-interpreter/object"
+)
+
+// Resolve gives every identifier of program the address of the variable it names, so evaluating it does not
+// search environments by name. Parameters and lets of a function are stored in slots of the environment of a call,
+// an identifier naming one knows how many functions up it was defined and its slot. Top level variables are still
+// looked up by name in env, where the REPL and the host can add them.
+// It fails on the first identifier that names no variable, builtin or constant.
+func Resolve(program *ast.Program, env *object.Environment) *object.Error {
+	r := &resolver{env: env, globals: make(map[string]bool)}
+	r.resolveStatements(program.Statements)
+	r.resolvePending()
+	return r.checkUnbound()
+}
+
+// ResolveLocals gives the identifiers of program their addresses like Resolve does, without checking that the others
+// name a variable. The compiler uses it, the globals of a program it compiles are only known when it runs.
+func ResolveLocals(program *ast.Program) {
+	r := &resolver{globals: make(map[string]bool)}
+	r.resolveStatements(program.Statements)
+	r.resolvePending()
+}
+
+// resolveMacro resolves the body of a macro, which is evaluated in env when macros are expanded
+func resolveMacro(macro *ast.MacroLiteral, env *object.Environment) {
+	r := &resolver{env: env, globals: make(map[string]bool)}
+	r.resolveFunction(macro.Parameters, macro.Body, &macro.NumLocals)
+}
+
+// scope holds the locals of a function
+type scope struct {
+	slots map[string]int
+	size  int
+}
+
+type resolver struct {
+	env     *object.Environment
+	globals map[string]bool   // Names bound by a top level let of the program
+	scopes  []*scope          // Functions enclosing the code being resolved, innermost last
+	pending []func()          // Functions found in the code being resolved, see resolveFunction
+	unbound []*ast.Identifier // Identifiers that are not locals, they must name a global, a builtin or a constant
+}
+
+func (r *resolver) resolveStatements(statements []ast.Statement) {
+	for _, statement := range statements {
+		r.resolve(statement)
+	}
+}
+
+func (r *resolver) resolve(node ast.Node) {
+	switch node := node.(type) {
+	case *ast.ExpressionStatement:
+		r.resolve(node.Expression)
+	case *ast.LetStatement:
+		// The value is resolved first, in `let x = x + 1` the x on the right is the outer one
+		r.resolve(node.Value)
+		r.define(node.Name)
+	case *ast.ReturnStatement:
+		r.resolve(node.ReturnValue)
+	case *ast.BlockStatement:
+		r.resolveStatements(node.Statements)
+	case *ast.Identifier:
+		r.resolveIdentifier(node)
+	case *ast.PrefixExpression:
+		r.resolve(node.Right)
+	case *ast.InfixExpression:
+		r.resolve(node.Left)
+		r.resolve(node.Right)
+	case *ast.IfElseExpression:
+		r.resolve(node.Condition)
+		r.resolve(node.Consequence)
+		if node.Alternative != nil {
+			r.resolve(node.Alternative)
+		}
+	case *ast.FunctionExpression:
+		r.pending = append(r.pending, func() {
+			r.resolveFunction(node.Parameters, node.Body, &node.NumLocals)
+		})
+	case *ast.CallExpression:
+		if iden, ok := node.Function.(*ast.Identifier); ok && iden.Value == "quote" {
+			for _, arg := range node.Argument {
+				r.resolveQuoted(arg)
+			}
+			return
+		}
+		r.resolve(node.Function)
+		for _, arg := range node.Argument {
+			r.resolve(arg)
+		}
+	case *ast.ArrayLiteral:
+		for _, element := range node.Elements {
+			r.resolve(element)
+		}
+	case *ast.IndexExpression:
+		r.resolve(node.Left)
+		r.resolve(node.Index)
+	case *ast.MemberExpression:
+		r.resolve(node.Object)
+	case *ast.HashLiteral:
+		for key, value := range node.Pairs {
+			r.resolve(key)
+			r.resolve(value)
+		}
+	}
+}
+
+// resolveQuoted resolves the arguments of the unquote calls of quoted code, the only part of it that is evaluated
+func (r *resolver) resolveQuoted(node ast.Node) {
+	ast.Inspect(node, func(node ast.Node) bool {
+		if !isUnquoteCall(node) {
+			return true
+		}
+		for _, arg := range node.(*ast.CallExpression).Argument {
+			r.resolve(arg)
+		}
+		return false
+	})
+}
+
+// resolveFunction resolves the body of a function. It is called once the code around the function is resolved,
+// so a function can use the variables bound after it in the enclosing functions, they are set by the time it runs.
+func (r *resolver) resolveFunction(params []*ast.Identifier, body *ast.BlockStatement, numLocals *int) {
+	scope := &scope{slots: make(map[string]int)}
+	r.scopes = append(r.scopes, scope)
+	enclosingPending := r.pending
+	r.pending = nil
+
+	// Parameters take the first slots in order, even when a name is repeated
+	for i, param := range params {
+		scope.slots[param.Value] = i
+		param.Local, param.Depth, param.Slot = true, 0, i
+	}
+	scope.size = len(params)
+	r.resolveStatements(body.Statements)
+	r.resolvePending()
+
+	r.pending = enclosingPending
+	r.scopes = r.scopes[:len(r.scopes)-1]
+	*numLocals = scope.size
+}
+
+func (r *resolver) resolvePending() {
+	for len(r.pending) > 0 {
+		resolve := r.pending[0]
+		r.pending = r.pending[1:]
+		resolve()
+	}
+}
+
+// define binds a let in the innermost function, lets at the top level are globals
+func (r *resolver) define(iden *ast.Identifier) {
+	if len(r.scopes) == 0 {
+		r.globals[iden.Value] = true
+		iden.Local, iden.Depth, iden.Slot = false, 0, 0
+		return
+	}
+
+	scope := r.scopes[len(r.scopes)-1]
+	slot, ok := scope.slots[iden.Value]
+	if !ok {
+		// Binding the same name again reuses its slot
+		slot = scope.size
+		scope.slots[iden.Value] = slot
+		scope.size++
+	}
+	iden.Local, iden.Depth, iden.Slot = true, 0, slot
+}
+
+func (r *resolver) resolveIdentifier(iden *ast.Identifier) {
+	for depth := 0; depth < len(r.scopes); depth++ {
+		if slot, ok := r.scopes[len(r.scopes)-1-depth].slots[iden.Value]; ok {
+			iden.Local, iden.Depth, iden.Slot = true, depth, slot
+			return
+		}
+	}
+	iden.Local, iden.Depth, iden.Slot = false, 0, 0
+	r.unbound = append(r.unbound, iden)
+}
+
+// checkUnbound runs once the whole program is resolved, when every top level let is known
+func (r *resolver) checkUnbound() *object.Error {
+	for _, iden := range r.unbound {
+		if r.globals[iden.Value] {
+			continue
+		}
+		if _, ok := r.env.Get(iden.Value); ok {
+			continue
+		}
+		if _, ok := builtins[iden.Value]; ok {
+			continue
+		}
+		if _, ok := constants[iden.Value]; ok {
+			continue
+		}
+		return newError("identifier not found: %s", iden.Value)
+	}
+	return nil
+}
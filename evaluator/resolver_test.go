@@ -0,0 +1,134 @@
+package evaluator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ShivankSharma070/go-interpreter/ast"
+	"github.com/ShivankSharma070/go-interpreter/object"
+)
+
+func TestResolveAddresses(t *testing.T) {
+	program := testParseProgram(`let a = 1; let f = fn(x) { let y = x; fn(z) { [a, x, y, z, later] } }; let later = 2;`)
+	if err := Resolve(program, object.NewEnvironment()); err != nil {
+		t.Fatalf("unexpected error: %s", err.Message)
+	}
+
+	outer := program.Statements[1].(*ast.LetStatement).Value.(*ast.FunctionExpression)
+	if outer.NumLocals != 2 {
+		t.Errorf("f has %d locals, want 2", outer.NumLocals)
+	}
+	inner := outer.Body.Statements[1].(*ast.ExpressionStatement).Expression.(*ast.FunctionExpression)
+	if inner.NumLocals != 1 {
+		t.Errorf("the inner function has %d locals, want 1", inner.NumLocals)
+	}
+
+	tests := []struct {
+		name  string
+		local bool
+		depth int
+		slot  int
+	}{
+		{"a", false, 0, 0},
+		{"x", true, 1, 0},
+		{"y", true, 1, 1},
+		{"z", true, 0, 0},
+		{"later", false, 0, 0},
+	}
+
+	elements := inner.Body.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.ArrayLiteral).Elements
+	for i, tt := range tests {
+		iden := elements[i].(*ast.Identifier)
+		if iden.Value != tt.name {
+			t.Fatalf("element %d is %s, want %s", i, iden.Value, tt.name)
+		}
+		if iden.Local != tt.local || iden.Depth != tt.depth || iden.Slot != tt.slot {
+			t.Errorf("%s resolved to local=%t depth=%d slot=%d, want local=%t depth=%d slot=%d",
+				tt.name, iden.Local, iden.Depth, iden.Slot, tt.local, tt.depth, tt.slot)
+		}
+	}
+}
+
+func TestResolveErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`let f = fn() { missing }; 1`, "identifier not found: missing"},
+		{`if (false) { missing }`, "identifier not found: missing"},
+		{`let f = fn(x) { x }; f(y)`, "identifier not found: y"},
+		// A parameter is only visible inside its function
+		{`let f = fn(x) { x }; x`, "identifier not found: x"},
+		{`let f = fn() { let inner = 1; inner }; inner`, "identifier not found: inner"},
+		{`quote(unquote(missing) + other)`, "identifier not found: missing"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		err, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("%s did not fail, got %T (%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+		if err.Message != tt.expected {
+			t.Errorf("wrong error for %s. want=%q, got=%q", tt.input, tt.expected, err.Message)
+		}
+	}
+}
+
+func TestResolveErrorsBeforeRunning(t *testing.T) {
+	env := object.NewEnvironment()
+	var out strings.Builder
+	env.Runtime.Stdout = &out
+
+	result := runProgram(testParseProgram(`puts("started"); let f = fn() { missing }; f()`), env)
+	if err, ok := result.(*object.Error); !ok || err.Message != "identifier not found: missing" {
+		t.Fatalf("undefined variable was not reported, got %+v", result)
+	}
+	if out.String() != "" {
+		t.Errorf("program ran before failing, printed %q", out.String())
+	}
+}
+
+func TestResolvedVariables(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{`let x = 1; let f = fn() { let y = x; let x = 10; y + x }; f()`, 11},
+		{`let x = 1; let f = fn() { let x = x + 1; x }; f() + x`, 3},
+		{`let f = fn(a) { let a = a * 2; a }; f(4)`, 8},
+		{`let f = fn() { let g = fn(n) { if (n == 0) { 0 } else { g(n - 1) } }; g(5) }; f()`, 0},
+		{`let f = fn(a) { fn(b) { fn(c) { a * 100 + b * 10 + c } } }; f(1)(2)(3)`, 123},
+		{`let f = fn(a) { if (a > 0) { let b = a * 2; b } else { 0 } }; f(3)`, 6},
+		// Top level functions see globals bound after them, and builtins until a global shadows them
+		{`let f = fn() { g() }; let g = fn() { 7 }; f()`, 7},
+		{`let n = len("ab"); let len = fn(x) { 40 }; n + len(1)`, 42},
+		{`let f = fn(puts) { puts + 1 }; f(1)`, 2},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestResolveUsesEnvironment(t *testing.T) {
+	env := object.NewEnvironment()
+	env.Set("base", &object.Integer{Value: 40})
+	testIntegerObject(t, runProgram(testParseProgram(`let f = fn() { base + 2 }; f()`), env), 42)
+}
+
+func TestMacroExpansionsAreResolvedWhereTheyAreUsed(t *testing.T) {
+	program := testParseProgram(`let next = macro() { quote(n + 1) }; let n = 100; let f = fn(n) { next() }; [f(1), next()]`)
+	macroEnv := object.NewEnvironment()
+	DefineMacros(program, macroEnv)
+	expanded, err := ExpandMacros(program, macroEnv)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Message)
+	}
+
+	result := runProgram(expanded.(*ast.Program), object.NewEnvironment())
+	if result.Inspect() != "[2, 101]" {
+		t.Errorf("wrong result, got %s", result.Inspect())
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/ShivankSharma070/go-interpreter/formatter"
+)
+
+// formatCommand implements the fmt subcommand. It formats the scripts it is given, directories are searched for
+// scripts, or its standard input without any.
+func formatCommand(args []string) int {
+	flags := flag.NewFlagSet("fmt", flag.ContinueOnError)
+	write := flags.Bool("w", false, "write the result back to the files instead of printing it")
+	check := flags.Bool("check", false, "list the files that are not formatted and fail if there are any, without changing them")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "usage: %s fmt [-w | -check] [path ...]\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *write && *check {
+		fmt.Fprintln(os.Stderr, "fmt: -w and -check cannot be used together")
+		return 2
+	}
+
+	if flags.NArg() == 0 {
+		if *write {
+			fmt.Fprintln(os.Stderr, "fmt: -w needs files to write to")
+			return 2
+		}
+		source, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		return formatSource("<standard input>", source, false, *check)
+	}
+
+	status := 0
+	for _, root := range flags.Args() {
+		err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			// Scripts are found by their extension in directories, a file given by name is always formatted
+			if entry.IsDir() || (path != root && filepath.Ext(path) != scriptExtension) {
+				return nil
+			}
+			source, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			status = max(status, formatSource(path, source, *write, *check))
+			return nil
+		})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			status = max(status, 1)
+		}
+	}
+	return status
+}
+
+// formatSource formats the source of the script at path, printing the result, writing it back to path or only
+// reporting path when it is not formatted.
+func formatSource(path string, source []byte, write, check bool) int {
+	formatted, err := formatter.Format(string(source))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", path, err)
+		return 1
+	}
+
+	switch {
+	case check:
+		if formatted != string(source) {
+			fmt.Println(path)
+			return 1
+		}
+	case write:
+		if formatted == string(source) {
+			return 0
+		}
+		info, err := os.Stat(path)
+		if err == nil {
+			err = os.WriteFile(path, []byte(formatted), info.Mode().Perm())
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	default:
+		fmt.Print(formatted)
+	}
+	return 0
+}
@@ -0,0 +1,420 @@
+// Package formatter prints monkey programs in their canonical layout.
+//
+// Statements go on their own line, blocks are indented with tabs, binary operators are surrounded by spaces and
+// parentheses are only kept where the precedence of the parser needs them. Comments stay on the line they were
+// written on. The ones written inside an expression follow it, each on its own line after the first one.
+package formatter
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/ShivankSharma070/go-interpreter/ast"
+	"github.com/ShivankSharma070/go-interpreter/lexer"
+	"github.com/ShivankSharma070/go-interpreter/parser"
+	"github.com/ShivankSharma070/go-interpreter/token"
+)
+
+// Blocks made of a single expression stay on one line when it fits in that many bytes
+const inlineBlockWidth = 60
+
+// Format returns source in the canonical layout, it fails when source does not parse
+func Format(source string) (string, error) {
+	l := lexer.New(source)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		return "", errors.New(strings.Join(p.Errors(), "\n"))
+	}
+
+	printer := &printer{lines: strings.Split(source, "\n")}
+	printer.comments = commentsOf(source, l.Comments())
+	printer.program(program)
+	return printer.out.String(), nil
+}
+
+// Node returns the code of node in the canonical layout, without comments
+func Node(node ast.Node) string {
+	p := &printer{}
+	switch node := node.(type) {
+	case *ast.Program:
+		p.program(node)
+		return strings.TrimSuffix(p.out.String(), "\n")
+	case *ast.BlockStatement:
+		p.block(node)
+	case ast.Statement:
+		p.statement(node, true, nil)
+	case ast.Expression:
+		p.expression(node)
+	}
+	return p.out.String()
+}
+
+type comment struct {
+	token.Token
+	trailing bool // Code comes before the comment on its line
+}
+
+// commentsOf tells the comments written after code on the same line apart from those on a line of their own
+func commentsOf(source string, tokens []token.Token) []comment {
+	firstCode := make(map[int]int) // Column of the first token of every line with code
+	l := lexer.New(source)
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		if column, ok := firstCode[tok.Pos.Line]; !ok || tok.Pos.Column < column {
+			firstCode[tok.Pos.Line] = tok.Pos.Column
+		}
+	}
+
+	comments := make([]comment, len(tokens))
+	for i, tok := range tokens {
+		column, ok := firstCode[tok.Pos.Line]
+		comments[i] = comment{Token: tok, trailing: ok && column < tok.Pos.Column}
+	}
+	return comments
+}
+
+type printer struct {
+	out       strings.Builder
+	indent    int
+	lineStart bool // Nothing was written on the current line, not even its indentation
+	commented bool // A comment ends the current line, another one cannot follow it there
+	opened    bool // Nothing was written since the program started or a block was opened
+
+	lines    []string  // Lines of the source, to keep blank lines between statements
+	comments []comment // Comments not printed yet
+}
+
+func (p *printer) write(s string) {
+	if p.lineStart {
+		p.out.WriteString(strings.Repeat("\t", p.indent))
+		p.lineStart = false
+		p.commented = false
+	}
+	p.out.WriteString(s)
+	p.opened = false
+}
+
+// breakLine starts a new line for something written at line in the source. A blank line before it in the source
+// is kept, unless it is the first thing of the program or of a block.
+func (p *printer) breakLine(line int) {
+	if p.out.Len() == 0 {
+		return
+	}
+	if !p.opened && line >= 2 && line-2 < len(p.lines) && strings.TrimSpace(p.lines[line-2]) == "" {
+		p.out.WriteString("\n")
+	}
+	p.out.WriteString("\n")
+	p.lineStart = true
+	p.opened = false
+}
+
+// flushComments prints the comments found before pos. Several comments written inside an expression that is
+// printed on one line get a line each, only the first one stays after the code.
+func (p *printer) flushComments(pos token.Position) {
+	for len(p.comments) > 0 && before(p.comments[0].Pos, pos) {
+		c := p.comments[0]
+		p.comments = p.comments[1:]
+		if c.trailing && p.out.Len() > 0 && !p.lineStart && !p.commented {
+			p.write(" " + c.Literal)
+		} else {
+			p.breakLine(c.Pos.Line)
+			p.write(c.Literal)
+		}
+		p.commented = true
+	}
+}
+
+// hasComments reports whether a comment is left between from and to
+func (p *printer) hasComments(from, to token.Position) bool {
+	for _, c := range p.comments {
+		if before(c.Pos, to) && !before(c.Pos, from) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *printer) program(program *ast.Program) {
+	p.opened = true
+	p.statements(program.Statements, false)
+	p.flushComments(token.Position{Line: len(p.lines) + 1})
+	if p.out.Len() > 0 {
+		p.out.WriteString("\n")
+	}
+}
+
+// statements prints the statements of the program or of a block, one per line
+func (p *printer) statements(statements []ast.Statement, inBlock bool) {
+	for i, statement := range statements {
+		start := ast.Start(statement)
+		p.flushComments(start)
+		p.breakLine(start.Line)
+
+		var next ast.Statement
+		if i+1 < len(statements) {
+			next = statements[i+1]
+		}
+		p.statement(statement, inBlock && next == nil, next)
+	}
+}
+
+// statement prints a statement, last tells if it ends a block and next is the statement after it
+func (p *printer) statement(statement ast.Statement, last bool, next ast.Statement) {
+	switch statement := statement.(type) {
+	case *ast.LetStatement:
+		p.write("let " + statement.Name.Value + " = ")
+		p.expression(statement.Value)
+		p.write(";")
+	case *ast.ReturnStatement:
+		p.write("return ")
+		p.expression(statement.ReturnValue)
+		p.write(";")
+	case *ast.ExpressionStatement:
+		p.expression(statement.Expression)
+		if needsSemicolon(statement, last, next) {
+			p.write(";")
+		}
+	case *ast.BlockStatement:
+		p.block(statement)
+	}
+}
+
+// needsSemicolon reports whether an expression statement is followed by a semicolon. It is left out at the end
+// of a block and after an if, unless the next statement would otherwise be read as part of the expression.
+func needsSemicolon(statement *ast.ExpressionStatement, last bool, next ast.Statement) bool {
+	if next != nil {
+		if next, ok := next.(*ast.ExpressionStatement); ok && continuesExpression(next.Expression) {
+			return true
+		}
+	}
+	if _, ok := statement.Expression.(*ast.IfElseExpression); ok {
+		return false
+	}
+	return !last
+}
+
+// continuesExpression reports whether the code of expression starts with a token that can follow an expression,
+// a call, an index or a subtraction
+func continuesExpression(expression ast.Expression) bool {
+	switch expression := expression.(type) {
+	case *ast.InfixExpression:
+		return needsParens(expression.Left, precedenceOf(expression), false) || continuesExpression(expression.Left)
+	case *ast.CallExpression:
+		return needsParens(expression.Function, parser.CALL, false) || continuesExpression(expression.Function)
+	case *ast.IndexExpression:
+		return needsParens(expression.Left, parser.CALL, false) || continuesExpression(expression.Left)
+	case *ast.MemberExpression:
+		return needsParens(expression.Object, parser.CALL, false) || continuesExpression(expression.Object)
+	case *ast.PrefixExpression:
+		return expression.Operator == "-"
+	case *ast.IntegerLiteral:
+		return strings.HasPrefix(expression.Token.Literal, "-")
+	case *ast.FloatLiteral:
+		return strings.HasPrefix(expression.Token.Literal, "-")
+	case *ast.ArrayLiteral:
+		return true
+	default:
+		return false
+	}
+}
+
+// block prints a block on one line when it is a single short expression, on its own lines otherwise
+func (p *printer) block(block *ast.BlockStatement) {
+	if p.hasComments(block.Token.Pos, block.End) {
+		p.multilineBlock(block)
+		return
+	}
+	if len(block.Statements) == 0 {
+		p.write("{}")
+		return
+	}
+	if statement, ok := block.Statements[0].(*ast.ExpressionStatement); ok && len(block.Statements) == 1 {
+		inline := Node(statement.Expression)
+		if len(inline) <= inlineBlockWidth && !strings.Contains(inline, "\n") {
+			p.write("{ " + inline + " }")
+			return
+		}
+	}
+	p.multilineBlock(block)
+}
+
+func (p *printer) multilineBlock(block *ast.BlockStatement) {
+	p.write("{")
+	p.indent++
+	p.opened = true
+	p.statements(block.Statements, true)
+	p.flushComments(block.End)
+	p.indent--
+	p.out.WriteString("\n")
+	p.lineStart = true
+	p.write("}")
+}
+
+func (p *printer) expression(expression ast.Expression) {
+	switch node := expression.(type) {
+	case *ast.Identifier:
+		p.write(node.Value)
+	case *ast.IntegerLiteral, *ast.FloatLiteral, *ast.BoolExpression:
+		p.write(node.String())
+	case *ast.StringLiteral:
+		p.write(`"` + node.Value + `"`)
+	case *ast.PrefixExpression:
+		p.write(node.Operator)
+		if startsWith(node.Right, node.Operator) {
+			p.write("(")
+			p.expression(node.Right)
+			p.write(")")
+		} else {
+			p.operand(node.Right, parser.PREFIX, false)
+		}
+	case *ast.InfixExpression:
+		precedence := precedenceOf(node)
+		p.operand(node.Left, precedence, false)
+		p.write(" " + node.Operator + " ")
+		p.operand(node.Right, precedence, true)
+	case *ast.IfElseExpression:
+		p.write("if (")
+		p.expression(node.Condition)
+		p.write(") ")
+		p.block(node.Consequence)
+		if node.Alternative != nil {
+			p.write(" else ")
+			p.block(node.Alternative)
+		}
+	case *ast.FunctionExpression:
+		p.write("fn")
+		p.parameters(node.Parameters)
+		p.block(node.Body)
+	case *ast.MacroLiteral:
+		p.write("macro")
+		p.parameters(node.Parameters)
+		p.block(node.Body)
+	case *ast.CallExpression:
+		p.operand(node.Function, parser.CALL, false)
+		p.write("(")
+		p.list(node.Argument)
+		p.write(")")
+	case *ast.ArrayLiteral:
+		p.write("[")
+		p.list(node.Elements)
+		p.write("]")
+	case *ast.IndexExpression:
+		p.operand(node.Left, parser.CALL, false)
+		p.write("[")
+		p.expression(node.Index)
+		p.write("]")
+	case *ast.MemberExpression:
+		p.operand(node.Object, parser.CALL, false)
+		p.write("." + node.Property.Value)
+	case *ast.HashLiteral:
+		p.hash(node)
+	}
+}
+
+// operand prints the operand of an operator binding as tightly as precedence, in parentheses when the parser
+// would otherwise bind it differently. A right operand of the same precedence needs them, operators group left.
+func (p *printer) operand(expression ast.Expression, precedence int, right bool) {
+	if needsParens(expression, precedence, right) {
+		p.write("(")
+		p.expression(expression)
+		p.write(")")
+		return
+	}
+	p.expression(expression)
+}
+
+// startsWith reports whether the code of expression starts with the prefix operator, -(-x) would read like --x
+// without parentheses
+func startsWith(expression ast.Expression, operator string) bool {
+	switch expression := expression.(type) {
+	case *ast.PrefixExpression:
+		return expression.Operator == operator
+	case *ast.IntegerLiteral:
+		return strings.HasPrefix(expression.Token.Literal, operator)
+	case *ast.FloatLiteral:
+		return strings.HasPrefix(expression.Token.Literal, operator)
+	}
+	return false
+}
+
+func needsParens(expression ast.Expression, precedence int, right bool) bool {
+	if right {
+		return precedenceOf(expression) <= precedence
+	}
+	return precedenceOf(expression) < precedence
+}
+
+// Precedence of the expressions that are not operators, nothing binds tighter
+const atom = parser.INDEX + 1
+
+// precedenceOf returns how tightly the operator at the top of expression binds
+func precedenceOf(expression ast.Expression) int {
+	switch expression := expression.(type) {
+	case *ast.InfixExpression:
+		return parser.Precedence(token.TokenType(expression.Operator))
+	case *ast.PrefixExpression:
+		return parser.PREFIX
+	case *ast.CallExpression:
+		return parser.CALL
+	case *ast.IndexExpression, *ast.MemberExpression:
+		return parser.INDEX
+	case *ast.IntegerLiteral:
+		// Negative literals only come from rewritten programs, they read like a prefix expression
+		if strings.HasPrefix(expression.Token.Literal, "-") {
+			return parser.PREFIX
+		}
+	case *ast.FloatLiteral:
+		if strings.HasPrefix(expression.Token.Literal, "-") {
+			return parser.PREFIX
+		}
+	}
+	return atom
+}
+
+func (p *printer) parameters(parameters []*ast.Identifier) {
+	names := make([]string, len(parameters))
+	for i, param := range parameters {
+		names[i] = param.Value
+	}
+	p.write("(" + strings.Join(names, ", ") + ") ")
+}
+
+func (p *printer) list(expressions []ast.Expression) {
+	for i, expression := range expressions {
+		if i > 0 {
+			p.write(", ")
+		}
+		p.expression(expression)
+	}
+}
+
+// hash prints the pairs of a hash in the order they were written in
+func (p *printer) hash(hash *ast.HashLiteral) {
+	keys := make([]ast.Expression, 0, len(hash.Pairs))
+	for key := range hash.Pairs {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := ast.Start(keys[i]), ast.Start(keys[j])
+		if a != b {
+			return before(a, b)
+		}
+		return keys[i].String() < keys[j].String()
+	})
+
+	p.write("{")
+	for i, key := range keys {
+		if i > 0 {
+			p.write(", ")
+		}
+		p.expression(key)
+		p.write(": ")
+		p.expression(hash.Pairs[key])
+	}
+	p.write("}")
+}
+
+func before(a, b token.Position) bool {
+	return a.Line < b.Line || a.Line == b.Line && a.Column < b.Column
+}
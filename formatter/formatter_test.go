@@ -0,0 +1,160 @@
+package formatter
+
+import (
+	"testing"
+
+	"github.com/ShivankSharma070/go-interpreter/ast"
+	"github.com/ShivankSharma070/go-interpreter/lexer"
+	"github.com/ShivankSharma070/go-interpreter/parser"
+)
+
+func TestMinimalParentheses(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`((a + b) * c)`, `(a + b) * c`},
+		{`(a * b) + c`, `a * b + c`},
+		{`a + (b * c)`, `a + b * c`},
+		{`(a - b) - c`, `a - b - c`},
+		{`a - (b - c)`, `a - (b - c)`},
+		{`(a == b) == c`, `a == b == c`},
+		{`a == (b < c)`, `a == b < c`},
+		{`(a == b) < c`, `(a == b) < c`},
+		{`-(a + b)`, `-(a + b)`},
+		{`-(-a)`, `-(-a)`},
+		{`!(!a)`, `!(!a)`},
+		{`-(!a)`, `-!a`},
+		{`!(a < b)`, `!(a < b)`},
+		{`(-a)[0]`, `(-a)[0]`},
+		{`-(a[0])`, `-a[0]`},
+		{`(a + b)(1)`, `(a + b)(1)`},
+		{`(f(1))[0].x(2)`, `f(1)[0].x(2)`},
+		{`(fn(x) { x })(1)`, `fn(x) { x }(1)`},
+		{`(1.5 * x)`, `1.5 * x`},
+	}
+
+	for _, tt := range tests {
+		program := parse(t, tt.input)
+		expression := program.Statements[0].(*ast.ExpressionStatement).Expression
+		if got := Node(expression); got != tt.expected {
+			t.Errorf("wrong format of %s. want=%q, got=%q", tt.input, tt.expected, got)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let x=1;let y = x", "let x = 1;\nlet y = x;\n"},
+		{"puts(1)\nputs(2)", "puts(1);\nputs(2);\n"},
+		{`let h = {"b": 1, "a": [1,2], "c": true}`, "let h = {\"b\": 1, \"a\": [1, 2], \"c\": true};\n"},
+		{"let f = fn(a,b){a+b}", "let f = fn(a, b) { a + b };\n"},
+		{"let f = fn(){}", "let f = fn() {};\n"},
+		{
+			"let f = fn(n){ let m = n * 2; if (m > 2) { return m; } m }",
+			"let f = fn(n) {\n\tlet m = n * 2;\n\tif (m > 2) {\n\t\treturn m;\n\t}\n\tm\n};\n",
+		},
+		{"if (x) { 1 } else { 2 }\nputs(x)", "if (x) { 1 } else { 2 }\nputs(x);\n"},
+		// Without a semicolon the next statement would be read as a call or a subtraction
+		{"if (x) { 1 };\n(a + b) * 2;\n[1];-1", "if (x) { 1 };\n(a + b) * 2;\n[1];\n-1;\n"},
+		{"let m = macro(a) { quote(unquote(a) * 2) }", "let m = macro(a) { quote(unquote(a) * 2) };\n"},
+		// Blank lines are kept, but never more than one
+		{"let a = 1;\n\n\n\nlet b = 2;\nlet c = 3;", "let a = 1;\n\nlet b = 2;\nlet c = 3;\n"},
+		{"", ""},
+		// Comments inside an expression printed on one line get a line each
+		{"let xs = [\n 1, // one\n 2, // two\n 3\n];", "let xs = [1, 2, 3]; // one\n// two\n"},
+	}
+
+	for _, tt := range tests {
+		got, err := Format(tt.input)
+		if err != nil {
+			t.Fatalf("Format(%q) failed: %s", tt.input, err)
+		}
+		if got != tt.expected {
+			t.Errorf("wrong format of %q.\nwant=%q\ngot =%q", tt.input, tt.expected, got)
+		}
+	}
+}
+
+func TestFormatKeepsComments(t *testing.T) {
+	input := `// Header
+// comment
+
+let f = fn(x) { // after the brace
+  // before the let
+  let y = x;   // trailing
+  y
+  // at the end of the block
+};
+let a = [1, // inside an array
+  2];
+// at the end
+`
+	expected := `// Header
+// comment
+
+let f = fn(x) { // after the brace
+	// before the let
+	let y = x; // trailing
+	y
+	// at the end of the block
+};
+let a = [1, 2]; // inside an array
+// at the end
+`
+
+	got, err := Format(input)
+	if err != nil {
+		t.Fatalf("Format failed: %s", err)
+	}
+	if got != expected {
+		t.Errorf("wrong format.\nwant=\n%s\ngot=\n%s", expected, got)
+	}
+}
+
+func TestFormatIsStable(t *testing.T) {
+	inputs := []string{
+		`let fib = fn(n) { if (n < 2) { n } else { fib(n - 1) + fib((n - 2)) } }; puts(fib(10))`,
+		"let a = -(1 + 2) * -3 / (4 - -5);\nlet b = !(a < 2) == (a == 3)",
+		"let list = map([1, 2, 3], fn(x) { let y = x * x; y + 1 })\nlist[0] + len(list)",
+		`let s = "hello"; s.upper().len() + [1, 2][1]`,
+		"let f = fn() {\n// comment\nreturn if (true) { 1 } else { let z = 2; z };\n} // done",
+		"let xs = [\n 1, // one\n 2, // two\n 3\n];\nlet y = -(-xs[0]);",
+	}
+
+	for _, input := range inputs {
+		formatted, err := Format(input)
+		if err != nil {
+			t.Fatalf("Format(%q) failed: %s", input, err)
+		}
+		again, err := Format(formatted)
+		if err != nil {
+			t.Fatalf("formatted code does not parse: %s\n%s", err, formatted)
+		}
+		if again != formatted {
+			t.Errorf("formatting is not stable.\nfirst=\n%s\nsecond=\n%s", formatted, again)
+		}
+		if parse(t, formatted).String() != parse(t, input).String() {
+			t.Errorf("formatting changed the program.\nwant=%s\ngot =%s", parse(t, input).String(), parse(t, formatted).String())
+		}
+	}
+}
+
+func TestFormatParseError(t *testing.T) {
+	if _, err := Format("let = 1"); err == nil {
+		t.Errorf("expected an error for invalid code")
+	}
+}
+
+func parse(t *testing.T, input string) *ast.Program {
+	t.Helper()
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parser errors for %s: %v", input, p.Errors())
+	}
+	return program
+}
@@ -1,17 +1,26 @@
 package lexer
 
-import "github.com/ShivankSharma070/go-interpreter/token"
+import (
+	"strings"
+
+	"github.com/ShivankSharma070/go-interpreter/token"
+)
 
 type Lexer struct {
 	input        string
 	position     int  // Position of current char
 	readPosition int  // Position of next char
 	ch           byte // Current Character
+	line         int  // Line of current char
+	column       int  // Column of current char
+
+	comments []token.Token // Comments skipped so far
 }
 
 func New(inp string) *Lexer {
 	l := &Lexer{
 		input: inp,
+		line:  1,
 	}
 	l.ReadChar()
 	return l
@@ -25,6 +34,12 @@ func (l *Lexer) PeekChar() byte {
 }
 
 func (l *Lexer) ReadChar() {
+	if l.ch == '\n' {
+		l.line += 1
+		l.column = 0
+	}
+	l.column += 1
+
 	if l.readPosition >= len(l.input) {
 		l.ch = 0
 	} else {
@@ -37,8 +52,13 @@ func (l *Lexer) ReadChar() {
 func (l *Lexer) NextToken() token.Token {
 	var tok token.Token
 
-	// Eat all the whitespace as it does not matter in the language we are creating
+	// Eat all the whitespace and comments as they do not matter in the language we are creating
 	l.eatWhitespaces()
+	for l.ch == '/' && l.PeekChar() == '/' {
+		l.comments = append(l.comments, l.readComment())
+		l.eatWhitespaces()
+	}
+	pos := token.Position{Line: l.line, Column: l.column}
 
 	switch l.ch {
 	case '=':
@@ -75,6 +95,8 @@ func (l *Lexer) NextToken() token.Token {
 		tok = token.NewToken(token.COLON, l.ch)
 	case ',':
 		tok = token.NewToken(token.COMMA, l.ch)
+	case '.':
+		tok = token.NewToken(token.DOT, l.ch)
 	case '"':
 	tok.Type = token.STRING
 		tok.Literal = l.readString()
@@ -95,12 +117,13 @@ func (l *Lexer) NextToken() token.Token {
 		tok.Type = token.EOF
 	default:
 		if isLetter(l.ch) {
-			tok.Literal = l.readIdenOrLiteral(isLetter)
+			tok.Literal = l.readIdentifier()
 			tok.Type = token.LookUpIden(tok.Literal)
+			tok.Pos = pos
 			return tok // Important as positing is already incremented in readIden()
 		} else if isDigit(l.ch) {
-			tok.Literal = l.readIdenOrLiteral(isDigit)
-			tok.Type = token.INT
+			tok.Literal, tok.Type = l.readNumber()
+			tok.Pos = pos
 			return tok // Important as positing is already incremented in readIden()
 		} else {
 			tok.Type = token.ELLEGAL
@@ -108,6 +131,7 @@ func (l *Lexer) NextToken() token.Token {
 		}
 	}
 
+	tok.Pos = pos
 	l.ReadChar()
 	return tok
 }
@@ -122,6 +146,28 @@ func (l *Lexer) readIdenOrLiteral(validate func(byte) bool) string {
 	return l.input[position:l.position]
 }
 
+// Read an identifier, it starts with a letter and can contain digits after that (like log10)
+func (l *Lexer) readIdentifier() string {
+	position := l.position
+	for isLetter(l.ch) || isDigit(l.ch) {
+		l.ReadChar()
+	}
+	return l.input[position:l.position]
+}
+
+// Read an integer or a float literal, a float needs digits on both sides of its dot (1.5 but not 1. or .5)
+func (l *Lexer) readNumber() (string, token.TokenType) {
+	position := l.position
+	l.readIdenOrLiteral(isDigit)
+	if l.ch != '.' || !isDigit(l.PeekChar()) {
+		return l.input[position:l.position], token.INT
+	}
+
+	l.ReadChar()
+	l.readIdenOrLiteral(isDigit)
+	return l.input[position:l.position], token.FLOAT
+}
+
 // Function to read a string (can contain anything but should be enclosed within "" )
 func (l *Lexer) readString() string {
 	position := l.position + 1
@@ -136,6 +182,22 @@ func (l *Lexer) readString() string {
 	return l.input[position: l.position]
 }
 
+// Comments returns the comments read so far, in order. The parser never sees them, tools like the formatter
+// keep them around.
+func (l *Lexer) Comments() []token.Token {
+	return l.comments
+}
+
+// Read a comment, it starts with // and runs until the end of the line
+func (l *Lexer) readComment() token.Token {
+	pos := token.Position{Line: l.line, Column: l.column}
+	position := l.position
+	for l.ch != '\n' && l.ch != 0 {
+		l.ReadChar()
+	}
+	return token.Token{Type: token.COMMENT, Literal: strings.TrimRight(l.input[position:l.position], "\r"), Pos: pos}
+}
+
 // Eat up all the whitespaces, newline, tab characters
 func (l *Lexer) eatWhitespaces() {
 	for l.ch == '\n' || l.ch == ' ' || l.ch == '\r' || l.ch == '\t' {
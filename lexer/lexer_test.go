@@ -127,3 +127,93 @@ func TestLexer(t *testing.T) {
 		}
 	}
 }
+
+func TestTokenPosition(t *testing.T) {
+	input := `let x = 5;
+  x + "ab";`
+
+	test := []struct {
+		expectedLiteral string
+		expectedPos     token.Position
+	}{
+		{"let", token.Position{Line: 1, Column: 1}},
+		{"x", token.Position{Line: 1, Column: 5}},
+		{"=", token.Position{Line: 1, Column: 7}},
+		{"5", token.Position{Line: 1, Column: 9}},
+		{";", token.Position{Line: 1, Column: 10}},
+		{"x", token.Position{Line: 2, Column: 3}},
+		{"+", token.Position{Line: 2, Column: 5}},
+		{"ab", token.Position{Line: 2, Column: 7}},
+		{";", token.Position{Line: 2, Column: 11}},
+	}
+
+	l := New(input)
+	for i, tt := range test {
+		tok := l.NextToken()
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("Test_%d: Literal mismatch Expected:%q Got:%q", i, tt.expectedLiteral, tok.Literal)
+		}
+		if tok.Pos != tt.expectedPos {
+			t.Fatalf("Test_%d: Position mismatch Expected:%s Got:%s", i, tt.expectedPos, tok.Pos)
+		}
+	}
+}
+
+func TestNumbersAndIdentifiersWithDigits(t *testing.T) {
+	input := `3.14 42 log10 1.x a.b`
+
+	test := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.FLOAT, "3.14"},
+		{token.INT, "42"},
+		{token.IDEN, "log10"},
+		{token.INT, "1"},
+		{token.DOT, "."},
+		{token.IDEN, "x"},
+		{token.IDEN, "a"},
+		{token.DOT, "."},
+		{token.IDEN, "b"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+	for i, tt := range test {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType {
+			t.Fatalf("Test_%d: Type mismatch Expected:%q Got:%q", i, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("Test_%d: Literal mismatch Expected:%q Got:%q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestComments(t *testing.T) {
+	input := "// header\nlet x = 10 / 2; // half\r\n// last"
+
+	expectedTokens := []string{"let", "x", "=", "10", "/", "2", ";", ""}
+	l := New(input)
+	for i, expected := range expectedTokens {
+		tok := l.NextToken()
+		if tok.Literal != expected {
+			t.Fatalf("Test_%d: Literal mismatch Expected:%q Got:%q", i, expected, tok.Literal)
+		}
+	}
+
+	expectedComments := []token.Token{
+		{Type: token.COMMENT, Literal: "// header", Pos: token.Position{Line: 1, Column: 1}},
+		{Type: token.COMMENT, Literal: "// half", Pos: token.Position{Line: 2, Column: 17}},
+		{Type: token.COMMENT, Literal: "// last", Pos: token.Position{Line: 3, Column: 1}},
+	}
+	comments := l.Comments()
+	if len(comments) != len(expectedComments) {
+		t.Fatalf("expected %d comments, got %d: %v", len(expectedComments), len(comments), comments)
+	}
+	for i, expected := range expectedComments {
+		if comments[i] != expected {
+			t.Errorf("comment %d: expected %+v, got %+v", i, expected, comments[i])
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ShivankSharma070/go-interpreter/lint"
+)
+
+// lintIssue is how the lint subcommand reports an issue as JSON
+type lintIssue struct {
+	File      string `json:"file"`
+	Line      int    `json:"line"`
+	Column    int    `json:"column"`
+	EndLine   int    `json:"endLine"`
+	EndColumn int    `json:"endColumn"`
+	Rule      string `json:"rule"`
+	Message   string `json:"message"`
+}
+
+// lintCommand implements the lint subcommand. It checks the scripts it is given, directories are searched for
+// scripts, or its standard input without any. It fails when any issue is found.
+func lintCommand(args []string) int {
+	config := &lint.Config{Rules: make(map[string]bool)}
+	ruleList := func(enabled bool) func(string) error {
+		return func(value string) error {
+			for _, name := range splitList(value) {
+				config.Rules[name] = enabled
+			}
+			return config.Validate()
+		}
+	}
+
+	flags := flag.NewFlagSet("lint", flag.ContinueOnError)
+	asJSON := flags.Bool("json", false, "print the issues as a JSON array")
+	listRules := flags.Bool("rules", false, "list the rules and whether they run by default, without checking anything")
+	flags.Func("enable", "comma separated `rules` to run along with the default ones", ruleList(true))
+	flags.Func("disable", "comma separated `rules` not to run", ruleList(false))
+	flags.Func("globals", "comma separated `names` the host defines, they are not reported as undefined", func(value string) error {
+		config.Globals = append(config.Globals, splitList(value)...)
+		return nil
+	})
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "usage: %s lint [-json] [-enable rules] [-disable rules] [-globals names] [path ...]\n", os.Args[0])
+		fmt.Fprintf(flags.Output(), "       %s lint -rules\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if *listRules {
+		for _, rule := range lint.Rules {
+			state := "off"
+			if rule.Default {
+				state = "on"
+			}
+			fmt.Printf("%-14s %-3s %s\n", rule.Name, state, rule.Doc)
+		}
+		return 0
+	}
+
+	var issues []lintIssue
+	add := func(path string, source []byte) {
+		for _, issue := range lint.Source(string(source), config) {
+			issues = append(issues, lintIssue{
+				File:      path,
+				Line:      issue.Pos.Line,
+				Column:    issue.Pos.Column,
+				EndLine:   issue.End.Line,
+				EndColumn: issue.End.Column,
+				Rule:      issue.Rule,
+				Message:   issue.Message,
+			})
+		}
+	}
+
+	status := 0
+	if flags.NArg() == 0 {
+		source, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		add("<standard input>", source)
+	}
+	for _, root := range flags.Args() {
+		err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			// Scripts are found by their extension in directories, a file given by name is always checked
+			if entry.IsDir() || (path != root && filepath.Ext(path) != scriptExtension) {
+				return nil
+			}
+			source, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			add(path, source)
+			return nil
+		})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			status = 1
+		}
+	}
+
+	if *asJSON {
+		if issues == nil {
+			issues = []lintIssue{}
+		}
+		data, _ := json.MarshalIndent(issues, "", "  ")
+		fmt.Println(string(data))
+	} else {
+		for _, issue := range issues {
+			fmt.Printf("%s:%d:%d: %s (%s)\n", issue.File, issue.Line, issue.Column, issue.Message, issue.Rule)
+		}
+	}
+	if len(issues) > 0 {
+		status = 1
+	}
+	return status
+}
+
+// splitList splits a comma separated flag value, leaving out empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
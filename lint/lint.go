@@ -0,0 +1,238 @@
+// Package lint finds mistakes in monkey scripts without running them, like variables that are never defined or
+// builtins called with the wrong number of arguments. Every kind of mistake is checked by a rule, which can be
+// turned on or off.
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ShivankSharma070/go-interpreter/analysis"
+	"github.com/ShivankSharma070/go-interpreter/ast"
+	"github.com/ShivankSharma070/go-interpreter/evaluator"
+	"github.com/ShivankSharma070/go-interpreter/lexer"
+	"github.com/ShivankSharma070/go-interpreter/parser"
+	"github.com/ShivankSharma070/go-interpreter/token"
+)
+
+// Syntax is the rule of the issues reporting syntax errors, it cannot be turned off
+const Syntax = "syntax"
+
+// Issue is a mistake found in a script
+type Issue struct {
+	Rule    string
+	Pos     token.Position // Start of the code at fault
+	End     token.Position // End of the code at fault, exclusive
+	Message string
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%s: %s (%s)", i.Pos, i.Message, i.Rule)
+}
+
+// Rule checks a program for one kind of mistake
+type Rule struct {
+	Name    string
+	Doc     string
+	Default bool // Whether the rule runs unless it is turned off
+	check   func(p *pass)
+}
+
+// Rules are every rule there is, in the order their issues are reported at the same position
+var Rules = []*Rule{
+	{"undefined", "identifiers that name no variable, builtin or constant", true, checkUndefined},
+	{"arity", "builtins called with the wrong number of arguments", true, checkArity},
+	{"shadow", "variables and parameters named like a builtin or a constant", true, checkShadow},
+	{"unused", "lets in functions that are never used", true, checkUnused},
+	{"unused-global", "top level lets that are never used, off as modules export them", false, checkUnusedGlobals},
+	{"unreachable", "statements after a return", true, checkUnreachable},
+}
+
+// LookupRule returns the rule called name
+func LookupRule(name string) (*Rule, bool) {
+	for _, rule := range Rules {
+		if rule.Name == name {
+			return rule, true
+		}
+	}
+	return nil, false
+}
+
+// Config chooses the rules to run. The zero value runs the default rules.
+type Config struct {
+	Rules   map[string]bool // Rules turned on or off by name, the others keep their default
+	Globals []string        // Names defined by the host before the script runs, they are never undefined
+}
+
+// Validate reports the first rule of the config that does not exist
+func (c *Config) Validate() error {
+	for name := range c.Rules {
+		if _, ok := LookupRule(name); !ok {
+			return fmt.Errorf("unknown rule %q", name)
+		}
+	}
+	return nil
+}
+
+func (c *Config) enabled(rule *Rule) bool {
+	if enabled, ok := c.Rules[rule.Name]; ok {
+		return enabled
+	}
+	return rule.Default
+}
+
+// Source parses and checks the source of a script. A script with syntax errors is not checked any further, its
+// issues are the syntax errors.
+func Source(source string, config *Config) []Issue {
+	p := parser.New(lexer.New(source))
+	program := p.ParseProgram()
+	if errors := p.ErrorList(); len(errors) != 0 {
+		issues := make([]Issue, len(errors))
+		for i, err := range errors {
+			issues[i] = Issue{Rule: Syntax, Pos: err.Pos, End: err.Pos, Message: err.Message}
+		}
+		return issues
+	}
+	return Check(program, config)
+}
+
+// Check runs the rules of config on program and returns the issues found, sorted by position
+func Check(program *ast.Program, config *Config) []Issue {
+	if config == nil {
+		config = &Config{}
+	}
+	p := &pass{program: program, info: analysis.Analyze(program), globals: make(map[string]bool)}
+	for _, name := range config.Globals {
+		p.globals[name] = true
+	}
+
+	for _, rule := range Rules {
+		if config.enabled(rule) {
+			p.rule = rule
+			rule.check(p)
+		}
+	}
+
+	// Issues at the same position stay in the order of their rules
+	sort.SliceStable(p.issues, func(i, j int) bool {
+		a, b := p.issues[i].Pos, p.issues[j].Pos
+		return a.Line < b.Line || a.Line == b.Line && a.Column < b.Column
+	})
+	return p.issues
+}
+
+// pass is one run of the rules over a program
+type pass struct {
+	program *ast.Program
+	info    *analysis.Info
+	globals map[string]bool
+	rule    *Rule
+	issues  []Issue
+}
+
+func (p *pass) report(node ast.Node, format string, args ...any) {
+	p.issues = append(p.issues, Issue{
+		Rule:    p.rule.Name,
+		Pos:     ast.Start(node),
+		End:     ast.End(node),
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// predefined reports whether name is defined without a let, by the language or by the host
+func (p *pass) predefined(name string) bool {
+	if _, ok := evaluator.LookupBuiltin(name); ok {
+		return true
+	}
+	if _, ok := evaluator.LookupConstant(name); ok {
+		return true
+	}
+	return name == "quote" || name == "unquote" || p.globals[name]
+}
+
+func checkUndefined(p *pass) {
+	for _, iden := range p.info.Free {
+		if !p.predefined(iden.Value) {
+			p.report(iden, "identifier not found: %s", iden.Value)
+		}
+	}
+}
+
+func checkArity(p *pass) {
+	ast.Inspect(p.program, func(node ast.Node) bool {
+		call, ok := node.(*ast.CallExpression)
+		if !ok {
+			return true
+		}
+		// Only names that are not variables call a builtin
+		iden, ok := call.Function.(*ast.Identifier)
+		if !ok || p.info.Refs[iden] != nil || p.globals[iden.Value] {
+			return true
+		}
+		arity, ok := evaluator.BuiltinArity(iden.Value)
+		if !ok || arity.Accepts(len(call.Argument)) {
+			return true
+		}
+		want := "want=" + arity.String()
+		if arity.Max < 0 {
+			want = "want " + arity.String()
+		}
+		p.report(call, "wrong number of arguments to `%s`. got=%d, %s", iden.Value, len(call.Argument), want)
+		return true
+	})
+}
+
+func checkShadow(p *pass) {
+	for _, binding := range p.info.Bindings {
+		name := binding.Name.Value
+		if _, ok := evaluator.LookupBuiltin(name); ok {
+			p.report(binding.Name, "%s `%s` shadows the builtin of the same name", binding.Kind, name)
+		} else if _, ok := evaluator.LookupConstant(name); ok {
+			p.report(binding.Name, "%s `%s` shadows the constant of the same name", binding.Kind, name)
+		}
+	}
+}
+
+// Lets whose names start with an underscore are meant to be unused
+func unused(binding *analysis.Binding) bool {
+	return binding.Let != nil && len(binding.Uses) == 0 && !strings.HasPrefix(binding.Name.Value, "_")
+}
+
+func checkUnused(p *pass) {
+	for _, binding := range p.info.Bindings {
+		if binding.Kind == analysis.Local && unused(binding) {
+			p.report(binding.Name, "`%s` is never used", binding.Name.Value)
+		}
+	}
+}
+
+func checkUnusedGlobals(p *pass) {
+	for _, binding := range p.info.Bindings {
+		if binding.Kind == analysis.Global && unused(binding) {
+			p.report(binding.Name, "`%s` is never used", binding.Name.Value)
+		}
+	}
+}
+
+// checkUnreachable reports the first statement after a return in every block, the ones after it are unreachable
+// as well
+func checkUnreachable(p *pass) {
+	check := func(statements []ast.Statement) {
+		for i, statement := range statements[:max(len(statements)-1, 0)] {
+			if _, ok := statement.(*ast.ReturnStatement); ok {
+				p.report(statements[i+1], "unreachable code after return")
+				return
+			}
+		}
+	}
+	ast.Inspect(p.program, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.Program:
+			check(node.Statements)
+		case *ast.BlockStatement:
+			check(node.Statements)
+		}
+		return true
+	})
+}
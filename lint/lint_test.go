@@ -0,0 +1,100 @@
+package lint
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRules(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"let a = 1; puts(a);", nil},
+		{"puts(b);", []string{"1:6: identifier not found: b (undefined)"}},
+		{"let f = fn() { g() }; let g = fn() { 1 }; puts(f(), PI);", nil},
+		{"let f = fn(x) { x + y }; puts(f);", []string{"1:21: identifier not found: y (undefined)"}},
+		{"let q = quote(a + unquote(b)); puts(q);", []string{"1:27: identifier not found: b (undefined)"}},
+		{"puts(len(1, 2));", []string{"1:6: wrong number of arguments to `len`. got=2, want=1 (arity)"}},
+		{"exit(1, 2);", []string{"1:1: wrong number of arguments to `exit`. got=2, want=0 or 1 (arity)"}},
+		{"puts(zip([1]));", []string{"1:6: wrong number of arguments to `zip`. got=1, want at least 2 (arity)"}},
+		{"puts(range(), range(1, 2, 3, 4));", []string{
+			"1:6: wrong number of arguments to `range`. got=0, want=1 to 3 (arity)",
+			"1:15: wrong number of arguments to `range`. got=4, want=1 to 3 (arity)",
+		}},
+		{"let len = fn(a, b) { a }; puts(len(1, 2));", []string{
+			"1:5: global `len` shadows the builtin of the same name (shadow)",
+		}},
+		{"let f = fn(first, PI) { first + PI }; puts(f);", []string{
+			"1:12: parameter `first` shadows the builtin of the same name (shadow)",
+			"1:19: parameter `PI` shadows the constant of the same name (shadow)",
+		}},
+		{"let f = fn() { let a = 1; let _b = 2; 3 }; puts(f);", []string{"1:20: `a` is never used (unused)"}},
+		{"let unused = 1;", nil},
+		{"let f = fn(x) { return x; puts(x); x };\nreturn f;\nf(1);", []string{
+			"1:27: unreachable code after return (unreachable)",
+			"3:1: unreachable code after return (unreachable)",
+		}},
+		{"let f = fn(x) { if (x) { return 1; } 2 }; puts(f);", nil},
+		{"let x = 1;\nlet y = ;", []string{"2:9: No prefix parsing function found for ; (syntax)"}},
+	}
+
+	for _, tt := range tests {
+		checkIssues(t, tt.input, nil, tt.expected)
+	}
+}
+
+func TestConfig(t *testing.T) {
+	input := "let a = 1; let len = 2; puts(host);"
+	tests := []struct {
+		config   *Config
+		expected []string
+	}{
+		{&Config{}, []string{
+			"1:16: global `len` shadows the builtin of the same name (shadow)",
+			"1:30: identifier not found: host (undefined)",
+		}},
+		{&Config{Globals: []string{"host"}, Rules: map[string]bool{"shadow": false}}, nil},
+		{&Config{Globals: []string{"host"}, Rules: map[string]bool{"unused-global": true}}, []string{
+			"1:5: `a` is never used (unused-global)",
+			"1:16: global `len` shadows the builtin of the same name (shadow)",
+			"1:16: `len` is never used (unused-global)",
+		}},
+	}
+
+	for _, tt := range tests {
+		checkIssues(t, input, tt.config, tt.expected)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	if err := (&Config{Rules: map[string]bool{"unused": false}}).Validate(); err != nil {
+		t.Errorf("valid config rejected: %s", err)
+	}
+	err := (&Config{Rules: map[string]bool{"typo": true}}).Validate()
+	if err == nil || err.Error() != `unknown rule "typo"` {
+		t.Errorf("wrong error. got=%v", err)
+	}
+}
+
+func TestIssueSpans(t *testing.T) {
+	issues := Source("puts(len(\"a\", \"b\"));", nil)
+	if len(issues) != 1 {
+		t.Fatalf("wrong number of issues. got=%v", issues)
+	}
+	if issues[0].Pos.String() != "1:6" || issues[0].End.String() != "1:19" {
+		t.Errorf("wrong span. got=%s-%s", issues[0].Pos, issues[0].End)
+	}
+}
+
+func checkIssues(t *testing.T, input string, config *Config, expected []string) {
+	t.Helper()
+	issues := Source(input, config)
+	got := make([]string, len(issues))
+	for i, issue := range issues {
+		got[i] = issue.String()
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("wrong issues for %q.\nexpected:\n%s\ngot:\n%s", input, strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/ShivankSharma070/go-interpreter/lsp"
+)
+
+// lspCommand implements the lsp subcommand, a language server talking to an editor over the standard input and output
+func lspCommand(args []string) int {
+	flags := flag.NewFlagSet("lsp", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "usage: %s lsp\n", os.Args[0])
+		fmt.Fprintln(flags.Output(), "Serves the Language Server Protocol over the standard input and output.")
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 0 {
+		flags.Usage()
+		return 2
+	}
+
+	if err := lsp.NewServer(os.Stdin, os.Stdout).Run(); err != nil {
+		fmt.Fprintln(os.Stderr, "lsp:", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,86 @@
+package lsp
+
+import (
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/ShivankSharma070/go-interpreter/analysis"
+	"github.com/ShivankSharma070/go-interpreter/ast"
+	"github.com/ShivankSharma070/go-interpreter/lexer"
+	"github.com/ShivankSharma070/go-interpreter/parser"
+	"github.com/ShivankSharma070/go-interpreter/token"
+)
+
+// document is an open script, parsed and analyzed every time it changes. A script with syntax errors still has the
+// part of the tree the parser could build.
+type document struct {
+	uri     string
+	version int
+	text    string
+	lines   []string
+	program *ast.Program
+	errors  []parser.Error
+	info    *analysis.Info
+}
+
+func newDocument(uri, text string, version int) *document {
+	p := parser.New(lexer.New(text))
+	program := p.ParseProgram()
+	return &document{
+		uri:     uri,
+		version: version,
+		text:    text,
+		lines:   strings.Split(text, "\n"),
+		program: program,
+		errors:  p.ErrorList(),
+		info:    analysis.Analyze(program),
+	}
+}
+
+// position converts a position of the lexer, in bytes from 1, to one of LSP, in UTF-16 code units from 0
+func (d *document) position(pos token.Position) Position {
+	if pos.Line < 1 || pos.Line > len(d.lines) {
+		return Position{Line: max(pos.Line-1, 0)}
+	}
+	line := d.lines[pos.Line-1]
+	column := min(max(pos.Column-1, 0), len(line))
+	return Position{Line: pos.Line - 1, Character: utf16Length(line[:column])}
+}
+
+// tokenPosition converts a position of LSP to one of the lexer, the opposite of position
+func (d *document) tokenPosition(pos Position) token.Position {
+	if pos.Line < 0 || pos.Line >= len(d.lines) {
+		return token.Position{Line: pos.Line + 1, Column: 1}
+	}
+	line := d.lines[pos.Line]
+	offset, units := 0, 0
+	for offset < len(line) && units < pos.Character {
+		r, size := utf8.DecodeRuneInString(line[offset:])
+		units += utf16.RuneLen(r)
+		offset += size
+	}
+	return token.Position{Line: pos.Line + 1, Column: offset + 1}
+}
+
+func (d *document) nodeRange(node ast.Node) Range {
+	return Range{Start: d.position(ast.Start(node)), End: d.position(ast.End(node))}
+}
+
+func (d *document) location(node ast.Node) Location {
+	return Location{URI: d.uri, Range: d.nodeRange(node)}
+}
+
+// fullRange covers the whole text of the document
+func (d *document) fullRange() Range {
+	last := len(d.lines) - 1
+	return Range{End: Position{Line: last, Character: utf16Length(d.lines[last])}}
+}
+
+func utf16Length(s string) int {
+	length := 0
+	for _, r := range s {
+		length += utf16.RuneLen(r)
+	}
+	return length
+}
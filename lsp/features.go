@@ -0,0 +1,276 @@
+package lsp
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ShivankSharma070/go-interpreter/analysis"
+	"github.com/ShivankSharma070/go-interpreter/ast"
+	"github.com/ShivankSharma070/go-interpreter/evaluator"
+	"github.com/ShivankSharma070/go-interpreter/formatter"
+	"github.com/ShivankSharma070/go-interpreter/object"
+)
+
+// Values longer than this are left out of hovers
+const maxHoverValue = 80
+
+// diagnostics reports the syntax errors of a document, at the token each was found at
+func diagnostics(doc *document) []Diagnostic {
+	diagnostics := []Diagnostic{}
+	for _, err := range doc.errors {
+		pos := doc.position(err.Pos)
+		diagnostics = append(diagnostics, Diagnostic{
+			Range:    Range{Start: pos, End: pos},
+			Severity: SeverityError,
+			Source:   "monkey",
+			Message:  err.Message,
+		})
+	}
+	return diagnostics
+}
+
+// binding finds the identifier at a position of a request and the binding it names, if any
+func (s *Server) binding(params TextDocumentPositionParams) (*document, *ast.Identifier, *analysis.Binding, error) {
+	doc, err := s.document(params.TextDocument.URI)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	iden := doc.info.IdentifierAt(doc.tokenPosition(params.Position))
+	if iden == nil {
+		return doc, nil, nil, nil
+	}
+	return doc, iden, doc.info.Refs[iden], nil
+}
+
+func (s *Server) definition(params json.RawMessage) (any, error) {
+	p, err := decode[TextDocumentPositionParams](params)
+	if err != nil {
+		return nil, err
+	}
+	doc, _, binding, err := s.binding(p)
+	if err != nil || binding == nil {
+		return nil, err
+	}
+	return doc.location(binding.Name), nil
+}
+
+func (s *Server) references(params json.RawMessage) (any, error) {
+	p, err := decode[ReferenceParams](params)
+	if err != nil {
+		return nil, err
+	}
+	doc, _, binding, err := s.binding(p.TextDocumentPositionParams)
+	if err != nil || binding == nil {
+		return nil, err
+	}
+
+	locations := []Location{}
+	if p.Context.IncludeDeclaration {
+		locations = append(locations, doc.location(binding.Name))
+	}
+	for _, use := range binding.Uses {
+		locations = append(locations, doc.location(use))
+	}
+	return locations, nil
+}
+
+func (s *Server) hover(params json.RawMessage) (any, error) {
+	p, err := decode[TextDocumentPositionParams](params)
+	if err != nil {
+		return nil, err
+	}
+	doc, iden, binding, err := s.binding(p)
+	if err != nil || iden == nil {
+		return nil, err
+	}
+
+	var code, detail string
+	switch {
+	case binding != nil && binding.Kind == analysis.Parameter:
+		code = "parameter " + iden.Value
+		detail = "of " + signature(binding.Scope)
+	case binding != nil:
+		code = fmt.Sprintf("let %s = %s", iden.Value, summary(binding.Let.Value))
+		detail = binding.Kind.String()
+		if typ := literalType(binding.Let.Value); typ != "" {
+			detail += " " + strings.ToLower(string(typ))
+		} else {
+			detail += " variable"
+		}
+	default:
+		if constant, ok := evaluator.LookupConstant(iden.Value); ok {
+			code = fmt.Sprintf("%s = %s", iden.Value, constant.Inspect())
+			detail = "constant " + string(constant.Type())
+		} else if _, ok := evaluator.LookupBuiltin(iden.Value); ok {
+			code = "builtin " + iden.Value
+			detail = string(object.BUILTIN_OBJ)
+		} else {
+			return nil, nil
+		}
+	}
+
+	return Hover{
+		Contents: MarkupContent{Kind: "markdown", Value: "```monkey\n" + code + "\n```\n" + detail},
+		Range:    doc.nodeRange(iden),
+	}, nil
+}
+
+// summary shows a value in a hover, functions by their parameters and long values not at all
+func summary(value ast.Expression) string {
+	switch value := value.(type) {
+	case nil:
+		return "..."
+	case *ast.FunctionExpression, *ast.MacroLiteral:
+		return signature(value)
+	}
+	code := formatter.Node(value)
+	if len(code) > maxHoverValue || strings.Contains(code, "\n") {
+		return "..."
+	}
+	return code
+}
+
+// signature shows the parameters of a function or a macro
+func signature(node ast.Node) string {
+	var keyword string
+	var params []*ast.Identifier
+	switch node := node.(type) {
+	case *ast.FunctionExpression:
+		keyword, params = "fn", node.Parameters
+	case *ast.MacroLiteral:
+		keyword, params = "macro", node.Parameters
+	default:
+		return ""
+	}
+	names := []string{}
+	for _, param := range params {
+		names = append(names, param.Value)
+	}
+	return keyword + "(" + strings.Join(names, ", ") + ")"
+}
+
+// literalType is the type of the values of a literal, empty when value is not one
+func literalType(value ast.Expression) object.ObjectType {
+	switch value.(type) {
+	case *ast.IntegerLiteral:
+		return object.INTEGER_OBJ
+	case *ast.FloatLiteral:
+		return object.FLOAT_OBJ
+	case *ast.StringLiteral:
+		return object.STRING_OBJ
+	case *ast.BoolExpression:
+		return object.BOOLEAN_OBJ
+	case *ast.ArrayLiteral:
+		return object.ARRAY_OBJ
+	case *ast.HashLiteral:
+		return object.HASH_OBJ
+	case *ast.FunctionExpression:
+		return object.FUNCTION_OBJ
+	case *ast.MacroLiteral:
+		return object.MACRO_OBJ
+	}
+	return ""
+}
+
+func (s *Server) documentSymbol(params json.RawMessage) (any, error) {
+	p, err := decode[DocumentSymbolParams](params)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := s.document(p.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+	symbols := doc.symbols(doc.program)
+	if symbols == nil {
+		symbols = []DocumentSymbol{}
+	}
+	return symbols, nil
+}
+
+// symbols lists the lets in node, the lets in the value of a let are its children
+func (d *document) symbols(node ast.Node) []DocumentSymbol {
+	var symbols []DocumentSymbol
+	ast.Inspect(node, func(node ast.Node) bool {
+		let, ok := node.(*ast.LetStatement)
+		if !ok || let.Name == nil {
+			return true
+		}
+
+		symbol := DocumentSymbol{
+			Name:           let.Name.Value,
+			Kind:           SymbolVariable,
+			Range:          d.nodeRange(let),
+			SelectionRange: d.nodeRange(let.Name),
+			Children:       d.symbols(let.Value),
+		}
+		switch let.Value.(type) {
+		case *ast.FunctionExpression, *ast.MacroLiteral:
+			symbol.Kind = SymbolFunction
+			symbol.Detail = signature(let.Value)
+		default:
+			symbol.Detail = string(literalType(let.Value))
+		}
+		symbols = append(symbols, symbol)
+		return false
+	})
+	return symbols
+}
+
+func (s *Server) completion(params json.RawMessage) (any, error) {
+	p, err := decode[TextDocumentPositionParams](params)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := s.document(p.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+
+	// Variables hide the builtins and constants of the same name
+	items := []CompletionItem{}
+	seen := make(map[string]bool)
+	for _, binding := range doc.info.Visible(doc.tokenPosition(p.Position)) {
+		item := CompletionItem{Label: binding.Name.Value, Kind: CompletionVariable, Detail: binding.Kind.String()}
+		if binding.Let != nil {
+			if sig := signature(binding.Let.Value); sig != "" {
+				item.Kind, item.Detail = CompletionFunction, sig
+			}
+		}
+		seen[item.Label] = true
+		items = append(items, item)
+	}
+	for _, name := range evaluator.BuiltinNames() {
+		if !seen[name] {
+			items = append(items, CompletionItem{Label: name, Kind: CompletionFunction, Detail: "builtin"})
+		}
+	}
+	for _, name := range evaluator.ConstantNames() {
+		if !seen[name] {
+			items = append(items, CompletionItem{Label: name, Kind: CompletionConstant, Detail: "constant"})
+		}
+	}
+	return items, nil
+}
+
+func (s *Server) formatting(params json.RawMessage) (any, error) {
+	p, err := decode[DocumentFormattingParams](params)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := s.document(p.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+
+	// A script with syntax errors is left alone, the errors are already reported
+	formatted, err := formatter.Format(doc.text)
+	if err != nil {
+		return nil, nil
+	}
+	if formatted == doc.text {
+		return []TextEdit{}, nil
+	}
+	return []TextEdit{{Range: doc.fullRange(), NewText: formatted}}, nil
+}
@@ -0,0 +1,210 @@
+// Package lsp implements a Language Server Protocol server for monkey scripts. It keeps the scripts an editor has
+// open, reports their syntax errors and answers questions about the variables they use.
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrNoShutdown is returned by Run when the client exits without asking the server to shut down first
+var ErrNoShutdown = errors.New("exit before shutdown")
+
+// Server serves one client, reading its messages from in and writing to out
+type Server struct {
+	in        *bufio.Reader
+	out       io.Writer
+	documents map[string]*document
+	shutdown  bool
+}
+
+func NewServer(in io.Reader, out io.Writer) *Server {
+	return &Server{in: bufio.NewReader(in), out: out, documents: make(map[string]*document)}
+}
+
+type handler func(s *Server, params json.RawMessage) (any, error)
+
+// Requests and notifications the server understands, anything else is answered with an error or ignored
+var handlers = map[string]handler{
+	"initialize":                  (*Server).initialize,
+	"initialized":                 ignore,
+	"shutdown":                    (*Server).shutdownRequest,
+	"textDocument/didOpen":        (*Server).didOpen,
+	"textDocument/didChange":      (*Server).didChange,
+	"textDocument/didClose":       (*Server).didClose,
+	"textDocument/didSave":        ignore,
+	"textDocument/definition":     (*Server).definition,
+	"textDocument/references":     (*Server).references,
+	"textDocument/hover":          (*Server).hover,
+	"textDocument/documentSymbol": (*Server).documentSymbol,
+	"textDocument/completion":     (*Server).completion,
+	"textDocument/formatting":     (*Server).formatting,
+}
+
+// Run serves messages until the client sends exit. It returns ErrNoShutdown when shutdown was not requested
+// before, and the error reading the input when it ends early.
+func (s *Server) Run() error {
+	for {
+		body, err := readMessage(s.in)
+		if err != nil {
+			return err
+		}
+
+		var msg message
+		if err := json.Unmarshal(body, &msg); err != nil {
+			s.reply(nil, nil, &responseError{Code: codeParseError, Message: err.Error()})
+			continue
+		}
+		if msg.Method == "exit" {
+			if !s.shutdown {
+				return ErrNoShutdown
+			}
+			return nil
+		}
+		s.handle(&msg)
+	}
+}
+
+func (s *Server) handle(msg *message) {
+	handle, ok := handlers[msg.Method]
+	if msg.ID == nil {
+		// Notifications have no answer, not even when they fail
+		if ok && !s.shutdown {
+			handle(s, msg.Params)
+		}
+		return
+	}
+
+	switch {
+	case msg.Method == "":
+		s.reply(msg.ID, nil, &responseError{Code: codeInvalidRequest, Message: "request without a method"})
+	case !ok:
+		s.reply(msg.ID, nil, &responseError{Code: codeMethodNotFound, Message: fmt.Sprintf("method not found: %s", msg.Method)})
+	case s.shutdown:
+		s.reply(msg.ID, nil, &responseError{Code: codeInvalidRequest, Message: "the server is shutting down"})
+	default:
+		result, err := handle(s, msg.Params)
+		s.reply(msg.ID, result, err)
+	}
+}
+
+func (s *Server) reply(id *json.RawMessage, result any, err error) {
+	if id == nil {
+		null := json.RawMessage("null")
+		id = &null
+	}
+	msg := &message{ID: id}
+	if err != nil {
+		var respErr *responseError
+		if !errors.As(err, &respErr) {
+			respErr = &responseError{Code: codeInternalError, Message: err.Error()}
+		}
+		msg.Error = respErr
+	} else {
+		data, err := json.Marshal(result)
+		if err != nil {
+			msg.Error = &responseError{Code: codeInternalError, Message: err.Error()}
+		} else {
+			msg.Result = data
+		}
+	}
+	s.send(msg)
+}
+
+func (s *Server) notify(method string, params any) {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return
+	}
+	s.send(&message{Method: method, Params: data})
+}
+
+// send writes a message, a client that went away is noticed when reading from it
+func (s *Server) send(msg *message) {
+	writeMessage(s.out, msg)
+}
+
+// decode unmarshals the params of a request into a value of type T
+func decode[T any](params json.RawMessage) (T, error) {
+	var value T
+	if err := json.Unmarshal(params, &value); err != nil {
+		return value, &responseError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	return value, nil
+}
+
+func ignore(*Server, json.RawMessage) (any, error) {
+	return nil, nil
+}
+
+func (s *Server) initialize(json.RawMessage) (any, error) {
+	return map[string]any{
+		"capabilities": map[string]any{
+			// Open and close notifications, with the full text on every change
+			"textDocumentSync":           map[string]any{"openClose": true, "change": 1},
+			"definitionProvider":         true,
+			"referencesProvider":         true,
+			"hoverProvider":              true,
+			"documentSymbolProvider":     true,
+			"completionProvider":         map[string]any{},
+			"documentFormattingProvider": true,
+		},
+		"serverInfo": map[string]any{"name": "monkey"},
+	}, nil
+}
+
+func (s *Server) shutdownRequest(json.RawMessage) (any, error) {
+	s.shutdown = true
+	return nil, nil
+}
+
+func (s *Server) didOpen(params json.RawMessage) (any, error) {
+	p, err := decode[DidOpenTextDocumentParams](params)
+	if err != nil {
+		return nil, err
+	}
+	s.open(newDocument(p.TextDocument.URI, p.TextDocument.Text, p.TextDocument.Version))
+	return nil, nil
+}
+
+func (s *Server) didChange(params json.RawMessage) (any, error) {
+	p, err := decode[DidChangeTextDocumentParams](params)
+	if err != nil || len(p.ContentChanges) == 0 {
+		return nil, err
+	}
+	// Every change is the whole text, the last one is current
+	text := p.ContentChanges[len(p.ContentChanges)-1].Text
+	s.open(newDocument(p.TextDocument.URI, text, p.TextDocument.Version))
+	return nil, nil
+}
+
+func (s *Server) didClose(params json.RawMessage) (any, error) {
+	p, err := decode[DidCloseTextDocumentParams](params)
+	if err != nil {
+		return nil, err
+	}
+	delete(s.documents, p.TextDocument.URI)
+	s.notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{URI: p.TextDocument.URI, Diagnostics: []Diagnostic{}})
+	return nil, nil
+}
+
+func (s *Server) open(doc *document) {
+	s.documents[doc.uri] = doc
+	s.notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{
+		URI:         doc.uri,
+		Version:     doc.version,
+		Diagnostics: diagnostics(doc),
+	})
+}
+
+// document returns the open document uri, requests about others fail
+func (s *Server) document(uri string) (*document, error) {
+	doc, ok := s.documents[uri]
+	if !ok {
+		return nil, &responseError{Code: codeInvalidParams, Message: fmt.Sprintf("document not open: %s", uri)}
+	}
+	return doc, nil
+}
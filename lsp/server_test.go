@@ -0,0 +1,378 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+const uri = "file:///test.monkey"
+
+// script is the document of the tests, its positions below are counted from 0
+const script = `let total = 0;
+let add = fn(a, b) {
+  let both = a + b;
+  both
+};
+let result = add(total, len("é"));
+puts(PI)
+`
+
+// session collects the messages of a client, then runs a server over them
+type session struct {
+	in     bytes.Buffer
+	nextID int
+}
+
+func (s *session) request(method string, params any) int {
+	s.nextID++
+	id := json.RawMessage(strings.TrimSpace(string(mustMarshal(s.nextID))))
+	s.send(&message{ID: &id, Method: method, Params: mustMarshal(params)})
+	return s.nextID
+}
+
+func (s *session) notify(method string, params any) {
+	s.send(&message{Method: method, Params: mustMarshal(params)})
+}
+
+func (s *session) send(msg *message) {
+	if err := writeMessage(&s.in, msg); err != nil {
+		panic(err)
+	}
+}
+
+// run serves the messages and returns the responses by id, the notifications of the server and what Run returned
+func (s *session) run(t *testing.T) (map[int]message, []message, error) {
+	t.Helper()
+	var out bytes.Buffer
+	err := NewServer(&s.in, &out).Run()
+
+	responses := make(map[int]message)
+	var notifications []message
+	r := bufio.NewReader(&out)
+	for {
+		body, readErr := readMessage(r)
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			t.Fatalf("server wrote an invalid message: %s", readErr)
+		}
+		var msg message
+		if err := json.Unmarshal(body, &msg); err != nil {
+			t.Fatalf("server wrote invalid json %s: %s", body, err)
+		}
+		if msg.ID == nil {
+			notifications = append(notifications, msg)
+			continue
+		}
+		var id int
+		json.Unmarshal(*msg.ID, &id)
+		responses[id] = msg
+	}
+	return responses, notifications, err
+}
+
+func mustMarshal(v any) json.RawMessage {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+func at(line, character int) TextDocumentPositionParams {
+	return TextDocumentPositionParams{TextDocument: TextDocumentIdentifier{URI: uri}, Position: Position{line, character}}
+}
+
+func span(line, start, end int) Range {
+	return Range{Start: Position{line, start}, End: Position{line, end}}
+}
+
+func result[T any](t *testing.T, msg message) T {
+	t.Helper()
+	if msg.Error != nil {
+		t.Fatalf("request failed: %s", msg.Error.Message)
+	}
+	var value T
+	if err := json.Unmarshal(msg.Result, &value); err != nil {
+		t.Fatalf("invalid result %s: %s", msg.Result, err)
+	}
+	return value
+}
+
+// openScript starts a session with the script of the tests open
+func openScript(text string) *session {
+	s := &session{}
+	s.request("initialize", map[string]any{})
+	s.notify("initialized", map[string]any{})
+	s.notify("textDocument/didOpen", DidOpenTextDocumentParams{TextDocument: TextDocumentItem{URI: uri, LanguageID: "monkey", Version: 1, Text: text}})
+	return s
+}
+
+func closeSession(s *session) {
+	s.request("shutdown", nil)
+	s.notify("exit", nil)
+}
+
+func TestDefinitionAndReferences(t *testing.T) {
+	s := openScript(script)
+	definitions := []struct {
+		id       int
+		expected *Location
+	}{
+		// both in the body of add
+		{s.request("textDocument/definition", at(3, 3)), &Location{uri, span(2, 6, 10)}},
+		// a parameter
+		{s.request("textDocument/definition", at(2, 13)), &Location{uri, span(1, 13, 14)}},
+		// total, a global used in an argument
+		{s.request("textDocument/definition", at(5, 18)), &Location{uri, span(0, 4, 9)}},
+		// len is a builtin, it has no definition
+		{s.request("textDocument/definition", at(5, 26)), nil},
+		// Outside of any identifier
+		{s.request("textDocument/definition", at(0, 12)), nil},
+	}
+	withDeclaration := ReferenceParams{TextDocumentPositionParams: at(1, 5)}
+	withDeclaration.Context.IncludeDeclaration = true
+	references := s.request("textDocument/references", withDeclaration)
+	usesOnly := s.request("textDocument/references", ReferenceParams{TextDocumentPositionParams: at(1, 16)})
+	closeSession(s)
+
+	responses, _, err := s.run(t)
+	if err != nil {
+		t.Fatalf("Run failed: %s", err)
+	}
+	for _, tt := range definitions {
+		got := result[*Location](t, responses[tt.id])
+		if (got == nil) != (tt.expected == nil) || got != nil && *got != *tt.expected {
+			t.Errorf("wrong definition for request %d. want=%v, got=%v", tt.id, tt.expected, got)
+		}
+	}
+
+	got := result[[]Location](t, responses[references])
+	expected := []Location{{uri, span(1, 4, 7)}, {uri, span(5, 13, 16)}}
+	if !equal(got, expected) {
+		t.Errorf("wrong references of add. want=%v, got=%v", expected, got)
+	}
+	got = result[[]Location](t, responses[usesOnly])
+	expected = []Location{{uri, span(2, 17, 18)}}
+	if !equal(got, expected) {
+		t.Errorf("wrong references of b. want=%v, got=%v", expected, got)
+	}
+}
+
+func TestHover(t *testing.T) {
+	s := openScript(script)
+	tests := []struct {
+		id       int
+		expected string
+	}{
+		{s.request("textDocument/hover", at(0, 6)), "```monkey\nlet total = 0\n```\nglobal integer"},
+		{s.request("textDocument/hover", at(5, 14)), "```monkey\nlet add = fn(a, b)\n```\nglobal function"},
+		{s.request("textDocument/hover", at(2, 7)), "```monkey\nlet both = a + b\n```\nlocal variable"},
+		{s.request("textDocument/hover", at(1, 13)), "```monkey\nparameter a\n```\nof fn(a, b)"},
+		{s.request("textDocument/hover", at(5, 25)), "```monkey\nbuiltin len\n```\nBUILTIN"},
+		{s.request("textDocument/hover", at(6, 6)), "```monkey\nPI = 3.141592653589793\n```\nconstant FLOAT"},
+		{s.request("textDocument/hover", at(4, 0)), ""},
+	}
+	closeSession(s)
+
+	responses, _, err := s.run(t)
+	if err != nil {
+		t.Fatalf("Run failed: %s", err)
+	}
+	for _, tt := range tests {
+		got := ""
+		if hover := result[*Hover](t, responses[tt.id]); hover != nil {
+			got = hover.Contents.Value
+		}
+		if got != tt.expected {
+			t.Errorf("wrong hover for request %d. want=%q, got=%q", tt.id, tt.expected, got)
+		}
+	}
+}
+
+func TestDocumentSymbols(t *testing.T) {
+	s := openScript(script)
+	id := s.request("textDocument/documentSymbol", DocumentSymbolParams{TextDocument: TextDocumentIdentifier{URI: uri}})
+	closeSession(s)
+
+	responses, _, _ := s.run(t)
+	var got []string
+	var walk func(symbols []DocumentSymbol, indent string)
+	walk = func(symbols []DocumentSymbol, indent string) {
+		for _, symbol := range symbols {
+			got = append(got, indent+symbol.Name+" "+symbol.Detail)
+			walk(symbol.Children, indent+"  ")
+		}
+	}
+	symbols := result[[]DocumentSymbol](t, responses[id])
+	walk(symbols, "")
+
+	expected := []string{"total INTEGER", "add fn(a, b)", "  both ", "result "}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("wrong symbols.\nwant=%q\ngot =%q", expected, got)
+	}
+	if symbols[1].Range != (Range{Start: Position{1, 0}, End: Position{4, 1}}) || symbols[1].SelectionRange != span(1, 4, 7) {
+		t.Errorf("wrong ranges of add, got %v and %v", symbols[1].Range, symbols[1].SelectionRange)
+	}
+}
+
+func TestCompletion(t *testing.T) {
+	s := openScript(script)
+	inFunction := s.request("textDocument/completion", at(3, 2))
+	atTop := s.request("textDocument/completion", at(0, 0))
+	closeSession(s)
+
+	responses, _, _ := s.run(t)
+	labels := func(id int) []string {
+		var labels []string
+		for _, item := range result[[]CompletionItem](t, responses[id]) {
+			labels = append(labels, item.Label)
+		}
+		return labels
+	}
+
+	got := labels(inFunction)
+	if strings.Join(got[:6], " ") != "both b a result add total" {
+		t.Errorf("wrong variables in add, got %v", got[:6])
+	}
+	if !contains(got, "len") || !contains(got, "PI") || contains(got[6:], "add") {
+		t.Errorf("builtins and constants missing after the variables, got %v", got)
+	}
+	if got := labels(atTop); contains(got, "both") || contains(got, "a") {
+		t.Errorf("locals of add visible outside of it, got %v", got)
+	}
+}
+
+func TestFormatting(t *testing.T) {
+	s := openScript("let  x=1\nputs( x )")
+	id := s.request("textDocument/formatting", DocumentFormattingParams{TextDocument: TextDocumentIdentifier{URI: uri}})
+	s.notify("textDocument/didChange", map[string]any{
+		"textDocument":   map[string]any{"uri": uri, "version": 2},
+		"contentChanges": []map[string]any{{"text": "let x = ;"}},
+	})
+	broken := s.request("textDocument/formatting", DocumentFormattingParams{TextDocument: TextDocumentIdentifier{URI: uri}})
+	closeSession(s)
+
+	responses, _, _ := s.run(t)
+	edits := result[[]TextEdit](t, responses[id])
+	expected := []TextEdit{{Range: span(0, 0, 0), NewText: "let x = 1;\nputs(x);\n"}}
+	expected[0].Range.End = Position{1, 9}
+	if len(edits) != 1 || edits[0] != expected[0] {
+		t.Errorf("wrong edits. want=%v, got=%v", expected, edits)
+	}
+	if edits := result[[]TextEdit](t, responses[broken]); edits != nil {
+		t.Errorf("expected no edits for a script with errors, got %v", edits)
+	}
+}
+
+func TestDiagnostics(t *testing.T) {
+	s := openScript("let x = 1;\nlet = 2;")
+	s.notify("textDocument/didChange", map[string]any{
+		"textDocument":   map[string]any{"uri": uri, "version": 2},
+		"contentChanges": []map[string]any{{"text": "let x = 1;"}},
+	})
+	s.notify("textDocument/didClose", DidCloseTextDocumentParams{TextDocument: TextDocumentIdentifier{URI: uri}})
+	closeSession(s)
+
+	_, notifications, _ := s.run(t)
+	if len(notifications) != 3 {
+		t.Fatalf("wrong number of notifications. want=3, got=%d", len(notifications))
+	}
+	var published []PublishDiagnosticsParams
+	for _, n := range notifications {
+		if n.Method != "textDocument/publishDiagnostics" {
+			t.Fatalf("unexpected notification %s", n.Method)
+		}
+		var params PublishDiagnosticsParams
+		json.Unmarshal(n.Params, &params)
+		published = append(published, params)
+	}
+
+	first := published[0].Diagnostics
+	if len(first) == 0 || first[0].Range.Start != (Position{1, 4}) || first[0].Message != "Expected next token to be IDEN , got =" {
+		t.Errorf("wrong diagnostics for the broken script, got %+v", first)
+	}
+	if published[1].Version != 2 || len(published[1].Diagnostics) != 0 {
+		t.Errorf("diagnostics not cleared by the fix, got %+v", published[1])
+	}
+	if len(published[2].Diagnostics) != 0 {
+		t.Errorf("diagnostics not cleared on close, got %+v", published[2])
+	}
+}
+
+func TestPositionsCountUTF16(t *testing.T) {
+	doc := newDocument(uri, "let s = \"é𝄞\"; s", 1)
+	// The last s is at byte 18, but at UTF-16 code unit 15
+	iden := doc.info.IdentifierAt(doc.tokenPosition(Position{0, 15}))
+	if iden == nil || iden.Token.Pos.Column != 19 {
+		t.Fatalf("wrong identifier at 0:15, got %v", iden)
+	}
+	if got := doc.position(iden.Token.Pos); got != (Position{0, 15}) {
+		t.Errorf("wrong position of s. want=0:15, got=%v", got)
+	}
+}
+
+func TestProtocolErrors(t *testing.T) {
+	s := &session{}
+	unknown := s.request("textDocument/unknown", nil)
+	notOpen := s.request("textDocument/hover", at(0, 0))
+	invalid := s.request("textDocument/hover", "not params")
+	s.notify("exit", nil)
+
+	responses, _, err := s.run(t)
+	if !errors.Is(err, ErrNoShutdown) {
+		t.Errorf("expected ErrNoShutdown, got %v", err)
+	}
+	tests := []struct {
+		id   int
+		code int
+	}{
+		{unknown, codeMethodNotFound},
+		{notOpen, codeInvalidParams},
+		{invalid, codeInvalidParams},
+	}
+	for _, tt := range tests {
+		if msg := responses[tt.id]; msg.Error == nil || msg.Error.Code != tt.code {
+			t.Errorf("wrong error for request %d. want=%d, got=%+v", tt.id, tt.code, msg.Error)
+		}
+	}
+
+	// Requests after shutdown fail, and the input ending before exit is an error
+	s = &session{}
+	s.request("shutdown", nil)
+	late := s.request("textDocument/hover", at(0, 0))
+	responses, _, err = s.run(t)
+	if msg := responses[late]; msg.Error == nil || msg.Error.Code != codeInvalidRequest {
+		t.Errorf("expected a request after shutdown to fail, got %+v", msg)
+	}
+	if err != io.EOF {
+		t.Errorf("expected io.EOF without exit, got %v", err)
+	}
+}
+
+func equal(a, b []Location) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -1,14 +1,75 @@
 package main
 
 import (
+	"encoding/json"
+	"flag"
 	"fmt"
 	"os"
 	"os/user"
+	"path/filepath"
+	"strings"
 
+	"github.com/ShivankSharma070/go-interpreter/ast"
+	"github.com/ShivankSharma070/go-interpreter/compiler"
+	"github.com/ShivankSharma070/go-interpreter/evaluator"
+	"github.com/ShivankSharma070/go-interpreter/lexer"
+	"github.com/ShivankSharma070/go-interpreter/object"
+	"github.com/ShivankSharma070/go-interpreter/optimizer"
+	"github.com/ShivankSharma070/go-interpreter/parser"
 	"github.com/ShivankSharma070/go-interpreter/repl"
+	"github.com/ShivankSharma070/go-interpreter/trace"
+	"github.com/ShivankSharma070/go-interpreter/vm"
+)
+
+// Extensions of scripts and of compiled programs
+const (
+	scriptExtension   = ".monkey"
+	bytecodeExtension = ".mbc"
+)
+
+// Subcommands, run as the first argument
+var commands = map[string]func(args []string) int{
+	"dap":   dapCommand,
+	"debug": debugCommand,
+	"fmt":   formatCommand,
+	"lint":  lintCommand,
+	"lsp":   lspCommand,
+}
+
+var (
+	useVM       = flag.Bool("vm", false, "run the script with the bytecode vm instead of the tree walking evaluator")
+	compileOnly = flag.Bool("compile", false, "compile the script to bytecode instead of running it")
+	output      = flag.String("o", "", "file -compile writes the bytecode to, the script with a "+bytecodeExtension+" extension by default")
+	disassemble = flag.Bool("disasm", false, "print the bytecode of the script instead of running it")
+	dumpAST     = flag.Bool("ast", false, "print the syntax tree of the script as JSON instead of running it")
+	fileAccess  = fileAccessFlag(object.FilesReadOnly)
+	traceCalls  = flag.Bool("trace", false, "print every call of the script with its arguments and result to the standard error, needs the tree walking evaluator")
 )
 
 func main() {
+	flag.Var(&fileAccess, "files", fileAccessUsage)
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] [script]\n", os.Args[0])
+		fmt.Fprintf(flag.CommandLine.Output(), "       %s dap [-listen address] [-files access]\n", os.Args[0])
+		fmt.Fprintf(flag.CommandLine.Output(), "       %s debug [-b lines] [-files access] script\n", os.Args[0])
+		fmt.Fprintf(flag.CommandLine.Output(), "       %s fmt [-w | -check] [path ...]\n", os.Args[0])
+		fmt.Fprintf(flag.CommandLine.Output(), "       %s lint [-json] [-enable rules] [-disable rules] [-globals names] [path ...]\n", os.Args[0])
+		fmt.Fprintf(flag.CommandLine.Output(), "       %s lsp\n", os.Args[0])
+		fmt.Fprintln(flag.CommandLine.Output(), "Without a script the REPL is started. Compiled scripts always run on the vm.")
+		fmt.Fprintln(flag.CommandLine.Output(), "Scripts can only read the files below the working directory unless -files says otherwise.")
+		flag.PrintDefaults()
+	}
+	if len(os.Args) > 1 {
+		if command, ok := commands[os.Args[1]]; ok {
+			os.Exit(command(os.Args[2:]))
+		}
+	}
+	flag.Parse()
+
+	if flag.NArg() > 0 {
+		os.Exit(runFile(flag.Arg(0)))
+	}
+
 	user, err := user.Current()
 	if err != nil {
 		panic(err)
@@ -16,5 +77,198 @@ func main() {
 
 	fmt.Printf("Hello %s! Welcome to Monkey Programming Language REPL", user.Username)
 	fmt.Println("Feel free to type any command..")
-	repl.Start(os.Stdin, os.Stdout)
+	os.Exit(repl.Start(os.Stdin, os.Stdout, newEnvironment("")))
+}
+
+// newEnvironment creates the environment programs run in, they access the files below the working directory
+// as -files allows. The imports of script are relative to its directory, those of the REPL, whose script is "",
+// to the working directory.
+func newEnvironment(script string) *object.Environment {
+	env := object.NewEnvironment()
+	env.Dir = scriptDir(script)
+	if object.FileMode(fileAccess) == object.FilesDisabled {
+		return env
+	}
+	files, err := object.NewRootedFilePolicy(".", object.FileMode(fileAccess))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "file access disabled:", err)
+		return env
+	}
+	env.Runtime.Files = files
+	return env
+}
+
+// scriptDir returns the directory of script relative to the working directory, in the form file policies use.
+// The directory of a script outside of the working directory starts with "..", the policy rejects its imports.
+func scriptDir(script string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return "."
+	}
+	dir, err := filepath.Abs(filepath.Dir(script))
+	if err != nil {
+		return "."
+	}
+	rel, err := filepath.Rel(wd, dir)
+	if err != nil {
+		return "."
+	}
+	return filepath.ToSlash(rel)
+}
+
+const fileAccessUsage = "`access` scripts have to the files below the working directory: none, read or write"
+
+// fileAccessFlag is the value of -files, the main command and the subcommands running scripts share it
+type fileAccessFlag object.FileMode
+
+// Names of the file modes -files accepts, indexed by mode
+var fileAccessNames = []string{object.FilesDisabled: "none", object.FilesReadOnly: "read", object.FilesReadWrite: "write"}
+
+func (f *fileAccessFlag) String() string {
+	return fileAccessNames[*f]
+}
+
+func (f *fileAccessFlag) Set(value string) error {
+	for mode, name := range fileAccessNames {
+		if name == value {
+			*f = fileAccessFlag(mode)
+			return nil
+		}
+	}
+	return fmt.Errorf("must be none, read or write, got %q", value)
+}
+
+// runFile evaluates a script, or compiles it when asked to, and returns the process exit code for it.
+// The script is either source code or a program compiled by -compile.
+func runFile(path string) int {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if *dumpAST {
+		return printAST(path, source)
+	}
+	if *traceCalls && (*useVM || *compileOnly || *disassemble || compiler.IsBytecode(source)) {
+		fmt.Fprintln(os.Stderr, "-trace needs a script run by the tree walking evaluator")
+		return 2
+	}
+	env := newEnvironment(path)
+
+	var bytecode *compiler.Bytecode
+	if compiler.IsBytecode(source) {
+		bytecode = &compiler.Bytecode{}
+		if err := bytecode.UnmarshalBinary(source); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", path, err)
+			return 1
+		}
+	} else {
+		program, errObj := parseProgram(string(source), env)
+		if errObj != nil {
+			fmt.Fprintln(os.Stderr, errObj.Message)
+			return 1
+		}
+		if !*compileOnly && !*disassemble {
+			if *useVM {
+				return exitCode(vm.Eval(program, env))
+			}
+			if *traceCalls {
+				trace.New(os.Stderr).Attach(env)
+			}
+			return exitCode(evaluator.Eval(program, env))
+		}
+
+		c := compiler.New()
+		if err := c.Compile(program); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		bytecode = c.Bytecode()
+	}
+
+	switch {
+	case *disassemble:
+		fmt.Print(bytecode.Disassemble())
+		return 0
+	case *compileOnly:
+		return writeBytecode(path, bytecode)
+	default:
+		return exitCode(vm.New(bytecode, env).Run())
+	}
+}
+
+// parseProgram parses a script, expands its macros and optimizes it
+func parseProgram(source string, env *object.Environment) (*ast.Program, *object.Error) {
+	program, err := expandProgram(source, env)
+	if err != nil {
+		return nil, err
+	}
+	return optimizer.Optimize(program), nil
+}
+
+// expandProgram parses a script and expands its macros
+func expandProgram(source string, env *object.Environment) (*ast.Program, *object.Error) {
+	p := parser.New(lexer.New(source))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		return nil, &object.Error{Message: strings.Join(p.Errors(), "\n")}
+	}
+
+	expanded, err := evaluator.ExpandProgram(program, env)
+	if err != nil {
+		return nil, &object.Error{Message: err.Inspect()}
+	}
+	return expanded, nil
+}
+
+// printAST prints the syntax tree of a script as it is written, before its macros are expanded
+func printAST(path string, source []byte) int {
+	if compiler.IsBytecode(source) {
+		fmt.Fprintf(os.Stderr, "%s: a compiled program has no syntax tree\n", path)
+		return 1
+	}
+	p := parser.New(lexer.New(string(source)))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		fmt.Fprintln(os.Stderr, strings.Join(p.Errors(), "\n"))
+		return 1
+	}
+
+	data, err := json.MarshalIndent(program, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", path, err)
+		return 1
+	}
+	fmt.Println(string(data))
+	return 0
+}
+
+// writeBytecode saves a program compiled from the script at path
+func writeBytecode(path string, bytecode *compiler.Bytecode) int {
+	data, err := bytecode.MarshalBinary()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot compile %s: %s\n", path, err)
+		return 1
+	}
+	out := *output
+	if out == "" {
+		out = strings.TrimSuffix(path, filepath.Ext(path)) + bytecodeExtension
+	}
+	if err := os.WriteFile(out, data, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// exitCode turns the result of a script into the exit code of the process
+func exitCode(result object.Object) int {
+	switch result := result.(type) {
+	case *object.Exit:
+		return result.Code
+	case *object.Error:
+		fmt.Fprintln(os.Stderr, result.Inspect())
+		return 1
+	}
+	return 0
 }
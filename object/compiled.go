@@ -0,0 +1,67 @@
+package object
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ShivankSharma070/go-interpreter/code"
+)
+
+const COMPILED_FUNCTION_OBJ = "COMPILED_FUNCTION"
+
+// CompiledFunction is a function lowered to bytecode by the compiler, it lives in the constant pool
+type CompiledFunction struct {
+	Instructions  code.Instructions
+	Positions     code.Positions
+	NumLocals     int
+	NumParameters int
+	Name          string   // Name the function was bound to by a let statement, if any
+	Parameters    []string // Names of the parameters, only used to print the function
+	Cells         []int    // Slots of the locals captured by closures, they hold a cell shared with the closures
+	LocalNames    []string // Names of the locals indexed by slot, for the errors of reading one that is not set
+	FreeNames     []string // Names of the captured variables indexed like the free variables of a closure
+}
+
+func (cf *CompiledFunction) Type() ObjectType { return COMPILED_FUNCTION_OBJ }
+func (cf *CompiledFunction) Inspect() string {
+	return fmt.Sprintf("fn %s(%s) { <compiled> }", cf.Name, strings.Join(cf.Parameters, ", "))
+}
+
+// CompiledProgram is the state shared by the functions of a compiled program, its constant pool and global variables.
+// Closures remember the program that created them, so they can be called by another one, like a program importing them from a module.
+type CompiledProgram struct {
+	Constants   []Object
+	Globals     []Object
+	GlobalNames []string     // Names of the globals, indexed like Globals
+	Env         *Environment // Environment the program runs in, builtins called by its closures are given it
+}
+
+// Closure is a compiled function along with the variables it captured, it is what the vm calls.
+// It counts as a FUNCTION, just like the functions created by the evaluator.
+type Closure struct {
+	Fn      *CompiledFunction
+	Free    []*Cell
+	Program *CompiledProgram
+}
+
+func (c *Closure) Type() ObjectType { return FUNCTION_OBJ }
+func (c *Closure) Inspect() string {
+	return fmt.Sprintf("fn(%s) { <compiled> }", strings.Join(c.Fn.Parameters, ", "))
+}
+
+const CELL_OBJ = "CELL"
+
+// Cell holds a local variable captured by closures. The function defining the variable and the closures share
+// the cell, so they all see the variable bound again, or bound after the closures were created, the same way
+// functions of the evaluator see the environment they were created in. Cells never reach monkey code.
+type Cell struct {
+	Value Object
+}
+
+func (c *Cell) Type() ObjectType { return CELL_OBJ }
+func (c *Cell) Inspect() string {
+	if c.Value == nil {
+		return "<unset>"
+	}
+	return c.Value.Inspect()
+}
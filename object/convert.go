@@ -0,0 +1,365 @@
+package object
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+)
+
+// Struct fields are matched with hash keys using the `monkey:"name"` tag, falling back to the field name.
+// A tag of `monkey:"-"` skips the field.
+const structTag = "monkey"
+
+// ToGo converts a monkey object into a plain go value.
+// Integers become int64, floats float64, strings string, booleans bool and NULL nil.
+// Arrays become []any. Hashes become map[string]any when every key is a string, map[any]any otherwise.
+func ToGo(obj Object) (any, error) {
+	switch obj := obj.(type) {
+	case nil, *Null:
+		return nil, nil
+	case *Integer:
+		return obj.Value, nil
+	case *Float:
+		return obj.Value, nil
+	case *String:
+		return obj.Value, nil
+	case *Boolean:
+		return obj.Value, nil
+	case *ReturnValue:
+		return ToGo(obj.Value)
+	case *Array:
+		result := make([]any, len(obj.Elements))
+		for i, el := range obj.Elements {
+			value, err := ToGo(el)
+			if err != nil {
+				return nil, fmt.Errorf("index %d: %w", i, err)
+			}
+			result[i] = value
+		}
+		return result, nil
+	case *Hash:
+		return hashToGo(obj)
+	default:
+		return nil, fmt.Errorf("cannot convert %s to a go value", obj.Type())
+	}
+}
+
+func hashToGo(hash *Hash) (any, error) {
+	stringKeys := true
+	for _, pair := range hash.Pair {
+		if pair.Key.Type() != STRING_OBJ {
+			stringKeys = false
+			break
+		}
+	}
+
+	if stringKeys {
+		result := make(map[string]any, len(hash.Pair))
+		for _, pair := range hash.Pair {
+			key := pair.Key.(*String).Value
+			value, err := ToGo(pair.Value)
+			if err != nil {
+				return nil, fmt.Errorf("key %q: %w", key, err)
+			}
+			result[key] = value
+		}
+		return result, nil
+	}
+
+	result := make(map[any]any, len(hash.Pair))
+	for _, pair := range hash.Pair {
+		key, err := ToGo(pair.Key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := ToGo(pair.Value)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", pair.Key.Inspect(), err)
+		}
+		result[key] = value
+	}
+	return result, nil
+}
+
+// ToGoInto converts a monkey object into the go value pointed to by target.
+// Unlike ToGo, it can fill typed values such as structs, typed slices and maps.
+func ToGoInto(obj Object, target any) error {
+	ptr := reflect.ValueOf(target)
+	if ptr.Kind() != reflect.Pointer || ptr.IsNil() {
+		return fmt.Errorf("target must be a non-nil pointer, got %T", target)
+	}
+	return assignGo(obj, ptr.Elem())
+}
+
+func assignGo(obj Object, dst reflect.Value) error {
+	if rv, ok := obj.(*ReturnValue); ok {
+		obj = rv.Value
+	}
+	if obj == nil {
+		obj = NULL
+	}
+
+	// NULL leaves the destination with its zero value
+	if obj.Type() == NULL_OBJ {
+		dst.SetZero()
+		return nil
+	}
+
+	switch dst.Kind() {
+	case reflect.Interface:
+		value, err := ToGo(obj)
+		if err != nil {
+			return err
+		}
+		if value == nil {
+			dst.SetZero()
+			return nil
+		}
+		v := reflect.ValueOf(value)
+		if !v.Type().AssignableTo(dst.Type()) {
+			return conversionError(obj, dst.Type())
+		}
+		dst.Set(v)
+		return nil
+	case reflect.Pointer:
+		elem := reflect.New(dst.Type().Elem())
+		if err := assignGo(obj, elem.Elem()); err != nil {
+			return err
+		}
+		dst.Set(elem)
+		return nil
+	case reflect.Bool:
+		b, ok := obj.(*Boolean)
+		if !ok {
+			return conversionError(obj, dst.Type())
+		}
+		dst.SetBool(b.Value)
+		return nil
+	case reflect.String:
+		s, ok := obj.(*String)
+		if !ok {
+			return conversionError(obj, dst.Type())
+		}
+		dst.SetString(s.Value)
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, ok := obj.(*Integer)
+		if !ok {
+			return conversionError(obj, dst.Type())
+		}
+		if dst.OverflowInt(i.Value) {
+			return fmt.Errorf("%d overflows %s", i.Value, dst.Type())
+		}
+		dst.SetInt(i.Value)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		i, ok := obj.(*Integer)
+		if !ok {
+			return conversionError(obj, dst.Type())
+		}
+		if i.Value < 0 || dst.OverflowUint(uint64(i.Value)) {
+			return fmt.Errorf("%d overflows %s", i.Value, dst.Type())
+		}
+		dst.SetUint(uint64(i.Value))
+		return nil
+	case reflect.Float32, reflect.Float64:
+		switch num := obj.(type) {
+		case *Float:
+			dst.SetFloat(num.Value)
+		case *Integer:
+			dst.SetFloat(float64(num.Value))
+		default:
+			return conversionError(obj, dst.Type())
+		}
+		return nil
+	case reflect.Slice:
+		arr, ok := obj.(*Array)
+		if !ok {
+			return conversionError(obj, dst.Type())
+		}
+		slice := reflect.MakeSlice(dst.Type(), len(arr.Elements), len(arr.Elements))
+		for i, el := range arr.Elements {
+			if err := assignGo(el, slice.Index(i)); err != nil {
+				return fmt.Errorf("index %d: %w", i, err)
+			}
+		}
+		dst.Set(slice)
+		return nil
+	case reflect.Array:
+		arr, ok := obj.(*Array)
+		if !ok {
+			return conversionError(obj, dst.Type())
+		}
+		if len(arr.Elements) != dst.Len() {
+			return fmt.Errorf("cannot convert ARRAY of length %d to %s", len(arr.Elements), dst.Type())
+		}
+		for i, el := range arr.Elements {
+			if err := assignGo(el, dst.Index(i)); err != nil {
+				return fmt.Errorf("index %d: %w", i, err)
+			}
+		}
+		return nil
+	case reflect.Map:
+		hash, ok := obj.(*Hash)
+		if !ok {
+			return conversionError(obj, dst.Type())
+		}
+		m := reflect.MakeMapWithSize(dst.Type(), len(hash.Pair))
+		for _, pair := range hash.Pair {
+			key := reflect.New(dst.Type().Key()).Elem()
+			if err := assignGo(pair.Key, key); err != nil {
+				return err
+			}
+			value := reflect.New(dst.Type().Elem()).Elem()
+			if err := assignGo(pair.Value, value); err != nil {
+				return fmt.Errorf("key %s: %w", pair.Key.Inspect(), err)
+			}
+			m.SetMapIndex(key, value)
+		}
+		dst.Set(m)
+		return nil
+	case reflect.Struct:
+		hash, ok := obj.(*Hash)
+		if !ok {
+			return conversionError(obj, dst.Type())
+		}
+		for _, field := range structFields(dst.Type()) {
+			pair, ok := hash.Pair[(&String{Value: field.key}).HashKey()]
+			if !ok {
+				continue
+			}
+			if err := assignGo(pair.Value, dst.FieldByIndex(field.index)); err != nil {
+				return fmt.Errorf("field %s: %w", field.key, err)
+			}
+		}
+		return nil
+	default:
+		return conversionError(obj, dst.Type())
+	}
+}
+
+func conversionError(obj Object, t reflect.Type) error {
+	return fmt.Errorf("cannot convert %s to %s", obj.Type(), t)
+}
+
+// FromGo converts a go value into a monkey object.
+// Structs become hashes keyed by their exported field names, see structTag.
+func FromGo(v any) (Object, error) {
+	if obj, ok := v.(Object); ok {
+		return obj, nil
+	}
+	return fromGoValue(reflect.ValueOf(v))
+}
+
+func fromGoValue(v reflect.Value) (Object, error) {
+	if !v.IsValid() {
+		return NULL, nil
+	}
+
+	if v.CanInterface() {
+		if obj, ok := v.Interface().(Object); ok {
+			return obj, nil
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return NULL, nil
+		}
+		return fromGoValue(v.Elem())
+	case reflect.Bool:
+		if v.Bool() {
+			return TRUE, nil
+		}
+		return FALSE, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Integer{Value: v.Int()}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if v.Uint() > math.MaxInt64 {
+			return nil, fmt.Errorf("%d overflows INTEGER", v.Uint())
+		}
+		return &Integer{Value: int64(v.Uint())}, nil
+	case reflect.Float32, reflect.Float64:
+		return &Float{Value: v.Float()}, nil
+	case reflect.String:
+		return &String{Value: v.String()}, nil
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return NULL, nil
+		}
+		elements := make([]Object, v.Len())
+		for i := range elements {
+			el, err := fromGoValue(v.Index(i))
+			if err != nil {
+				return nil, fmt.Errorf("index %d: %w", i, err)
+			}
+			elements[i] = el
+		}
+		return &Array{Elements: elements}, nil
+	case reflect.Map:
+		if v.IsNil() {
+			return NULL, nil
+		}
+		pairs := make(map[HashKey]HashPair, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			key, err := fromGoValue(iter.Key())
+			if err != nil {
+				return nil, err
+			}
+			hashKey, ok := key.(Hashable)
+			if !ok {
+				return nil, fmt.Errorf("unusable as hash key: %s", key.Type())
+			}
+			value, err := fromGoValue(iter.Value())
+			if err != nil {
+				return nil, fmt.Errorf("key %v: %w", iter.Key(), err)
+			}
+			pairs[hashKey.HashKey()] = HashPair{Key: key, Value: value}
+		}
+		return &Hash{Pair: pairs}, nil
+	case reflect.Struct:
+		pairs := make(map[HashKey]HashPair)
+		for _, field := range structFields(v.Type()) {
+			value, err := fromGoValue(v.FieldByIndex(field.index))
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %w", field.key, err)
+			}
+			key := &String{Value: field.key}
+			pairs[key.HashKey()] = HashPair{Key: key, Value: value}
+		}
+		return &Hash{Pair: pairs}, nil
+	default:
+		return nil, fmt.Errorf("unsupported go type %s", v.Type())
+	}
+}
+
+type structField struct {
+	key   string
+	index []int
+}
+
+// structFields lists the exported fields of a struct type along with the hash key used for each of them
+func structFields(t reflect.Type) []structField {
+	fields := []structField{}
+	for _, f := range reflect.VisibleFields(t) {
+		if !f.IsExported() || f.Anonymous {
+			continue
+		}
+
+		key := f.Name
+		if tag, ok := f.Tag.Lookup(structTag); ok {
+			name, _, _ := strings.Cut(tag, ",")
+			if name == "-" {
+				continue
+			}
+			if name != "" {
+				key = name
+			}
+		}
+		fields = append(fields, structField{key: key, index: f.Index})
+	}
+	return fields
+}
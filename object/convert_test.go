@@ -0,0 +1,142 @@
+package object
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestToGo(t *testing.T) {
+	tests := []struct {
+		input    Object
+		expected any
+	}{
+		{&Integer{Value: 5}, int64(5)},
+		{&Float{Value: 2.5}, 2.5},
+		{&String{Value: "monkey"}, "monkey"},
+		{TRUE, true},
+		{NULL, nil},
+		{&Array{Elements: []Object{&Integer{Value: 1}, &String{Value: "two"}}}, []any{int64(1), "two"}},
+		{testHash(&String{Value: "a"}, &Integer{Value: 1}), map[string]any{"a": int64(1)}},
+		{testHash(&Integer{Value: 1}, TRUE), map[any]any{int64(1): true}},
+	}
+
+	for _, tt := range tests {
+		result, err := ToGo(tt.input)
+		if err != nil {
+			t.Errorf("ToGo(%s) returned error: %s", tt.input.Inspect(), err)
+			continue
+		}
+		if !reflect.DeepEqual(result, tt.expected) {
+			t.Errorf("ToGo(%s) is not %#v, got %#v", tt.input.Inspect(), tt.expected, result)
+		}
+	}
+}
+
+func TestToGoUnsupported(t *testing.T) {
+	_, err := ToGo(&Array{Elements: []Object{&Builtin{}}})
+	if err == nil {
+		t.Fatalf("expected error for BUILTIN, got nil")
+	}
+	if err.Error() != "index 0: cannot convert BUILTIN to a go value" {
+		t.Errorf("wrong error message, got %q", err.Error())
+	}
+}
+
+type testConfig struct {
+	Name    string   `monkey:"name"`
+	Retries int      `monkey:"retries"`
+	Ratio   float64  `monkey:"ratio"`
+	Tags    []string `monkey:"tags"`
+	Secret  string   `monkey:"-"`
+	Debug   bool
+}
+
+func TestToGoInto(t *testing.T) {
+	input := testHash(
+		&String{Value: "name"}, &String{Value: "server"},
+		&String{Value: "retries"}, &Integer{Value: 3},
+		&String{Value: "ratio"}, &Integer{Value: 2},
+		&String{Value: "tags"}, &Array{Elements: []Object{&String{Value: "a"}, &String{Value: "b"}}},
+		&String{Value: "Secret"}, &String{Value: "ignored"},
+		&String{Value: "Debug"}, TRUE,
+	)
+
+	var cfg testConfig
+	if err := ToGoInto(input, &cfg); err != nil {
+		t.Fatalf("ToGoInto returned error: %s", err)
+	}
+
+	expected := testConfig{Name: "server", Retries: 3, Ratio: 2, Tags: []string{"a", "b"}, Debug: true}
+	if !reflect.DeepEqual(cfg, expected) {
+		t.Errorf("cfg is not %+v, got %+v", expected, cfg)
+	}
+
+	var small int8
+	if err := ToGoInto(&Integer{Value: 300}, &small); err == nil || err.Error() != "300 overflows int8" {
+		t.Errorf("expected overflow error, got %v", err)
+	}
+
+	if err := ToGoInto(testHash(&String{Value: "retries"}, &String{Value: "x"}), &cfg); err == nil ||
+		err.Error() != "field retries: cannot convert STRING to int" {
+		t.Errorf("expected conversion error, got %v", err)
+	}
+}
+
+func TestFromGo(t *testing.T) {
+	tests := []struct {
+		input    any
+		expected string
+	}{
+		{nil, "NULL"},
+		{42, "42"},
+		{uint8(7), "7"},
+		{1.5, "1.5"},
+		{"monkey", "monkey"},
+		{false, "false"},
+		{[]int{1, 2, 3}, "[1, 2, 3]"},
+		{map[string]int{"a": 1}, "{a : 1}"},
+		{testConfig{Name: "x", Secret: "s"}, ""},
+	}
+
+	for _, tt := range tests {
+		result, err := FromGo(tt.input)
+		if err != nil {
+			t.Errorf("FromGo(%#v) returned error: %s", tt.input, err)
+			continue
+		}
+		if tt.expected != "" && result.Inspect() != tt.expected {
+			t.Errorf("FromGo(%#v) is not %s, got %s", tt.input, tt.expected, result.Inspect())
+		}
+	}
+
+	if obj, _ := FromGo(true); obj != TRUE {
+		t.Errorf("FromGo(true) is not the shared TRUE object")
+	}
+
+	obj, err := FromGo(testConfig{Name: "x", Retries: 2, Secret: "s"})
+	if err != nil {
+		t.Fatalf("FromGo returned error: %s", err)
+	}
+	hash := obj.(*Hash)
+	if len(hash.Pair) != 5 {
+		t.Errorf("struct hash has wrong number of pairs, got %d", len(hash.Pair))
+	}
+	if _, ok := hash.Pair[(&String{Value: "Secret"}).HashKey()]; ok {
+		t.Errorf("field tagged with - was converted")
+	}
+	if pair := hash.Pair[(&String{Value: "retries"}).HashKey()]; pair.Value.Inspect() != "2" {
+		t.Errorf("retries is not 2, got %v", pair.Value)
+	}
+
+	if _, err := FromGo(make(chan int)); err == nil || err.Error() != "unsupported go type chan int" {
+		t.Errorf("expected unsupported type error, got %v", err)
+	}
+}
+
+func testHash(kv ...Object) *Hash {
+	pairs := make(map[HashKey]HashPair)
+	for i := 0; i < len(kv); i += 2 {
+		pairs[kv[i].(Hashable).HashKey()] = HashPair{Key: kv[i], Value: kv[i+1]}
+	}
+	return &Hash{Pair: pairs}
+}
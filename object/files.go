@@ -0,0 +1,49 @@
+package object
+
+import (
+	"io/fs"
+	"os"
+)
+
+// FileMode tells what scripts are allowed to do with files
+type FileMode int
+
+const (
+	FilesDisabled  FileMode = iota // No file access at all, the default
+	FilesReadOnly                  // Files can be read, but not written
+	FilesReadWrite                 // Files can be read and written
+)
+
+// FilePolicy is the filesystem a runtime exposes to the file builtins.
+// Reads go through FS, so a host can hand out any fs.FS (an embed.FS, a fstest.MapFS, ...).
+// Writes need a directory on disk, they go through Root and can not escape it.
+type FilePolicy struct {
+	Mode FileMode
+	FS   fs.FS
+	Root *os.Root
+}
+
+// NewReadOnlyFilePolicy lets scripts read files from fsys
+func NewReadOnlyFilePolicy(fsys fs.FS) *FilePolicy {
+	return &FilePolicy{Mode: FilesReadOnly, FS: fsys}
+}
+
+// NewRootedFilePolicy lets scripts access the files below dir, and only those.
+// mode decides if they can also write them.
+func NewRootedFilePolicy(dir string, mode FileMode) (*FilePolicy, error) {
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return nil, err
+	}
+	return &FilePolicy{Mode: mode, FS: root.FS(), Root: root}, nil
+}
+
+// CanRead reports whether the policy allows reading files, it is safe to call on a nil policy
+func (p *FilePolicy) CanRead() bool {
+	return p != nil && p.Mode != FilesDisabled && p.FS != nil
+}
+
+// CanWrite reports whether the policy allows writing files, it is safe to call on a nil policy
+func (p *FilePolicy) CanWrite() bool {
+	return p != nil && p.Mode == FilesReadWrite && p.Root != nil
+}
@@ -0,0 +1,99 @@
+package object
+
+import (
+	"slices"
+
+	"github.com/ShivankSharma070/go-interpreter/ast"
+	"github.com/ShivankSharma070/go-interpreter/token"
+)
+
+// Hook is told by the tree walking evaluator about its progress, tracers, loggers, profilers and debuggers are
+// built on it. A hook pauses the program by not returning. Embedding NopHook leaves out the events a hook
+// does not care about.
+type Hook interface {
+	// Enter is called before node is evaluated in env. Returning an object other than nil skips the node
+	// as if it had evaluated to it, an exit stops the program.
+	Enter(node ast.Node, env *Environment) Object
+	// Exit is called once node evaluated to result, which is nil for statements without a value
+	Exit(node ast.Node, env *Environment, result Object)
+	// Call is called before fn runs with args. env is the environment of the call: a new one holding the
+	// parameters for monkey functions, the one of the call site for builtins. pos is the call site.
+	Call(fn Object, args []Object, env *Environment, pos token.Position)
+	// Return is called once the call of fn is over, with its result
+	Return(fn Object, result Object)
+	// Error is called once for every error, with the node it was created in. The nodes around it see it again
+	// in Exit as it unwinds. Errors of builtins are created in their call expression, once the call is over.
+	Error(err *Error, node ast.Node)
+}
+
+// NopHook ignores every event
+type NopHook struct{}
+
+func (NopHook) Enter(ast.Node, *Environment) Object                 { return nil }
+func (NopHook) Exit(ast.Node, *Environment, Object)                 {}
+func (NopHook) Call(Object, []Object, *Environment, token.Position) {}
+func (NopHook) Return(Object, Object)                               {}
+func (NopHook) Error(*Error, ast.Node)                              {}
+
+// Hooks are the hooks attached to a runtime, they are told about events in the order they were attached.
+// The zero value has no hooks.
+type Hooks struct {
+	hooks     []Hook
+	lastError *Error // The last error reported, the nodes it unwinds through return it too
+}
+
+// Attach adds hook, it is told about the events from now on. Hooks are compared to be detached, a pointer
+// makes a good hook.
+func (h *Hooks) Attach(hook Hook) {
+	h.hooks = append(h.hooks, hook)
+}
+
+// Detach removes hook and reports whether it was attached
+func (h *Hooks) Detach(hook Hook) bool {
+	i := slices.Index(h.hooks, hook)
+	if i < 0 {
+		return false
+	}
+	h.hooks = slices.Delete(h.hooks, i, i+1)
+	return true
+}
+
+// Len returns the number of hooks attached, the evaluator skips the events when it is 0
+func (h *Hooks) Len() int {
+	return len(h.hooks)
+}
+
+// Enter tells the hooks node is about to be evaluated, the first object a hook returns skips it
+func (h *Hooks) Enter(node ast.Node, env *Environment) Object {
+	for _, hook := range h.hooks {
+		if result := hook.Enter(node, env); result != nil {
+			return result
+		}
+	}
+	return nil
+}
+
+// Exit tells the hooks node evaluated to result, an error they have not seen yet was created in node
+func (h *Hooks) Exit(node ast.Node, env *Environment, result Object) {
+	if err, ok := result.(*Error); ok && err != h.lastError {
+		h.lastError = err
+		for _, hook := range h.hooks {
+			hook.Error(err, node)
+		}
+	}
+	for _, hook := range h.hooks {
+		hook.Exit(node, env, result)
+	}
+}
+
+func (h *Hooks) Call(fn Object, args []Object, env *Environment, pos token.Position) {
+	for _, hook := range h.hooks {
+		hook.Call(fn, args, env, pos)
+	}
+}
+
+func (h *Hooks) Return(fn Object, result Object) {
+	for _, hook := range h.hooks {
+		hook.Return(fn, result)
+	}
+}
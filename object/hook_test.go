@@ -0,0 +1,74 @@
+package object
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ShivankSharma070/go-interpreter/ast"
+	"github.com/ShivankSharma070/go-interpreter/token"
+)
+
+// recorder writes down the events it is told about
+type recorder struct {
+	NopHook
+	name   string
+	events *[]string
+	skip   Object
+}
+
+func (r *recorder) Enter(node ast.Node, _ *Environment) Object {
+	*r.events = append(*r.events, fmt.Sprintf("%s enter %s", r.name, node.String()))
+	return r.skip
+}
+
+func (r *recorder) Exit(node ast.Node, _ *Environment, result Object) {
+	*r.events = append(*r.events, fmt.Sprintf("%s exit %s %s", r.name, node.String(), result.Inspect()))
+}
+
+func (r *recorder) Error(err *Error, node ast.Node) {
+	*r.events = append(*r.events, fmt.Sprintf("%s error %s %s", r.name, node.String(), err.Message))
+}
+
+func (r *recorder) Call(fn Object, args []Object, _ *Environment, _ token.Position) {
+	*r.events = append(*r.events, fmt.Sprintf("%s call %s %d", r.name, fn.Inspect(), len(args)))
+}
+
+func TestHooks(t *testing.T) {
+	var events []string
+	first := &recorder{name: "first", events: &events}
+	second := &recorder{name: "second", events: &events}
+	var hooks Hooks
+	hooks.Attach(first)
+	hooks.Attach(second)
+
+	inner := &ast.Identifier{Value: "inner"}
+	outer := &ast.Identifier{Value: "outer"}
+	err := &Error{Message: "boom"}
+	hooks.Enter(outer, nil)
+	hooks.Exit(inner, nil, err)
+	hooks.Exit(outer, nil, err)
+	hooks.Call(NULL, []Object{TRUE}, nil, token.Position{})
+
+	first.skip = &Exit{Code: 3}
+	if result := hooks.Enter(outer, nil); result != first.skip {
+		t.Errorf("the object of the first hook was not returned. got=%v", result)
+	}
+	if !hooks.Detach(first) || hooks.Detach(first) || hooks.Len() != 1 {
+		t.Errorf("detaching a hook twice did not fail only once")
+	}
+	hooks.Call(NULL, nil, nil, token.Position{})
+
+	expected := []string{
+		"first enter outer", "second enter outer",
+		"first error inner boom", "second error inner boom",
+		"first exit inner Error: boom", "second exit inner Error: boom",
+		"first exit outer Error: boom", "second exit outer Error: boom",
+		"first call NULL 1", "second call NULL 1",
+		"first enter outer",
+		"second call NULL 0",
+	}
+	if strings.Join(events, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("wrong events.\nexpected:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(events, "\n"))
+	}
+}
@@ -0,0 +1,45 @@
+package object
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/ShivankSharma070/go-interpreter/ast"
+)
+
+const (
+	QUOTE_OBJ = "QUOTE"
+	MACRO_OBJ = "MACRO"
+)
+
+// Quote is a piece of code that was not evaluated, it is what quote(...) returns and what macros produce
+type Quote struct {
+	Node ast.Node
+}
+
+func (q *Quote) Type() ObjectType { return QUOTE_OBJ }
+func (q *Quote) Inspect() string  { return "QUOTE(" + q.Node.String() + ")" }
+
+// Macro is a macro definition, it only exists while macros are being expanded
+type Macro struct {
+	Parameters []*ast.Identifier
+	Body       *ast.BlockStatement
+	NumLocals  int
+	Env        *Environment
+}
+
+func (m *Macro) Type() ObjectType { return MACRO_OBJ }
+func (m *Macro) Inspect() string {
+	var out bytes.Buffer
+	params := []string{}
+	for _, p := range m.Parameters {
+		params = append(params, p.String())
+	}
+
+	out.WriteString("macro(")
+	out.WriteString(strings.Join(params, ", "))
+	out.WriteString(") {\n")
+	out.WriteString(m.Body.String())
+	out.WriteString("\n}")
+	return out.String()
+}
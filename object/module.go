@@ -0,0 +1,73 @@
+package object
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const MODULE_OBJ = "MODULE"
+
+// Module is the result of importing a file, it holds the bindings exported by the file.
+// Every top level binding is exported unless its name starts with an underscore.
+type Module struct {
+	Path    string
+	Exports map[string]Object
+}
+
+func (m *Module) Type() ObjectType { return MODULE_OBJ }
+func (m *Module) Inspect() string {
+	names := make([]string, 0, len(m.Exports))
+	for name := range m.Exports {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return fmt.Sprintf("module %q {%s}", m.Path, strings.Join(names, ", "))
+}
+
+// NewModule creates a module out of the environment a file was evaluated in
+func NewModule(path string, env *Environment) *Module {
+	exports := make(map[string]Object)
+	for name, value := range env.Store {
+		if !strings.HasPrefix(name, "_") {
+			exports[name] = value
+		}
+	}
+	return &Module{Path: path, Exports: exports}
+}
+
+// ModuleCache remembers the modules imported by a runtime, so every file is only evaluated once,
+// along with the modules being imported right now, to detect import cycles.
+type ModuleCache struct {
+	loaded  map[string]*Module
+	loading []string
+}
+
+func NewModuleCache() *ModuleCache {
+	return &ModuleCache{loaded: make(map[string]*Module)}
+}
+
+func (c *ModuleCache) Get(path string) (*Module, bool) {
+	module, ok := c.loaded[path]
+	return module, ok
+}
+
+// Begin marks path as being imported, it fails if path is already being imported as that would be a cycle
+func (c *ModuleCache) Begin(path string) error {
+	for i, loading := range c.loading {
+		if loading == path {
+			cycle := append(append([]string{}, c.loading[i:]...), path)
+			return fmt.Errorf("import cycle: %s", strings.Join(cycle, " -> "))
+		}
+	}
+	c.loading = append(c.loading, path)
+	return nil
+}
+
+// End marks the import of path as finished, module is nil when the import failed
+func (c *ModuleCache) End(path string, module *Module) {
+	c.loading = c.loading[:len(c.loading)-1]
+	if module != nil {
+		c.loaded[path] = module
+	}
+}
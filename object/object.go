@@ -3,18 +3,25 @@ package object
 import (
 	"bytes"
 	"fmt"
-	"strings"
 	"hash/fnv"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
 
 	"github.com/ShivankSharma070/go-interpreter/ast"
+	"github.com/ShivankSharma070/go-interpreter/token"
 )
 
 const (
 	INTEGER_OBJ      = "INTEGER"
+	FLOAT_OBJ        = "FLOAT"
 	BOOLEAN_OBJ      = "BOOLEAN"
 	RETURN_VALUE_OBJ = "RETURN_VALUE"
 	NULL_OBJ         = "NULL"
 	ERROR_OBJ        = "ERROR"
+	EXIT_OBJ         = "EXIT"
 	FUNCTION_OBJ     = "FUNCTION"
 	STRING_OBJ = "STRING"
 	BUILTIN_OBJ = "BUILTIN"
@@ -24,6 +31,13 @@ const (
 
 type ObjectType string
 
+// There is no difference between two nulls, trues or falses, so every part of the interpreter shares these values.
+var (
+	NULL  = &Null{}
+	TRUE  = &Boolean{Value: true}
+	FALSE = &Boolean{Value: false}
+)
+
 type Object interface {
 	Type() ObjectType
 	Inspect() string
@@ -50,6 +64,23 @@ func (i *Integer) HashKey() HashKey{
 	return HashKey{Type: i.Type(), Value : uint64(i.Value)}
 }
 
+type Float struct {
+	Value float64
+}
+
+// A float is always printed with a dot or an exponent, so it can not be mistaken for an integer
+func (f *Float) Inspect() string {
+	s := strconv.FormatFloat(f.Value, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".eIN") {
+		s += ".0"
+	}
+	return s
+}
+func (f *Float) Type() ObjectType { return FLOAT_OBJ }
+func (f *Float) HashKey() HashKey {
+	return HashKey{Type: f.Type(), Value: math.Float64bits(f.Value)}
+}
+
 type Boolean struct {
 	Value bool
 }
@@ -101,9 +132,19 @@ type Error struct {
 func (e *Error) Inspect() string  { return "Error: " + e.Message }
 func (e *Error) Type() ObjectType { return ERROR_OBJ }
 
+// Exit is the signal produced by the exit builtin, it unwinds evaluation like an error does.
+// The CLI turns it into the process exit code, hosts embedding the interpreter can handle it however they want.
+type Exit struct {
+	Code int
+}
+
+func (e *Exit) Inspect() string  { return fmt.Sprintf("exit(%d)", e.Code) }
+func (e *Exit) Type() ObjectType { return EXIT_OBJ }
+
 type FunctionLiteral struct {
 	Parameters []*ast.Identifier
 	Body       *ast.BlockStatement
+	NumLocals  int // Size of the environment of a call
 	Env        *Environment
 }
 
@@ -127,20 +168,49 @@ func (fe *FunctionLiteral) Type() ObjectType {
 }
 
 // ============ ENVIRONMENT ==============
+// Top level variables are stored by name, the parameters and lets of a function call are stored in Slots
+// at the addresses the resolver gave them.
 type Environment struct {
-	Store map[string]Object
-	Outer *Environment
+	Store   map[string]Object
+	Slots   []Object
+	Outer   *Environment
+	Runtime *Runtime
+	// Dir is the directory of the script or module run in the environment, below the root of the file policy.
+	// Imports are relative to it. Enclosed environments share it, so functions import from where they were defined.
+	Dir string
+}
+
+// Runtime holds the interpreter wide settings, it is shared by an environment and every environment enclosed by it.
+type Runtime struct {
+	Stdout  io.Writer    // Where builtins like puts write their output
+	Stderr  io.Writer
+	Files   *FilePolicy  // Files scripts can access, nil disables file access
+	Modules *ModuleCache // Modules imported so far, they are also read through Files
+
+	// Evaluate runs the programs of imported modules, nil uses the tree walking evaluator
+	Evaluate func(program *ast.Program, env *Environment) Object
+
+	// Hooks follow the tree walking evaluator, tracers and debuggers attach to them
+	Hooks Hooks
+}
+
+func NewRuntime() *Runtime {
+	return &Runtime{Stdout: os.Stdout, Stderr: os.Stderr, Modules: NewModuleCache()}
 }
 
 func NewEnclosingEnvironment(enclosingEnv *Environment) *Environment{
-	env := NewEnvironment()
-	env.Outer = enclosingEnv
-	return env
+	s := make(map[string]Object)
+	return &Environment{Store: s, Outer: enclosingEnv, Runtime: enclosingEnv.Runtime, Dir: enclosingEnv.Dir}
+}
+
+// NewFrameEnvironment creates the environment of a function call with room for size locals
+func NewFrameEnvironment(enclosingEnv *Environment, size int) *Environment {
+	return &Environment{Slots: make([]Object, size), Outer: enclosingEnv, Runtime: enclosingEnv.Runtime, Dir: enclosingEnv.Dir}
 }
 
 func NewEnvironment() *Environment {
 	s := make(map[string]Object)
-	return &Environment{Store: s, Outer: nil }
+	return &Environment{Store: s, Outer: nil, Runtime: NewRuntime()}
 }
 
 func (e *Environment) Get(name string) (Object, bool) {
@@ -152,13 +222,35 @@ func (e *Environment) Get(name string) (Object, bool) {
 }
 
 func (e *Environment) Set(name string, value Object) Object {
+	if e.Store == nil {
+		e.Store = make(map[string]Object)
+	}
 	e.Store[name] = value
 	return value
 }
 
+// GetLocal returns the local at slot of the function call depth environments up, nil if it was not set yet
+func (e *Environment) GetLocal(depth, slot int) Object {
+	for ; depth > 0; depth-- {
+		e = e.Outer
+	}
+	return e.Slots[slot]
+}
+
 // ================== BUILT-IN FUNCTION ===================
 
-type BuiltInFunction func(args ...Object) Object
+type BuiltInFunction func(ctx *BuiltinContext, args ...Object) Object
+
+// BuiltinContext gives a builtin function access to the interpreter calling it.
+type BuiltinContext struct {
+	Env    *Environment   // Environment the builtin was called from
+	Pos    token.Position // Position of the call in the source
+	Stdout io.Writer
+	Stderr io.Writer
+
+	// Apply calls a monkey function or builtin with the given arguments, the same way a call expression would
+	Apply func(fn Object, args []Object) Object
+}
 
 type Builtin struct {
 	Fn BuiltInFunction
@@ -209,3 +301,21 @@ func (h *Hash) Inspect() string {
 	out.WriteString("}")
 	return out.String()
 }
+
+// Describe shows a value on one line, functions by their parameters. nil, the value of code without one,
+// is shown as null.
+func Describe(value Object) string {
+	switch value := value.(type) {
+	case nil:
+		return NULL.Inspect()
+	case *FunctionLiteral:
+		params := make([]string, len(value.Parameters))
+		for i, param := range value.Parameters {
+			params[i] = param.Value
+		}
+		return "fn(" + strings.Join(params, ", ") + ")"
+	case *Builtin:
+		return "builtin"
+	}
+	return strings.ReplaceAll(value.Inspect(), "\n", " ")
+}
@@ -19,3 +19,21 @@ func TestStringHashKey(t *testing.T) {
 		t.Errorf("strings with different content have same hash keys")
 	}
 }
+
+func TestDescribe(t *testing.T) {
+	tests := []struct {
+		value    Object
+		expected string
+	}{
+		{nil, "NULL"},
+		{NULL, "NULL"},
+		{&String{Value: "a\nb"}, "a b"},
+		{&Builtin{}, "builtin"},
+	}
+
+	for _, tt := range tests {
+		if got := Describe(tt.value); got != tt.expected {
+			t.Errorf("wrong description of %#v. want=%q, got=%q", tt.value, tt.expected, got)
+		}
+	}
+}
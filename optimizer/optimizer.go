@@ -0,0 +1,311 @@
+// Package optimizer rewrites programs into equivalent programs that do less work when they run.
+package optimizer
+
+import (
+	"github.com/ShivankSharma070/go-interpreter/ast"
+	"github.com/ShivankSharma070/go-interpreter/evaluator"
+	"github.com/ShivankSharma070/go-interpreter/object"
+	"github.com/ShivankSharma070/go-interpreter/token"
+)
+
+// Optimize returns a copy of program where
+//   - operators applied to literals are replaced by their result, 2 * 60 * 60 becomes 3600
+//   - an if with a literal condition is replaced by the branch that runs
+//   - the uses of a let of a function bound once to a literal are replaced by the literal
+//
+// Operations that fail, like 1 / 0, are kept so they still fail when the program runs. Quoted code is left alone.
+// Top level lets are not inlined, the REPL and the host can bind them again later.
+func Optimize(program *ast.Program) *ast.Program {
+	copied := *program
+	copied.Statements = optimizeStatements(program.Statements, nil, false)
+	return &copied
+}
+
+// inlined maps the lets that can be inlined to their literal
+type inlined map[string]ast.Expression
+
+// optimizeStatements optimizes a list of statements, body tells if it is the body of a function, only lets
+// that always run when the function does can be inlined.
+func optimizeStatements(statements []ast.Statement, scope inlined, body bool) []ast.Statement {
+	if statements == nil {
+		return nil
+	}
+	optimized := make([]ast.Statement, 0, len(statements))
+	for _, statement := range statements {
+		switch statement := statement.(type) {
+		case *ast.ExpressionStatement:
+			// The statements of a branch that always runs take the place of the if, blocks do not have a scope
+			if ifElse, ok := statement.Expression.(*ast.IfElseExpression); ok {
+				if branch, ok := prune(ifElse, scope); ok && branch != nil && len(branch.Statements) > 0 {
+					optimized = append(optimized, optimizeStatements(branch.Statements, scope, body)...)
+					continue
+				}
+			}
+			copied := *statement
+			copied.Expression = optimizeExpression(statement.Expression, scope)
+			optimized = append(optimized, &copied)
+		case *ast.LetStatement:
+			copied := *statement
+			copied.Value = optimizeExpression(statement.Value, scope)
+			if _, ok := scope[statement.Name.Value]; ok && body && isLiteral(copied.Value) {
+				scope[statement.Name.Value] = copied.Value
+			}
+			optimized = append(optimized, &copied)
+		case *ast.ReturnStatement:
+			copied := *statement
+			copied.ReturnValue = optimizeExpression(statement.ReturnValue, scope)
+			optimized = append(optimized, &copied)
+		default:
+			optimized = append(optimized, statement)
+		}
+	}
+	return optimized
+}
+
+func optimizeBlock(block *ast.BlockStatement, scope inlined) *ast.BlockStatement {
+	if block == nil {
+		return nil
+	}
+	copied := *block
+	copied.Statements = optimizeStatements(block.Statements, scope, false)
+	return &copied
+}
+
+func optimizeExpression(expression ast.Expression, scope inlined) ast.Expression {
+	switch node := expression.(type) {
+	case *ast.Identifier:
+		if literal := scope[node.Value]; literal != nil {
+			return relocate(literal, node.Token.Pos)
+		}
+		return node
+	case *ast.PrefixExpression:
+		copied := *node
+		copied.Right = optimizeExpression(node.Right, scope)
+		if right, ok := value(copied.Right); ok {
+			if folded, ok := literal(evaluator.PrefixOperation(node.Operator, right), node.Token.Pos); ok {
+				return folded
+			}
+		}
+		return &copied
+	case *ast.InfixExpression:
+		copied := *node
+		copied.Left = optimizeExpression(node.Left, scope)
+		copied.Right = optimizeExpression(node.Right, scope)
+		left, leftOk := value(copied.Left)
+		right, rightOk := value(copied.Right)
+		if leftOk && rightOk {
+			if folded, ok := literal(evaluator.InfixOperation(node.Operator, left, right), position(copied.Left)); ok {
+				return folded
+			}
+		}
+		return &copied
+	case *ast.IfElseExpression:
+		return optimizeIf(node, scope)
+	case *ast.FunctionExpression:
+		copied := *node
+		copied.Body = &ast.BlockStatement{Token: node.Body.Token}
+		copied.Body.Statements = optimizeStatements(node.Body.Statements, functionScope(node, scope), true)
+		return &copied
+	case *ast.CallExpression:
+		if iden, ok := node.Function.(*ast.Identifier); ok && iden.Value == "quote" {
+			return node
+		}
+		copied := *node
+		copied.Function = optimizeExpression(node.Function, scope)
+		copied.Argument = optimizeExpressions(node.Argument, scope)
+		return &copied
+	case *ast.ArrayLiteral:
+		copied := *node
+		copied.Elements = optimizeExpressions(node.Elements, scope)
+		return &copied
+	case *ast.IndexExpression:
+		copied := *node
+		copied.Left = optimizeExpression(node.Left, scope)
+		copied.Index = optimizeExpression(node.Index, scope)
+		return &copied
+	case *ast.MemberExpression:
+		copied := *node
+		copied.Object = optimizeExpression(node.Object, scope)
+		return &copied
+	case *ast.HashLiteral:
+		copied := *node
+		copied.Pairs = make(map[ast.Expression]ast.Expression, len(node.Pairs))
+		for key, value := range node.Pairs {
+			copied.Pairs[optimizeExpression(key, scope)] = optimizeExpression(value, scope)
+		}
+		return &copied
+	default:
+		return expression
+	}
+}
+
+func optimizeExpressions(expressions []ast.Expression, scope inlined) []ast.Expression {
+	if expressions == nil {
+		return nil
+	}
+	optimized := make([]ast.Expression, len(expressions))
+	for i, expression := range expressions {
+		optimized[i] = optimizeExpression(expression, scope)
+	}
+	return optimized
+}
+
+// optimizeIf drops the branch of an if that cannot run. A branch made of a single expression replaces the if.
+func optimizeIf(node *ast.IfElseExpression, scope inlined) ast.Expression {
+	copied := *node
+	branch, ok := prune(node, scope)
+	if !ok {
+		copied.Condition = optimizeExpression(node.Condition, scope)
+		copied.Consequence = optimizeBlock(node.Consequence, scope)
+		copied.Alternative = optimizeBlock(node.Alternative, scope)
+		return &copied
+	}
+
+	if branch != nil && len(branch.Statements) == 1 {
+		if statement, ok := branch.Statements[0].(*ast.ExpressionStatement); ok {
+			return optimizeExpression(statement.Expression, scope)
+		}
+	}
+
+	// The if stays to keep its value, an empty branch gives nothing while a missing else gives null
+	copied.Condition = optimizeExpression(node.Condition, scope)
+	if branch == node.Consequence {
+		copied.Consequence = optimizeBlock(branch, scope)
+		copied.Alternative = nil
+	} else {
+		copied.Consequence = &ast.BlockStatement{Token: node.Consequence.Token}
+		copied.Alternative = optimizeBlock(branch, scope)
+	}
+	return &copied
+}
+
+// prune returns the branch of an if that runs when its condition is a literal, nil when it is a missing else
+func prune(node *ast.IfElseExpression, scope inlined) (*ast.BlockStatement, bool) {
+	condition, ok := value(optimizeExpression(node.Condition, scope))
+	if !ok {
+		return nil, false
+	}
+	if evaluator.IsTruthy(condition) {
+		return node.Consequence, true
+	}
+	return node.Alternative, true
+}
+
+// functionScope returns the lets of function that can be inlined, along with those of the enclosing functions.
+// A let can be inlined when nothing else binds its name inside the function, the let itself is inlined
+// once it is reached with a literal value.
+func functionScope(function *ast.FunctionExpression, enclosing inlined) inlined {
+	scope := make(inlined, len(enclosing))
+	for name, literal := range enclosing {
+		scope[name] = literal
+	}
+
+	lets := make(map[string]int)
+	params := make(map[string]bool)
+	for _, param := range function.Parameters {
+		params[param.Value] = true
+	}
+	ast.Inspect(function.Body, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.LetStatement:
+			lets[node.Name.Value]++
+		case *ast.FunctionExpression:
+			for _, param := range node.Parameters {
+				params[param.Value] = true
+			}
+		case *ast.MacroLiteral:
+			for _, param := range node.Parameters {
+				params[param.Value] = true
+			}
+		}
+		return true
+	})
+
+	for name, count := range lets {
+		if count == 1 && !params[name] {
+			// Not inlined until its let is reached
+			scope[name] = nil
+		}
+	}
+	return scope
+}
+
+// value returns the value of a literal
+func value(expression ast.Expression) (object.Object, bool) {
+	switch node := expression.(type) {
+	case *ast.IntegerLiteral:
+		return &object.Integer{Value: node.Value}, true
+	case *ast.FloatLiteral:
+		return &object.Float{Value: node.Value}, true
+	case *ast.StringLiteral:
+		return &object.String{Value: node.Value}, true
+	case *ast.BoolExpression:
+		if node.Value {
+			return object.TRUE, true
+		}
+		return object.FALSE, true
+	default:
+		return nil, false
+	}
+}
+
+func isLiteral(expression ast.Expression) bool {
+	_, ok := value(expression)
+	return ok
+}
+
+// literal turns the result of an operation into a literal at pos, it fails on errors
+func literal(obj object.Object, pos token.Position) (ast.Expression, bool) {
+	if obj == nil || obj.Type() == object.ERROR_OBJ {
+		return nil, false
+	}
+	node, err := evaluator.UnquoteNode(obj)
+	if err != nil {
+		return nil, false
+	}
+	expression, ok := node.(ast.Expression)
+	if !ok || !isLiteral(expression) {
+		return nil, false
+	}
+	return relocate(expression, pos), true
+}
+
+// relocate returns a copy of a literal placed at pos
+func relocate(expression ast.Expression, pos token.Position) ast.Expression {
+	switch node := expression.(type) {
+	case *ast.IntegerLiteral:
+		copied := *node
+		copied.Token.Pos = pos
+		return &copied
+	case *ast.FloatLiteral:
+		copied := *node
+		copied.Token.Pos = pos
+		return &copied
+	case *ast.StringLiteral:
+		copied := *node
+		copied.Token.Pos = pos
+		return &copied
+	case *ast.BoolExpression:
+		copied := *node
+		copied.Token.Pos = pos
+		return &copied
+	default:
+		return expression
+	}
+}
+
+// position returns where a literal starts
+func position(expression ast.Expression) token.Position {
+	switch node := expression.(type) {
+	case *ast.IntegerLiteral:
+		return node.Token.Pos
+	case *ast.FloatLiteral:
+		return node.Token.Pos
+	case *ast.StringLiteral:
+		return node.Token.Pos
+	case *ast.BoolExpression:
+		return node.Token.Pos
+	default:
+		return token.Position{}
+	}
+}
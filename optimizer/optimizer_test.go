@@ -0,0 +1,108 @@
+package optimizer
+
+import (
+	"testing"
+
+	"github.com/ShivankSharma070/go-interpreter/ast"
+	"github.com/ShivankSharma070/go-interpreter/evaluator"
+	"github.com/ShivankSharma070/go-interpreter/lexer"
+	"github.com/ShivankSharma070/go-interpreter/object"
+	"github.com/ShivankSharma070/go-interpreter/parser"
+)
+
+func TestOptimize(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		// Constant folding
+		{`2 * 60 * 60`, `7200`},
+		{`1 + 2 * 3 - -4`, `11`},
+		{`1.5 * 2`, `3.0`},
+		{`"hello" + " " + "world"`, `hello world`},
+		{`!(1 < 2) == false`, `true`},
+		{`x + 2 * 3`, `(x + 6)`},
+		{`[1 + 1, {"a": 2 * 2}[3 - 2]]`, `[2, ({a:4}[1])]`},
+		// Failing operations are kept
+		{`1 / 0`, `(1 / 0)`},
+		{`2 * (1 / 0)`, `(2 * (1 / 0))`},
+		{`1 + true`, `(1 + true)`},
+		{`-"a"`, `(-a)`},
+		// If pruning
+		{`if (true) { a } else { b }`, `a`},
+		{`if (1 > 2) { a } else { b }`, `b`},
+		{`if ("") { a }`, `a`},
+		{`let v = if (false) { a } else { b; c }; v`, `let v = if false else bc;v`},
+		{`if (x) { 1 + 1 } else { 2 * 2 }`, `if x 2else 4`},
+		{`if (true) { let a = 1; a }; a`, `let a = 1;aa`},
+		{`if (false) { a }`, `if false `},
+		// Quoted code is data
+		{`quote(1 + 2)`, `quote((1 + 2))`},
+		// Inlining of the lets of a function
+		{`fn() { let h = 60; let d = h * 24; d * 7 }`, `fn()let h = 60;let d = 1440;10080`},
+		{`fn() { let a = 1; fn() { a + 1 } }`, `fn()let a = 1;fn()2`},
+		{`fn() { let a = 1; let a = 2; a }`, `fn()let a = 1;let a = 2;a`},
+		{`fn(c) { if (c) { let a = 1 }; a }`, `fn(c)if c let a = 1;a`},
+		{`fn() { a; let a = 1; a }`, `fn()alet a = 1;1`},
+		{`fn() { let f = fn() { a }; let a = 1; f() }`, `fn()let f = fn()a;let a = 1;f()`},
+		{`fn() { let a = 1; fn(a) { a } }`, `fn()let a = 1;fn(a)a`},
+		{`fn() { let a = x; a }`, `fn()let a = x;a`},
+		// Top level lets are not inlined
+		{`let a = 1; a`, `let a = 1;a`},
+	}
+
+	for _, tt := range tests {
+		program := parse(t, tt.input)
+		original := program.String()
+
+		optimized := Optimize(program)
+		if optimized.String() != tt.expected {
+			t.Errorf("wrong optimization of %s. want=%q, got=%q", tt.input, tt.expected, optimized.String())
+		}
+		if program.String() != original {
+			t.Errorf("Optimize changed its input %s, got %q", tt.input, program.String())
+		}
+	}
+}
+
+func TestOptimizePreservesResults(t *testing.T) {
+	inputs := []string{
+		`let seconds = fn(days) { let h = 60 * 60; days * 24 * h }; seconds(2)`,
+		`let f = fn() { 10 / 0 }; f()`,
+		`let f = fn(x) { if (true) { return x * 2; } 0 }; f(21)`,
+		`if (false) { 1 }`,
+		`5; if (true) {}`,
+		`let f = fn(c) { if (c) { let a = 1 }; a }; f(false)`,
+		`let a = 1; let f = fn() { let b = a; let a = 2; [a, b] }; f()`,
+		`let f = fn() { let g = fn() { n }; let n = 3; g() }; f()`,
+		`let f = fn() { let s = "a" + "b"; let t = s + s; len(t) }; f()`,
+		`let f = fn() { let x = 1; x.missing }; f()`,
+		`quote(unquote(1 + 2) + 3)`,
+		`if (1 > 2) { 1 } else { let x = -(2.5 * 2); x }`,
+	}
+
+	for _, input := range inputs {
+		want := evaluator.Eval(parse(t, input), object.NewEnvironment())
+		got := evaluator.Eval(Optimize(parse(t, input)), object.NewEnvironment())
+		if inspect(got) != inspect(want) {
+			t.Errorf("optimizing %s changed its result. want=%s, got=%s", input, inspect(want), inspect(got))
+		}
+	}
+}
+
+func parse(t *testing.T, input string) *ast.Program {
+	t.Helper()
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parser errors for %s: %v", input, p.Errors())
+	}
+	return program
+}
+
+func inspect(obj object.Object) string {
+	if obj == nil {
+		return "<nil>"
+	}
+	return obj.Inspect()
+}
@@ -33,13 +33,14 @@ var precedence = map[token.TokenType]int{
 	token.ASTERIK: PRODUCT,
 	token.LPAREN:  CALL,
 	token.LBRACKET : INDEX, 
+	token.DOT:      INDEX,
 }
 
 type Parser struct {
 	l            *lexer.Lexer
 	currentToken token.Token
 	peekToken    token.Token
-	errors       []string
+	errors       []Error
 
 	// Maps to associate a token with a parser function
 	prefixParserMap map[token.TokenType]prefixParserFunc
@@ -51,17 +52,37 @@ type (
 	infixParserFunc  func(ast.Expression) ast.Expression // For token found in infix position
 )
 
+// Error is a syntax error, found at the token at Pos
+type Error struct {
+	Pos     token.Position
+	Message string
+}
+
 func (p *Parser) Errors() []string {
+	messages := []string{}
+	for _, err := range p.errors {
+		messages = append(messages, err.Message)
+	}
+	return messages
+}
+
+// ErrorList returns the errors like Errors, along with where they were found
+func (p *Parser) ErrorList() []Error {
 	return p.errors
 }
 
+func (p *Parser) addError(pos token.Position, format string, args ...any) {
+	p.errors = append(p.errors, Error{Pos: pos, Message: fmt.Sprintf(format, args...)})
+}
+
 func New(l *lexer.Lexer) *Parser {
-	p := &Parser{l: l, errors: []string{}}
+	p := &Parser{l: l}
 
 	// Prefix Functions
 	p.prefixParserMap = map[token.TokenType]prefixParserFunc{}
 	p.registerPrefix(token.IDEN, p.parseIdentifier)
 	p.registerPrefix(token.INT, p.parseIntegerLiteral)
+	p.registerPrefix(token.FLOAT, p.parseFloatLiteral)
 	p.registerPrefix(token.MINUS, p.parsePrefixExpression)
 	p.registerPrefix(token.BANG, p.parsePrefixExpression)
 	p.registerPrefix(token.TRUE, p.parseBooleanExpression)
@@ -69,6 +90,7 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerPrefix(token.LPAREN, p.parseGroupedExpression)
 	p.registerPrefix(token.IF, p.parseIfElseExpression)
 	p.registerPrefix(token.FUNCTION, p.parseFunctionExpression)
+	p.registerPrefix(token.MACRO, p.parseMacroLiteral)
 	p.registerPrefix(token.STRING, p.parseStringLiteral)
 	p.registerPrefix(token.LBRACKET, p.parseArrayLiteral)
 	p.registerPrefix(token.LBRACE, p.parseHashLiteral)
@@ -85,6 +107,7 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerInfix(token.ASTERIK, p.parseInfixExpression)
 	p.registerInfix(token.LPAREN, p.parseCallExpression)
 	p.registerInfix(token.LBRACKET, p.parseIndexExpression)
+	p.registerInfix(token.DOT, p.parseMemberExpression)
 
 	// Read Two tokens so that currentToken and peekToken are set
 	p.nextToken()
@@ -179,7 +202,7 @@ func (p *Parser) parseExpressionStatement() ast.Statement {
 func (p *Parser) parseExpression(precedence int) ast.Expression {
 	prefix := p.prefixParserMap[p.currentToken.Type]
 	if prefix == nil {
-		p.errors = append(p.errors, fmt.Sprintf("No prefix parsing function found for %s", p.currentToken.Literal))
+		p.addError(p.currentToken.Pos, "No prefix parsing function found for %s", p.currentToken.Literal)
 		return nil
 	}
 
@@ -210,8 +233,19 @@ func (p *Parser) parseIntegerLiteral() ast.Expression {
 
 	value, err := strconv.ParseInt(lit.TokenLiteral(), 0, 64)
 	if err != nil {
-		err := fmt.Sprintf("Could not parse %q as integer", lit.TokenLiteral())
-		p.errors = append(p.errors, err)
+		p.addError(lit.Token.Pos, "Could not parse %q as integer", lit.TokenLiteral())
+	}
+
+	lit.Value = value
+	return lit
+}
+
+func (p *Parser) parseFloatLiteral() ast.Expression {
+	lit := &ast.FloatLiteral{Token: p.currentToken}
+
+	value, err := strconv.ParseFloat(lit.TokenLiteral(), 64)
+	if err != nil {
+		p.addError(lit.Token.Pos, "Could not parse %q as float", lit.TokenLiteral())
 	}
 
 	lit.Value = value
@@ -314,6 +348,22 @@ func (p *Parser) parseFunctionExpression() ast.Expression {
 	return function
 }
 
+func (p *Parser) parseMacroLiteral() ast.Expression {
+	macro := &ast.MacroLiteral{Token: p.currentToken}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+	macro.Parameters = p.parseFunctionParameters()
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	macro.Body = p.parseBlockExpression()
+
+	return macro
+}
+
 func (p *Parser) parseFunctionParameters() []*ast.Identifier {
 	identifier := []*ast.Identifier{}
 
@@ -353,6 +403,7 @@ func (p *Parser) parseBlockExpression() *ast.BlockStatement {
 		}
 		p.nextToken()
 	}
+	blockStmt.End = p.currentToken.Pos
 
 	return blockStmt
 }
@@ -360,6 +411,7 @@ func (p *Parser) parseBlockExpression() *ast.BlockStatement {
 func (p *Parser) parseCallExpression(exp ast.Expression) ast.Expression {
 	callExp := &ast.CallExpression{Token: p.currentToken, Function: exp}
 	callExp.Argument = p.parseCallArgument()
+	callExp.End = p.currentToken.Pos
 	return callExp
 }
 
@@ -372,6 +424,7 @@ func (p *Parser) parseCallArgument() []ast.Expression {
 func (p *Parser) parseArrayLiteral() ast.Expression {
 	array := &ast.ArrayLiteral{Token: p.currentToken}
 	array.Elements = p.parseExpressionList(token.RBRACKET)
+	array.End = p.currentToken.Pos
 	return array
 }
 
@@ -406,6 +459,18 @@ func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression{
 	if !p.expectPeek(token.RBRACKET) {
 		return nil
 	}
+	exp.End = p.currentToken.Pos
+
+	return exp
+}
+
+func (p *Parser) parseMemberExpression(left ast.Expression) ast.Expression {
+	exp := &ast.MemberExpression{Token: p.currentToken, Object: left}
+
+	if !p.expectPeek(token.IDEN) {
+		return nil
+	}
+	exp.Property = &ast.Identifier{Token: p.currentToken, Value: p.currentToken.Literal}
 
 	return exp
 }
@@ -433,6 +498,7 @@ func (p *Parser) parseHashLiteral() ast.Expression{
 	if !p.expectPeek(token.RBRACE) {
 		return nil
 	}
+	hash.End = p.currentToken.Pos
 	return hash
 }
 
@@ -455,8 +521,15 @@ func (p *Parser) isPeekToken(t token.TokenType) bool {
 }
 
 func (p *Parser) peekError(t token.TokenType) {
-	err := fmt.Sprintf("Expected next token to be %s , got %s", t, p.peekToken.Type)
-	p.errors = append(p.errors, err)
+	p.addError(p.peekToken.Pos, "Expected next token to be %s , got %s", t, p.peekToken.Type)
+}
+
+// Precedence returns how tightly the infix operator t binds its operands, LOWEST for tokens that are not operators
+func Precedence(t token.TokenType) int {
+	if p, ok := precedence[t]; ok {
+		return p
+	}
+	return LOWEST
 }
 
 func (p *Parser) peekPrecedence() int {
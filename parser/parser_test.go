@@ -6,6 +6,7 @@ import (
 
 	"github.com/ShivankSharma070/go-interpreter/ast"
 	"github.com/ShivankSharma070/go-interpreter/lexer"
+	"github.com/ShivankSharma070/go-interpreter/token"
 )
 
 func TestReturnParser(t *testing.T) {
@@ -94,6 +95,35 @@ func TestIntegerLiteralExpression(t *testing.T) {
 	}
 }
 
+func TestFloatLiteralExpression(t *testing.T) {
+	input := `2.5;`
+
+	l := lexer.New(input)
+	p := New(l)
+
+	program := p.ParseProgram()
+	checkForParserErrors(t, p)
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not have enough statements, got %d", len(program.Statements))
+	}
+
+	expStmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("statement is not ast.ExpressionStatement, got %T", program.Statements[0])
+	}
+
+	lit, ok := expStmt.Expression.(*ast.FloatLiteral)
+	if !ok {
+		t.Fatalf("expression is not ast.FloatLiteral, got %T", expStmt.Expression)
+	}
+	if lit.Value != 2.5 {
+		t.Errorf("lit.Value is not 2.5, got %f", lit.Value)
+	}
+	if lit.TokenLiteral() != "2.5" {
+		t.Errorf("lit.TokenLiteral() is not 2.5, got %s", lit.TokenLiteral())
+	}
+}
+
 func TestPrefixExpressionParsing(t *testing.T) {
 	tests := []struct {
 		input        string
@@ -292,6 +322,14 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 			"add(a * b[2], b[1], 2 * [1, 2][1])",
 			"add((a * (b[2])), (b[1]), (2 * ([1, 2][1])))",
 		},
+		{
+			"-a.b * c.d(e)",
+			"((-(a.b)) * (c.d)(e))",
+		},
+		{
+			"a.b[1].c",
+			"(((a.b)[1]).c)",
+		},
 	}
 
 	for _, tt := range tests {
@@ -478,6 +516,46 @@ func TestFunctionStatementParsing(t *testing.T) {
 	}
 }
 
+func TestMacroLiteralParsing(t *testing.T) {
+	input := `macro(x, y) { x + y; }`
+
+	l := lexer.New(input)
+	p := New(l)
+
+	program := p.ParseProgram()
+	checkForParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("Program does not contain %d statements, got %d", 1, len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("program.Statement[0] is not ast.ExpressionStatement, got %T", program.Statements[0])
+	}
+
+	macro, ok := stmt.Expression.(*ast.MacroLiteral)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.MacroLiteral, got %T", stmt.Expression)
+	}
+
+	if len(macro.Parameters) != 2 {
+		t.Fatalf("Macro does not contain %d parameters, got %d", 2, len(macro.Parameters))
+	}
+	testLiteralExpression(t, macro.Parameters[0], "x")
+	testLiteralExpression(t, macro.Parameters[1], "y")
+
+	if len(macro.Body.Statements) != 1 {
+		t.Fatalf("macro.Body.Statements does not contain %d statement, got %d", 1, len(macro.Body.Statements))
+	}
+
+	bodyStmt, ok := macro.Body.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("macro.Body.Statements[0] is not ast.ExpressionStatement, got %T", macro.Body.Statements[0])
+	}
+	testInfixExpression(t, bodyStmt.Expression, "x", "+", "y")
+}
+
 func TestFunctionParameters(t *testing.T) {
 	tests := []struct {
 		input          string
@@ -669,6 +747,32 @@ func TestParsingArrayLiterals(t *testing.T) {
 	testInfixExpression(t, array.Elements[2], 4, "+", 5)
 }
 
+func TestParsingMemberExpression(t *testing.T) {
+	input := "myHash.key"
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkForParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	memberExp, ok := stmt.Expression.(*ast.MemberExpression)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.MemberExpression, got %T", stmt.Expression)
+	}
+
+	if !testIdentifier(t, memberExp.Object, "myHash") {
+		return
+	}
+	testIdentifier(t, memberExp.Property, "key")
+
+	p = New(lexer.New("a.5"))
+	p.ParseProgram()
+	if len(p.Errors()) == 0 || p.Errors()[0] != "Expected next token to be IDEN , got INT" {
+		t.Errorf("expected error for a.5, got %v", p.Errors())
+	}
+}
+
 func TestParsingIndexExpression(t *testing.T) {
 	input := "myArray[1+1]"
 
@@ -790,6 +894,40 @@ func TestParsingHashliteralWithExpression(t *testing.T) {
 
 // ========== HELPER FUNCTIONS ================
 
+func TestErrorPositions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []Error
+	}{
+		{"let = 5;", []Error{
+			{token.Position{Line: 1, Column: 5}, "Expected next token to be IDEN , got ="},
+			// Parsing goes on at the next token
+			{token.Position{Line: 1, Column: 5}, "No prefix parsing function found for ="},
+		}},
+		{"let x = 1;\nlet y 2;", []Error{{token.Position{Line: 2, Column: 7}, "Expected next token to be = , got INT"}}},
+		{"x + ;", []Error{{token.Position{Line: 1, Column: 5}, "No prefix parsing function found for ;"}}},
+		{"f(1, 99999999999999999999)", []Error{{token.Position{Line: 1, Column: 6}, `Could not parse "99999999999999999999" as integer`}}},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+		errors := p.ErrorList()
+		if len(errors) != len(tt.expected) {
+			t.Errorf("wrong errors for %q. want=%v, got=%v", tt.input, tt.expected, errors)
+			continue
+		}
+		for i, err := range errors {
+			if err != tt.expected[i] {
+				t.Errorf("wrong error %d for %q. want=%v, got=%v", i, tt.input, tt.expected[i], err)
+			}
+			if p.Errors()[i] != err.Message {
+				t.Errorf("Errors and ErrorList disagree, %q and %q", p.Errors()[i], err.Message)
+			}
+		}
+	}
+}
+
 func testIdentifier(t *testing.T, expStmt ast.Expression, value string) bool {
 	idenStmt, ok := expStmt.(*ast.Identifier)
 	if !ok {
@@ -5,23 +5,29 @@ import (
 	"fmt"
 	"io"
 
+	"github.com/ShivankSharma070/go-interpreter/ast"
 	"github.com/ShivankSharma070/go-interpreter/evaluator"
 	"github.com/ShivankSharma070/go-interpreter/lexer"
 	"github.com/ShivankSharma070/go-interpreter/object"
+	"github.com/ShivankSharma070/go-interpreter/optimizer"
 	"github.com/ShivankSharma070/go-interpreter/parser"
 )
 
 const PROMPT = ">>>"
 
-func Start(in io.Reader, out io.Writer) {
+// Start runs the REPL in env until the input ends or the exit builtin is called, it returns the exit code of the session.
+func Start(in io.Reader, out io.Writer, env *object.Environment) int {
 	scanner := bufio.NewScanner(in)
-	env := object.NewEnvironment()
+	env.Runtime.Stdout = out
+	// Macros defined in one line can be used by the following ones
+	macroEnv := object.NewEnvironment()
+	macroEnv.Runtime = env.Runtime
 
 	for {
 		fmt.Print(PROMPT)
 		scanned := scanner.Scan()
 		if !scanned {
-			return
+			return 0
 		}
 
 		line := scanner.Text()
@@ -35,7 +41,17 @@ func Start(in io.Reader, out io.Writer) {
 			continue
 		}
 
-		evaluated := evaluator.Eval(program, env)
+		evaluator.DefineMacros(program, macroEnv)
+		expanded, err := evaluator.ExpandMacros(program, macroEnv)
+		if err != nil {
+			io.WriteString(out, err.Inspect()+"\n")
+			continue
+		}
+
+		evaluated := evaluator.Eval(optimizer.Optimize(expanded.(*ast.Program)), env)
+		if exit, ok := evaluated.(*object.Exit); ok {
+			return exit.Code
+		}
 		if evaluated != nil {
 			io.WriteString(out,evaluated.Inspect())
 			io.WriteString(out, "\n")
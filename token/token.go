@@ -1,14 +1,18 @@
 package token
 
+import "fmt"
+
 type TokenType string
 
 const (
 	EOF     = "EOF"
 	ELLEGAL = "ELLEGAL"
+	COMMENT = "COMMENT" // Never handed to the parser, see Lexer.Comments
 
 	// Identifier and Literals
 	IDEN = "IDEN" // Variable names
 	INT  = "INT"
+	FLOAT = "FLOAT"
 	STRING = "STRING"
 
 	// Operators
@@ -28,6 +32,7 @@ const (
 	SEMICOLON = ";"
 	COLON = ":"
 	COMMA     = ","
+	DOT       = "."
 
 	// Braces
 	LPAREN = "("
@@ -45,11 +50,23 @@ const (
 	IF       = "IF"
 	ELSE     = "ELSE"
 	RETURN   = "RETURN"
+	MACRO    = "MACRO"
 )
 
+// Position of a token in the source, both line and column start from 1
+type Position struct {
+	Line   int
+	Column int
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
 type Token struct {
 	Type    TokenType
 	Literal string
+	Pos     Position
 }
 
 var keyword = map[string]TokenType{
@@ -60,6 +77,7 @@ var keyword = map[string]TokenType{
 	"if":     IF,
 	"else":   ELSE,
 	"return": RETURN,
+	"macro":  MACRO,
 }
 
 func LookUpIden(iden string) TokenType {
@@ -70,5 +88,5 @@ func LookUpIden(iden string) TokenType {
 }
 
 func NewToken(t TokenType, char byte) Token {
-	return Token{t, string(char)}
+	return Token{Type: t, Literal: string(char)}
 }
@@ -0,0 +1,108 @@
+package trace
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/ShivankSharma070/go-interpreter/ast"
+	"github.com/ShivankSharma070/go-interpreter/object"
+	"github.com/ShivankSharma070/go-interpreter/token"
+)
+
+// Tracer is a hook writing every call of a program with its arguments, and what it returns, indented by the
+// depth of the call. Errors are written where they are created.
+//
+//	fib(2)
+//	  fib(1)
+//	  => 1
+//	  fib(0)
+//	  => 0
+//	=> 1
+type Tracer struct {
+	object.NopHook
+	w     io.Writer
+	depth int
+	calls []*site // Call expressions being evaluated, the innermost last
+}
+
+// site is a call expression, its callee is called once its arguments are evaluated
+type site struct {
+	call   *ast.CallExpression
+	called bool
+}
+
+func New(w io.Writer) *Tracer {
+	return &Tracer{w: w}
+}
+
+// Attach makes t trace the programs run in env, the returned function detaches it
+func (t *Tracer) Attach(env *object.Environment) func() {
+	env.Runtime.Hooks.Attach(t)
+	return func() { env.Runtime.Hooks.Detach(t) }
+}
+
+func (t *Tracer) Enter(node ast.Node, _ *object.Environment) object.Object {
+	if call, ok := node.(*ast.CallExpression); ok {
+		t.calls = append(t.calls, &site{call: call})
+	}
+	return nil
+}
+
+func (t *Tracer) Exit(node ast.Node, _ *object.Environment, _ object.Object) {
+	if _, ok := node.(*ast.CallExpression); ok {
+		t.calls = t.calls[:len(t.calls)-1]
+	}
+}
+
+func (t *Tracer) Call(fn object.Object, args []object.Object, _ *object.Environment, _ token.Position) {
+	described := make([]string, len(args))
+	for i, arg := range args {
+		described[i] = describe(arg)
+	}
+	t.printf("%s(%s)", t.name(fn), strings.Join(described, ", "))
+	t.depth++
+}
+
+func (t *Tracer) Return(_ object.Object, result object.Object) {
+	t.depth--
+	t.printf("=> %s", describe(result))
+}
+
+func (t *Tracer) Error(err *object.Error, node ast.Node) {
+	t.printf("error at %s: %s", ast.Start(node), err.Message)
+}
+
+// name names fn after the expression it is called through. Functions builtins call, like the one given to map,
+// have none.
+func (t *Tracer) name(fn object.Object) string {
+	if len(t.calls) > 0 && !t.calls[len(t.calls)-1].called {
+		site := t.calls[len(t.calls)-1]
+		site.called = true
+		switch callee := site.call.Function.(type) {
+		case *ast.Identifier:
+			return callee.Value
+		case *ast.FunctionExpression:
+			return "fn"
+		default:
+			return callee.String()
+		}
+	}
+	if _, ok := fn.(*object.Builtin); ok {
+		return "builtin"
+	}
+	return "fn"
+}
+
+// describe shows a value on one line, strings are quoted to tell them apart from other values
+func describe(value object.Object) string {
+	if str, ok := value.(*object.String); ok {
+		return strconv.Quote(str.Value)
+	}
+	return object.Describe(value)
+}
+
+func (t *Tracer) printf(format string, a ...any) {
+	fmt.Fprintf(t.w, "%s%s\n", strings.Repeat("  ", t.depth), fmt.Sprintf(format, a...))
+}
@@ -0,0 +1,74 @@
+package trace
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ShivankSharma070/go-interpreter/evaluator"
+	"github.com/ShivankSharma070/go-interpreter/lexer"
+	"github.com/ShivankSharma070/go-interpreter/object"
+	"github.com/ShivankSharma070/go-interpreter/parser"
+)
+
+func TestTracer(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let add = fn(a, b) { a + b }; add(1, add(2, 3));", `add(2, 3)
+=> 5
+add(1, 5)
+=> 6
+`},
+		{"let fact = fn(n) { if (n < 2) { return 1; } n * fact(n - 1) }; fact(3)", `fact(3)
+  fact(2)
+    fact(1)
+    => 1
+  => 2
+=> 6
+`},
+		{`map([1, 2], fn(x) { x * 2 }); fn(s) { len(s) }("ab")`, `map([1, 2], fn(x))
+  fn(1)
+  => 2
+  fn(2)
+  => 4
+=> [2, 4]
+fn("ab")
+  len("ab")
+  => 2
+=> 2
+`},
+		{"let f = fn(x) { x / 0 };\nf(1) + 2", `f(1)
+  error at 1:17: division by zero
+=> Error: division by zero
+`},
+		{"let g = fn() {}; puts(g());", `g()
+=> NULL
+puts(NULL)
+=> NULL
+`},
+		{"len(1, 2)", `len(1, 2)
+=> Error: wrong number of arguments. got=2, want=1
+error at 1:1: wrong number of arguments. got=2, want=1
+`},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			t.Fatalf("parser errors: %v", p.Errors())
+		}
+
+		var out bytes.Buffer
+		env := object.NewEnvironment()
+		detach := New(&out).Attach(env)
+		evaluator.Eval(program, env)
+		detach()
+		evaluator.Eval(program, env)
+
+		if out.String() != tt.expected {
+			t.Errorf("wrong trace for %q.\nexpected:\n%s\ngot:\n%s", tt.input, tt.expected, out.String())
+		}
+	}
+}
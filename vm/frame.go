@@ -0,0 +1,99 @@
+package vm
+
+import (
+	"github.com/ShivankSharma070/go-interpreter/object"
+)
+
+// Frame is a call being executed
+type Frame struct {
+	cl          *object.Closure
+	ip          int // Instruction being executed, it starts before the first one
+	basePointer int // Where the locals of the call start on the stack, the closure sits right below them
+}
+
+func NewFrame(cl *object.Closure, basePointer int) *Frame {
+	return &Frame{cl: cl, ip: -1, basePointer: basePointer}
+}
+
+// executeCall calls the function sitting on the stack below its numArgs arguments
+func (vm *VM) executeCall(numArgs int) object.Object {
+	callee := vm.stack[vm.sp-1-numArgs]
+	switch callee := callee.(type) {
+	case *object.Closure:
+		return vm.callClosure(callee, numArgs)
+	case *object.Builtin:
+		args := make([]object.Object, numArgs)
+		copy(args, vm.stack[vm.sp-numArgs:vm.sp])
+		result := callee.Fn(vm.builtinContext(), args...)
+		vm.sp -= numArgs + 1
+		if result == nil {
+			result = NULL
+		}
+		return vm.push(result)
+	default:
+		return newError("not a function: %s", callee.Type())
+	}
+}
+
+// callClosure starts the execution of cl, whose arguments are on top of the stack
+func (vm *VM) callClosure(cl *object.Closure, numArgs int) object.Object {
+	if numArgs != cl.Fn.NumParameters {
+		return newError("wrong number of arguments. got=%d, want=%d", numArgs, cl.Fn.NumParameters)
+	}
+	if len(vm.frames) == MaxFrames {
+		return newError("stack overflow, more than %d nested calls", MaxFrames)
+	}
+
+	frame := NewFrame(cl, vm.sp-numArgs)
+	vm.frames = append(vm.frames, frame)
+
+	// Room for the locals
+	vm.sp = frame.basePointer + cl.Fn.NumLocals
+	for vm.sp >= len(vm.stack) {
+		vm.stack = append(vm.stack, make([]object.Object, len(vm.stack))...)
+	}
+	clear(vm.stack[frame.basePointer+numArgs : vm.sp])
+	// Locals read by closures are shared with them through cells
+	for _, slot := range cl.Fn.Cells {
+		vm.stack[frame.basePointer+slot] = &object.Cell{Value: vm.stack[frame.basePointer+slot]}
+	}
+	return nil
+}
+
+func (vm *VM) builtinContext() *object.BuiltinContext {
+	frame := vm.frames[len(vm.frames)-1]
+	// A closure of a module is given the environment of the module, like functions of the evaluator
+	env := frame.cl.Program.Env
+	return &object.BuiltinContext{
+		Env:    env,
+		Pos:    frame.cl.Fn.Positions.Lookup(frame.ip),
+		Stdout: env.Runtime.Stdout,
+		Stderr: env.Runtime.Stderr,
+		Apply:  vm.apply,
+	}
+}
+
+// apply calls fn from a builtin, a closure is run to completion before apply returns
+func (vm *VM) apply(fn object.Object, args []object.Object) object.Object {
+	switch fn := fn.(type) {
+	case *object.Builtin:
+		return fn.Fn(vm.builtinContext(), args...)
+	case *object.Closure:
+		sp, depth := vm.sp, len(vm.frames)
+		vm.push(fn)
+		for _, arg := range args {
+			vm.push(arg)
+		}
+
+		result := vm.callClosure(fn, len(args))
+		if result == nil {
+			result = vm.run(depth + 1)
+		}
+		// Whatever stopped the call is handed to the builtin, the state of the caller is restored
+		vm.sp = sp
+		vm.frames = vm.frames[:depth]
+		return result
+	default:
+		return newError("not a function: %s", fn.Type())
+	}
+}
@@ -0,0 +1,448 @@
+// Package vm runs the bytecode produced by the compiler on a stack machine.
+package vm
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ShivankSharma070/go-interpreter/ast"
+	"github.com/ShivankSharma070/go-interpreter/code"
+	"github.com/ShivankSharma070/go-interpreter/compiler"
+	"github.com/ShivankSharma070/go-interpreter/evaluator"
+	"github.com/ShivankSharma070/go-interpreter/object"
+)
+
+const (
+	StackSize = 2048    // Initial size of the stack, it grows as needed
+	MaxFrames = 1 << 16 // Deepest nesting of calls before the vm gives up with a stack overflow
+)
+
+var (
+	NULL  = object.NULL
+	TRUE  = object.TRUE
+	FALSE = object.FALSE
+)
+
+// builtins indexed the way the compiler refers to them
+var builtins = func() []*object.Builtin {
+	names := evaluator.BuiltinNames()
+	table := make([]*object.Builtin, len(names))
+	for i, name := range names {
+		table[i], _ = evaluator.LookupBuiltin(name)
+	}
+	return table
+}()
+
+type VM struct {
+	env     *object.Environment // Gives builtins access to the runtime
+	program *object.CompiledProgram
+	main    *object.Closure
+
+	stack []object.Object
+	sp    int // Always points to the next free slot, the top of the stack is stack[sp-1]
+
+	frames []*Frame
+}
+
+// New creates a vm running bytecode in env, which gives builtins access to the runtime
+func New(bytecode *compiler.Bytecode, env *object.Environment) *VM {
+	return NewWithProgram(bytecode, env, &object.CompiledProgram{})
+}
+
+// NewWithProgram creates a vm running bytecode as part of program, whose globals were already set by a previous run.
+// Modules imported by the program run on the vm as well, unless the runtime already says otherwise.
+func NewWithProgram(bytecode *compiler.Bytecode, env *object.Environment, program *object.CompiledProgram) *VM {
+	if env.Runtime.Evaluate == nil {
+		env.Runtime.Evaluate = Eval
+	}
+	program.Constants = bytecode.Constants
+	program.GlobalNames = bytecode.GlobalNames
+	program.Env = env
+	if missing := len(bytecode.GlobalNames) - len(program.Globals); missing > 0 {
+		program.Globals = append(program.Globals, make([]object.Object, missing)...)
+	}
+
+	mainFn := &object.CompiledFunction{Instructions: bytecode.Instructions, Positions: bytecode.Positions}
+	return &VM{
+		env:     env,
+		program: program,
+		main:    &object.Closure{Fn: mainFn, Program: program},
+		stack:   make([]object.Object, StackSize),
+	}
+}
+
+// Eval compiles and runs a program the way evaluator.Eval evaluates it, the bindings of env are available
+// to the program as globals and the globals of the program are stored back into env once it is done.
+// Modules imported by the program run on the vm as well, unless the runtime already says otherwise.
+func Eval(node *ast.Program, env *object.Environment) object.Object {
+	// Undefined variables are reported before anything runs, the same way the evaluator does
+	if err := evaluator.Resolve(node, env); err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(env.Store))
+	for name := range env.Store {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	symbols := compiler.NewSymbolTable()
+	program := &object.CompiledProgram{}
+	for _, name := range names {
+		symbols.Define(name)
+		program.Globals = append(program.Globals, env.Store[name])
+	}
+
+	c := compiler.NewWithState(symbols, []object.Object{})
+	if err := c.Compile(node); err != nil {
+		return &object.Error{Message: err.Error()}
+	}
+	result := NewWithProgram(c.Bytecode(), env, program).Run()
+
+	for i, name := range program.GlobalNames {
+		if value := program.Globals[i]; value != nil {
+			env.Set(name, value)
+		}
+	}
+	return result
+}
+
+// Run executes the program and returns its result, like evaluator.Eval it is either the value of the last statement,
+// the value of a top level return, or the error or exit signal that stopped the program.
+func (vm *VM) Run() object.Object {
+	vm.push(vm.main)
+	if err := vm.callClosure(vm.main, 0); err != nil {
+		return err
+	}
+	return vm.run(1)
+}
+
+// run executes instructions until the frame at index depth-1 returns, it returns the value returned by that frame
+// or the error that stopped the execution.
+func (vm *VM) run(depth int) object.Object {
+	for {
+		frame := vm.frames[len(vm.frames)-1]
+		frame.ip++
+		ip := frame.ip
+		ins := frame.cl.Fn.Instructions
+		op := code.Opcode(ins[ip])
+
+		var err object.Object
+		switch op {
+		case code.OpConstant:
+			constIndex := code.ReadUint16(ins[ip+1:])
+			frame.ip += 2
+			vm.push(frame.cl.Program.Constants[constIndex])
+
+		case code.OpPop:
+			vm.pop()
+
+		case code.OpAdd, code.OpSub, code.OpMul, code.OpDiv,
+			code.OpEqual, code.OpNotEqual, code.OpGreaterThan, code.OpLessThan:
+			err = vm.executeInfixOperation(op)
+
+		case code.OpMinus:
+			err = vm.push(evaluator.PrefixOperation("-", vm.pop()))
+
+		case code.OpBang:
+			err = vm.push(evaluator.PrefixOperation("!", vm.pop()))
+
+		case code.OpTrue:
+			vm.push(TRUE)
+
+		case code.OpFalse:
+			vm.push(FALSE)
+
+		case code.OpNull:
+			vm.push(NULL)
+
+		case code.OpJump:
+			pos := int(code.ReadUint16(ins[ip+1:]))
+			frame.ip = pos - 1
+
+		case code.OpJumpNotTruthy:
+			pos := int(code.ReadUint16(ins[ip+1:]))
+			frame.ip += 2
+			if !evaluator.IsTruthy(vm.pop()) {
+				frame.ip = pos - 1
+			}
+
+		case code.OpGetGlobal:
+			index := code.ReadUint16(ins[ip+1:])
+			frame.ip += 2
+			program := frame.cl.Program
+			value := program.Globals[index]
+			if value == nil {
+				return newError("identifier not found: %s", program.GlobalNames[index])
+			}
+			vm.push(value)
+
+		case code.OpSetGlobal:
+			index := code.ReadUint16(ins[ip+1:])
+			frame.ip += 2
+			frame.cl.Program.Globals[index] = vm.pop()
+
+		case code.OpGetLocal:
+			index := code.ReadUint8(ins[ip+1:])
+			frame.ip += 1
+			value := vm.stack[frame.basePointer+int(index)]
+			if value == nil {
+				return newError("identifier not found: %s", frame.cl.Fn.LocalNames[index])
+			}
+			vm.push(value)
+
+		case code.OpSetLocal:
+			index := code.ReadUint8(ins[ip+1:])
+			frame.ip += 1
+			vm.stack[frame.basePointer+int(index)] = vm.pop()
+
+		case code.OpGetBuiltin:
+			index := code.ReadUint16(ins[ip+1:])
+			frame.ip += 2
+			vm.push(builtins[index])
+
+		case code.OpGetCell:
+			index := code.ReadUint8(ins[ip+1:])
+			frame.ip += 1
+			value := vm.stack[frame.basePointer+int(index)].(*object.Cell).Value
+			if value == nil {
+				return newError("identifier not found: %s", frame.cl.Fn.LocalNames[index])
+			}
+			vm.push(value)
+
+		case code.OpSetCell:
+			index := code.ReadUint8(ins[ip+1:])
+			frame.ip += 1
+			vm.stack[frame.basePointer+int(index)].(*object.Cell).Value = vm.pop()
+
+		case code.OpGetFree:
+			index := code.ReadUint8(ins[ip+1:])
+			frame.ip += 1
+			value := frame.cl.Free[index].Value
+			if value == nil {
+				return newError("identifier not found: %s", frame.cl.Fn.FreeNames[index])
+			}
+			vm.push(value)
+
+		case code.OpCaptureLocal:
+			index := code.ReadUint8(ins[ip+1:])
+			frame.ip += 1
+			vm.push(vm.stack[frame.basePointer+int(index)])
+
+		case code.OpCaptureFree:
+			index := code.ReadUint8(ins[ip+1:])
+			frame.ip += 1
+			vm.push(frame.cl.Free[index])
+
+		case code.OpArray:
+			numElements := int(code.ReadUint16(ins[ip+1:]))
+			frame.ip += 2
+			elements := make([]object.Object, numElements)
+			copy(elements, vm.stack[vm.sp-numElements:vm.sp])
+			vm.sp -= numElements
+			vm.push(&object.Array{Elements: elements})
+
+		case code.OpHash:
+			numElements := int(code.ReadUint16(ins[ip+1:]))
+			frame.ip += 2
+			hash, hashErr := vm.buildHash(vm.sp-numElements, vm.sp)
+			if hashErr != nil {
+				return hashErr
+			}
+			vm.sp -= numElements
+			vm.push(hash)
+
+		case code.OpIndex:
+			index := vm.pop()
+			left := vm.pop()
+			err = vm.push(evaluator.IndexOperation(left, index))
+
+		case code.OpMember:
+			constIndex := code.ReadUint16(ins[ip+1:])
+			frame.ip += 2
+			name := frame.cl.Program.Constants[constIndex].(*object.String).Value
+			err = vm.push(evaluator.MemberOperation(vm.pop(), name))
+
+		case code.OpCall:
+			numArgs := int(code.ReadUint8(ins[ip+1:]))
+			frame.ip += 1
+			err = vm.executeCall(numArgs)
+
+		case code.OpReturnValue, code.OpReturn:
+			var value object.Object = NULL
+			if op == code.OpReturnValue {
+				value = vm.pop()
+			} else if len(vm.frames) == 1 {
+				// A program ending with a let statement has no value, the evaluator returns nil for it as well
+				value = nil
+			}
+
+			vm.frames = vm.frames[:len(vm.frames)-1]
+			vm.sp = frame.basePointer - 1
+			if len(vm.frames) < depth {
+				return value
+			}
+			vm.push(value)
+
+		case code.OpClosure:
+			constIndex := code.ReadUint16(ins[ip+1:])
+			numFree := int(code.ReadUint8(ins[ip+3:]))
+			frame.ip += 3
+			vm.pushClosure(frame.cl.Program, int(constIndex), numFree)
+
+		case code.OpQuote:
+			constIndex := code.ReadUint16(ins[ip+1:])
+			numValues := int(code.ReadUint8(ins[ip+3:]))
+			frame.ip += 3
+			template := frame.cl.Program.Constants[constIndex].(*object.Quote)
+			err = vm.push(vm.fillQuote(template, vm.sp-numValues, vm.sp))
+
+		default:
+			def, lookupErr := code.Lookup(byte(op))
+			if lookupErr != nil {
+				return newError("%s", lookupErr)
+			}
+			return newError("unhandled opcode %s", def.Name)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// executeInfixOperation pops the left operand then the right one, operations on integers are done right here
+// and everything else is left to the evaluator so both engines agree.
+func (vm *VM) executeInfixOperation(op code.Opcode) object.Object {
+	left := vm.pop()
+	right := vm.pop()
+
+	leftInt, leftOk := left.(*object.Integer)
+	rightInt, rightOk := right.(*object.Integer)
+	if leftOk && rightOk {
+		l, r := leftInt.Value, rightInt.Value
+		switch op {
+		case code.OpAdd:
+			return vm.push(&object.Integer{Value: l + r})
+		case code.OpSub:
+			return vm.push(&object.Integer{Value: l - r})
+		case code.OpMul:
+			return vm.push(&object.Integer{Value: l * r})
+		case code.OpEqual:
+			return vm.push(nativeBoolToBooleanObject(l == r))
+		case code.OpNotEqual:
+			return vm.push(nativeBoolToBooleanObject(l != r))
+		case code.OpGreaterThan:
+			return vm.push(nativeBoolToBooleanObject(l > r))
+		case code.OpLessThan:
+			return vm.push(nativeBoolToBooleanObject(l < r))
+		}
+	}
+	return vm.push(evaluator.InfixOperation(infixOperators[op], left, right))
+}
+
+var infixOperators = map[code.Opcode]string{
+	code.OpAdd:         "+",
+	code.OpSub:         "-",
+	code.OpMul:         "*",
+	code.OpDiv:         "/",
+	code.OpEqual:       "==",
+	code.OpNotEqual:    "!=",
+	code.OpGreaterThan: ">",
+	code.OpLessThan:    "<",
+}
+
+// buildHash creates a hash out of the keys and values between stack[start] and stack[end]
+func (vm *VM) buildHash(start, end int) (object.Object, object.Object) {
+	pairs := make(map[object.HashKey]object.HashPair)
+	for i := start; i < end; i += 2 {
+		key := vm.stack[i]
+		value := vm.stack[i+1]
+
+		hashKey, ok := key.(object.Hashable)
+		if !ok {
+			return nil, newError("unusable as hash key: %s", key.Type())
+		}
+		pairs[hashKey.HashKey()] = object.HashPair{Key: key, Value: value}
+	}
+	return &object.Hash{Pair: pairs}, nil
+}
+
+// fillQuote replaces the unquote placeholders of a quote with the values between stack[start] and stack[end]
+func (vm *VM) fillQuote(template *object.Quote, start, end int) object.Object {
+	values := make([]object.Object, end-start)
+	copy(values, vm.stack[start:end])
+	vm.sp = start
+
+	var err *object.Error
+	node := ast.Modify(template.Node, func(node ast.Node) ast.Node {
+		call, ok := node.(*ast.CallExpression)
+		if !ok || err != nil {
+			return node
+		}
+		iden, ok := call.Function.(*ast.Identifier)
+		if !ok || iden.Value != "unquote" {
+			return node
+		}
+		placeholder := call.Argument[0].(*ast.IntegerLiteral)
+
+		converted, convErr := evaluator.UnquoteNode(values[placeholder.Value])
+		if convErr != nil {
+			err = convErr
+			return node
+		}
+		return converted
+	})
+	if err != nil {
+		return err
+	}
+	return &object.Quote{Node: node}
+}
+
+func (vm *VM) pushClosure(program *object.CompiledProgram, constIndex, numFree int) {
+	fn := program.Constants[constIndex].(*object.CompiledFunction)
+	free := make([]*object.Cell, numFree)
+	for i := range free {
+		free[i] = vm.stack[vm.sp-numFree+i].(*object.Cell)
+	}
+	vm.sp -= numFree
+	vm.push(&object.Closure{Fn: fn, Free: free, Program: program})
+}
+
+// push puts obj on top of the stack, unless it is an error or an exit signal, which are returned to stop the execution
+func (vm *VM) push(obj object.Object) object.Object {
+	if isError(obj) {
+		return obj
+	}
+	if vm.sp >= len(vm.stack) {
+		vm.stack = append(vm.stack, make([]object.Object, len(vm.stack))...)
+	}
+	vm.stack[vm.sp] = obj
+	vm.sp++
+	return nil
+}
+
+func (vm *VM) pop() object.Object {
+	obj := vm.stack[vm.sp-1]
+	vm.sp--
+	return obj
+}
+
+func nativeBoolToBooleanObject(value bool) *object.Boolean {
+	if value {
+		return TRUE
+	}
+	return FALSE
+}
+
+func newError(format string, a ...any) *object.Error {
+	return &object.Error{Message: fmt.Sprintf(format, a...)}
+}
+
+// isError reports whether obj stops the execution, exit signals unwind the same way errors do
+func isError(obj object.Object) bool {
+	if obj != nil {
+		return obj.Type() == object.ERROR_OBJ || obj.Type() == object.EXIT_OBJ
+	}
+	return false
+}
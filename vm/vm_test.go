@@ -0,0 +1,195 @@
+package vm
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/ShivankSharma070/go-interpreter/ast"
+	"github.com/ShivankSharma070/go-interpreter/compiler"
+	"github.com/ShivankSharma070/go-interpreter/evaluator"
+	"github.com/ShivankSharma070/go-interpreter/lexer"
+	"github.com/ShivankSharma070/go-interpreter/object"
+	"github.com/ShivankSharma070/go-interpreter/parser"
+)
+
+// The evaluator tests run on the vm as well, these cover what is specific to the vm
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`let fib = fn(n) { if (n < 2) { n } else { fib(n - 1) + fib(n - 2) } }; fib(15)`, "610"},
+		{`let newAdder = fn(a) { fn(b) { a + b } }; let addTwo = newAdder(2); addTwo(3)`, "5"},
+		{`let f = fn(a) { let g = fn(b) { fn(c) { a + b + c } }; g(2) }; f(1)(3)`, "6"},
+		{`let countDown = fn(x) { if (x == 0) { 0 } else { countDown(x - 1) } }; countDown(10000)`, "0"},
+		// Functions can use globals defined after them
+		{`let isEven = fn(n) { if (n == 0) { true } else { isOdd(n - 1) } }; let isOdd = fn(n) { if (n == 0) { false } else { isEven(n - 1) } }; isEven(10)`, "true"},
+		{`let a = 1; let f = fn() { a }; let a = 2; f()`, "2"},
+		// Builtins calling closures
+		{`let offset = 10; map([1, 2, 3], fn(x) { x + offset })`, "[11, 12, 13]"},
+		{`reduce([1, 2, 3, 4], fn(acc, x) { acc + x }, 0)`, "10"},
+		{`sort_by(["ccc", "a", "bb"], fn(s) { len(s) })`, "[a, bb, ccc]"},
+		{`[1, 2, 3].map(fn(x) { [1, 2].map(fn(y) { x * y }) })`, "[[1, 2], [2, 4], [3, 6]]"},
+		{`let m = macro(x) { quote(unquote(x) * 2) }; 1`, "Error: macro literals can only be bound by a top level let statement"},
+		{`let q = fn(x) { quote(unquote(x) + 1) }; [q(1), q(2)]`, "[QUOTE((1 + 1)), QUOTE((2 + 1))]"},
+		{`let a = 5; let b = a * 2; return b; 0`, "10"},
+	}
+
+	for _, tt := range tests {
+		result := testRun(tt.input, object.NewEnvironment())
+		if result == nil {
+			t.Errorf("%s returned nil", tt.input)
+			continue
+		}
+		if result.Inspect() != tt.expected {
+			t.Errorf("%s is not %q, got %q", tt.input, tt.expected, result.Inspect())
+		}
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`let f = fn() { missing }; f()`, "identifier not found: missing"},
+		// Names are checked before the program runs, even in functions that are never called
+		{`let unused = fn() { later }; 1`, "identifier not found: later"},
+		{`fn(x) { x }(1, 2)`, "wrong number of arguments. got=2, want=1"},
+		{`1(2)`, "not a function: INTEGER"},
+		{`map([1, 2], fn(x) { x / 0 })`, "division by zero"},
+		{`let f = fn() { f() }; f()`, "stack overflow, more than 65536 nested calls"},
+		{`{[1]: 2}`, "unusable as hash key: ARRAY"},
+	}
+
+	for _, tt := range tests {
+		result := testRun(tt.input, object.NewEnvironment())
+		err, ok := result.(*object.Error)
+		if !ok {
+			t.Errorf("%s did not fail, got %T (%+v)", tt.input, result, result)
+			continue
+		}
+		if err.Message != tt.expected {
+			t.Errorf("wrong error for %s. want=%q, got=%q", tt.input, tt.expected, err.Message)
+		}
+	}
+}
+
+func TestEvalSharesEnvironment(t *testing.T) {
+	env := object.NewEnvironment()
+	env.Set("base", &object.Integer{Value: 40})
+
+	// later is bound by a let that never runs, so the resolver accepts it but it is never stored
+	result := testRun(`let answer = base + 2; let unused = fn() { later }; if (false) { let later = 1 }; answer`, env)
+	if result.Inspect() != "42" {
+		t.Fatalf("program did not read base from env, got %s", result.Inspect())
+	}
+
+	answer, ok := env.Get("answer")
+	if !ok || answer.Inspect() != "42" {
+		t.Errorf("answer was not stored in env, got %v", answer)
+	}
+	if _, ok := env.Get("later"); ok {
+		t.Errorf("a global that was never set should not be stored in env")
+	}
+}
+
+func TestExitStopsProgram(t *testing.T) {
+	env := object.NewEnvironment()
+	var out strings.Builder
+	env.Runtime.Stdout = &out
+
+	result := testRun(`puts("before"); each([1, 2], fn(x) { exit(x + 2) }); puts("after")`, env)
+	exit, ok := result.(*object.Exit)
+	if !ok || exit.Code != 3 {
+		t.Fatalf("program did not exit with 3, got %+v", result)
+	}
+	if out.String() != "before\n" {
+		t.Errorf("wrong output %q", out.String())
+	}
+}
+
+func TestModulesRunOnVM(t *testing.T) {
+	env := object.NewEnvironment()
+	env.Runtime.Files = object.NewReadOnlyFilePolicy(fstest.MapFS{
+		"counter.monkey": {Data: []byte(`let start = 10; let next = fn(x) { x + start + _step }; let _step = 1;`)},
+	})
+
+	result := testRun(`let counter = import("counter"); let start = 0; [counter.next(1), counter.start]`, env)
+	if result.Inspect() != "[12, 10]" {
+		t.Errorf("module functions do not use their own globals, got %s", result.Inspect())
+	}
+
+	module, _ := env.Get("counter")
+	if _, ok := module.(*object.Module).Exports["next"].(*object.Closure); !ok {
+		t.Errorf("module was not compiled, next is %T", module.(*object.Module).Exports["next"])
+	}
+}
+
+func TestCompiledProgramsRunModulesOnVM(t *testing.T) {
+	env := object.NewEnvironment()
+	env.Runtime.Files = object.NewReadOnlyFilePolicy(fstest.MapFS{
+		"counter.monkey": {Data: []byte(`let next = fn(x) { x + 1 };`)},
+	})
+	c := compiler.New()
+	if err := c.Compile(parser.New(lexer.New(`import("counter").next(1)`)).ParseProgram()); err != nil {
+		t.Fatalf("compiler error: %s", err)
+	}
+	if result := New(c.Bytecode(), env).Run(); result.Inspect() != "2" {
+		t.Errorf("function of a module imported by a compiled program cannot be called, got %s", result.Inspect())
+	}
+}
+
+func TestRunDecodedBytecode(t *testing.T) {
+	program := parser.New(lexer.New(`let add = fn(a) { fn(b) { a + b } }; puts(add(2)(3), "ok", len([1.5]))`)).ParseProgram()
+	c := compiler.New()
+	if err := c.Compile(program); err != nil {
+		t.Fatalf("compiler error: %s", err)
+	}
+	data, err := c.Bytecode().MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary failed: %s", err)
+	}
+
+	decoded := &compiler.Bytecode{}
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary failed: %s", err)
+	}
+	env := object.NewEnvironment()
+	var out strings.Builder
+	env.Runtime.Stdout = &out
+	if result := New(decoded, env).Run(); isError(result) {
+		t.Fatalf("decoded program failed: %s", result.Inspect())
+	}
+	if out.String() != "5\nok\n1\n" {
+		t.Errorf("wrong output %q", out.String())
+	}
+}
+
+func testRun(input string, env *object.Environment) object.Object {
+	program := parser.New(lexer.New(input)).ParseProgram()
+	return Eval(program, env)
+}
+
+const fibonacci = `let fib = fn(n) { if (n < 2) { n } else { fib(n - 1) + fib(n - 2) } }; fib(20)`
+
+func BenchmarkFibonacciVM(b *testing.B) {
+	benchmarkEngine(b, Eval)
+}
+
+func BenchmarkFibonacciEvaluator(b *testing.B) {
+	benchmarkEngine(b, func(program *ast.Program, env *object.Environment) object.Object {
+		return evaluator.Eval(program, env)
+	})
+}
+
+func benchmarkEngine(b *testing.B, eval func(*ast.Program, *object.Environment) object.Object) {
+	program := parser.New(lexer.New(fibonacci)).ParseProgram()
+	for b.Loop() {
+		if result := eval(program, object.NewEnvironment()); result.Inspect() != "6765" {
+			b.Fatalf("wrong result %s", result.Inspect())
+		}
+	}
+}